@@ -1,14 +1,26 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"os"
+	"sync"
+	"time"
 
+	"disk-peek/internal/audit"
 	"disk-peek/internal/cache"
+	"disk-peek/internal/cleaner"
+	"disk-peek/internal/export"
+	"disk-peek/internal/gitcheck"
+	"disk-peek/internal/journal"
+	"disk-peek/internal/policy"
 	"disk-peek/internal/scanner"
 	"disk-peek/internal/settings"
 	"disk-peek/internal/trash"
 	"disk-peek/internal/updater"
+	"disk-peek/internal/watcher"
+	"disk-peek/internal/xdg"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -18,10 +30,25 @@ type App struct {
 	ctx           context.Context
 	devScanner    *scanner.DevScanner
 	normalScanner *scanner.NormalScanner
+	fingerprints  *scanner.FingerprintIndex
 	scanCancel    context.CancelFunc
 	cleanCancel   context.CancelFunc
+
+	// Live updates of the last scan results, see SetWatchEnabled
+	watchMu      sync.Mutex
+	rewatchMu    sync.Mutex
+	watcher      *watcher.Watcher
+	watchedTree  *watcher.Tree
+	watchedDev   *scanner.ScanResult
+	lastSnapshot time.Time
+
+	// Applies the cleanup policies from settings in the background
+	scheduler *policy.Scheduler
 }
 
+// watchSnapshotInterval limits how often live updates are recorded as trends
+const watchSnapshotInterval = time.Hour
+
 // NewApp creates a new App application struct
 func NewApp() *App {
 	return &App{
@@ -33,6 +60,31 @@ func NewApp() *App {
 // startup is called when the app starts
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+
+	// Move files from the pre-XDG ~/.config/disk-peek layout
+	_ = xdg.MigrateLegacy()
+
+	applyExclusions(settings.GetExclusions())
+
+	a.scheduler = policy.NewScheduler(policy.DefaultCheckInterval, func(runs []policy.Run) {
+		runtime.EventsEmit(a.ctx, "policies:completed", runs)
+	})
+	a.scheduler.Start()
+}
+
+// shutdown is called when the app is closing
+// It waits for a policy run in progress to stop, so no cleanup is cut off
+// halfway through its journal.
+func (a *App) shutdown(ctx context.Context) {
+	if a.scheduler != nil {
+		a.scheduler.Stop()
+	}
+	a.stopWatching()
+}
+
+// applyExclusions makes every scanner skip the given rules
+func applyExclusions(e settings.Exclusions) {
+	scanner.SetExcluder(scanner.NewExcluder(e.Patterns, e.Paths, e.Mounts))
 }
 
 // CancelScan cancels any running scan operation
@@ -84,6 +136,7 @@ func (a *App) ScanDev() scanner.ScanResult {
 
 	// Save to cache
 	_ = cache.SaveDevScan(result)
+	a.watchDevResult(result)
 
 	runtime.EventsEmit(a.ctx, "scan:completed", result)
 	return result
@@ -95,6 +148,7 @@ func (a *App) QuickScanDev() scanner.ScanResult {
 	result := a.devScanner.QuickScan()
 	// Save to cache
 	_ = cache.SaveDevScan(result)
+	a.watchDevResult(result)
 	runtime.EventsEmit(a.ctx, "scan:completed", result)
 	return result
 }
@@ -104,6 +158,12 @@ func (a *App) GetDevCategories() []scanner.Category {
 	return scanner.GetCategories()
 }
 
+// ValidateCustomCategories checks the user's categories file
+// Returns nil if the file is valid or doesn't exist
+func (a *App) ValidateCustomCategories() error {
+	return scanner.ValidateCustomCategories()
+}
+
 // ScanCategory scans a single category by ID
 func (a *App) ScanCategory(categoryID string) *scanner.Category {
 	return a.devScanner.ScanCategory(categoryID)
@@ -134,6 +194,7 @@ func (a *App) ScanNormal() scanner.FullScanResult {
 	})
 
 	home, _ := os.UserHomeDir()
+	a.loadFingerprints()
 
 	runtime.EventsEmit(a.ctx, "scan:started", nil)
 	result := a.normalScanner.Scan()
@@ -145,6 +206,8 @@ func (a *App) ScanNormal() scanner.FullScanResult {
 
 	// Save to cache
 	_ = cache.SaveNormalScan(result, home)
+	_ = cache.SaveFingerprintIndex(a.fingerprints)
+	a.watchNormalResult(result)
 
 	runtime.EventsEmit(a.ctx, "scan:completed:normal", result)
 	return result
@@ -167,6 +230,8 @@ func (a *App) ScanNormalPath(path string) scanner.FullScanResult {
 		runtime.EventsEmit(a.ctx, "scan:progress", progress)
 	})
 
+	a.loadFingerprints()
+
 	runtime.EventsEmit(a.ctx, "scan:started", nil)
 	result := a.normalScanner.ScanPath(path)
 
@@ -177,15 +242,178 @@ func (a *App) ScanNormalPath(path string) scanner.FullScanResult {
 
 	// Save to cache
 	_ = cache.SaveNormalScan(result, path)
+	_ = cache.SaveFingerprintIndex(a.fingerprints)
+	a.watchNormalResult(result)
 
 	runtime.EventsEmit(a.ctx, "scan:completed:normal", result)
 
 	return result
 }
 
+// loadFingerprints loads the directory fingerprints on the first normal scan
+// so later scans only re-read directories that changed
+func (a *App) loadFingerprints() {
+	if a.fingerprints == nil {
+		a.fingerprints = cache.LoadFingerprintIndex()
+		a.normalScanner.SetFingerprintIndex(a.fingerprints)
+	}
+}
+
 // GetDirectoryChildren returns the children of a directory for lazy loading
 func (a *App) GetDirectoryChildren(path string) ([]*scanner.FileNode, error) {
-	return a.normalScanner.GetDirectoryChildren(path)
+	children, err := a.normalScanner.GetDirectoryChildren(path)
+	if err == nil {
+		a.watchMu.Lock()
+		if a.watchedTree != nil {
+			a.watchedTree.Graft(path, children)
+		}
+		a.watchMu.Unlock()
+	}
+	return children, err
+}
+
+// --- Watch Methods ---
+
+// SetWatchEnabled turns live updates of scan results on or off
+// While enabled, the directories of the last scans are watched and changes
+// are sent as "tree:updated" and "categories:updated" events.
+func (a *App) SetWatchEnabled(enabled bool) error {
+	if err := settings.SetWatchEnabled(enabled); err != nil {
+		return err
+	}
+	if !enabled {
+		a.stopWatching()
+	}
+	return nil
+}
+
+// GetWatchEnabled returns whether live updates are enabled
+func (a *App) GetWatchEnabled() bool {
+	return settings.GetWatchEnabled()
+}
+
+// watchNormalResult starts watching the root of a finished normal scan
+func (a *App) watchNormalResult(result scanner.FullScanResult) {
+	if !settings.GetWatchEnabled() || result.Root == nil {
+		return
+	}
+	a.watchMu.Lock()
+	a.watchedTree = watcher.NewTree(result, a.normalScanner)
+	a.watchMu.Unlock()
+	go a.rewatch()
+}
+
+// watchDevResult starts watching the category paths of a finished dev scan
+func (a *App) watchDevResult(result scanner.ScanResult) {
+	if !settings.GetWatchEnabled() {
+		return
+	}
+	a.watchMu.Lock()
+	a.watchedDev = &result
+	a.watchMu.Unlock()
+	go a.rewatch()
+}
+
+// rewatch points the watcher at the roots of the watched results
+// Adding the watches walks every watched tree, so it runs in the background
+// and without holding watchMu. rewatchMu orders the walks, so the last one
+// sees the latest results.
+func (a *App) rewatch() {
+	a.rewatchMu.Lock()
+	defer a.rewatchMu.Unlock()
+
+	a.watchMu.Lock()
+	var roots []string
+	if a.watchedTree != nil {
+		roots = append(roots, a.watchedTree.Result().Root.Path)
+	}
+	if a.watchedDev != nil {
+		roots = append(roots, watcher.CategoryRoots(a.watchedDev.Categories)...)
+	}
+	if a.watcher == nil {
+		a.watcher = watcher.New(watcher.DefaultOptions(), a.applyChanges)
+	}
+	w := a.watcher
+	a.watchMu.Unlock()
+
+	_ = w.Watch(roots)
+}
+
+// stopWatching stops the watcher and forgets the watched results
+// It waits for a rewatch in progress, which would otherwise restart the
+// watcher after it is closed.
+func (a *App) stopWatching() {
+	a.rewatchMu.Lock()
+	defer a.rewatchMu.Unlock()
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+
+	if a.watcher != nil {
+		_ = a.watcher.Close()
+	}
+	a.watchedTree = nil
+	a.watchedDev = nil
+}
+
+// applyChanges updates the watched results and notifies the frontend
+// The changed directories are measured without holding watchMu, so lazy
+// loading and starting or stopping the watcher don't wait for them.
+func (a *App) applyChanges(dirs []string) {
+	a.watchMu.Lock()
+	tree, dev := a.watchedTree, a.watchedDev
+	var ids []string
+	if dev != nil {
+		ids = watcher.AffectedCategories(dev.Categories, dirs)
+	}
+	a.watchMu.Unlock()
+
+	if tree != nil {
+		updates := tree.Apply(dirs)
+
+		// The tree may have been replaced by a new scan or dropped meanwhile
+		a.watchMu.Lock()
+		if a.watchedTree == tree && len(updates) > 0 {
+			for _, update := range updates {
+				runtime.EventsEmit(a.ctx, "tree:updated", update)
+			}
+			result := tree.Result()
+			_ = cache.SaveNormalScan(result, result.Root.Path)
+		}
+		a.watchMu.Unlock()
+	}
+
+	if dev == nil || len(ids) == 0 {
+		return
+	}
+	var measured []scanner.Category
+	for _, id := range ids {
+		if cat := a.devScanner.ScanCategory(id); cat != nil {
+			measured = append(measured, *cat)
+		}
+	}
+
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+
+	// The dev result may have been replaced or dropped meanwhile
+	if a.watchedDev != dev {
+		return
+	}
+	changed := false
+	for _, cat := range measured {
+		changed = watcher.UpdateCategory(dev, cat) || changed
+	}
+	if changed {
+		_ = cache.SaveDevScan(*dev)
+		runtime.EventsEmit(a.ctx, "categories:updated", *dev)
+
+		// Keep trends current without a snapshot for every change
+		if time.Since(a.lastSnapshot) >= watchSnapshotInterval {
+			if tm, err := scanner.NewTrendsManager(); err == nil && tm.RecordSnapshot(*dev) == nil {
+				a.lastSnapshot = time.Now()
+			}
+		}
+	}
 }
 
 // --- Utility Methods ---
@@ -223,195 +451,162 @@ func (a *App) FormatSize(bytes int64) string {
 // If permanent is true, uses os.RemoveAll for permanent deletion
 // If permanent is false, moves to system Trash
 // Emits progress events for batch operations
-func (a *App) DeletePaths(paths []string, permanent bool) scanner.CleanResult {
-	runtime.EventsEmit(a.ctx, "clean:started", nil)
-
-	result := scanner.CleanResult{
-		FreedBytes:     0,
-		DeletedPaths:   []string{},
-		Errors:         []string{},
-		DetailedErrors: []scanner.CleanError{},
-	}
-
-	total := len(paths)
-	for i, path := range paths {
-		// Emit progress
-		progress := scanner.CleanProgress{
-			Current:     i + 1,
-			Total:       total,
-			CurrentPath: path,
-			BytesFreed:  result.FreedBytes,
-			CurrentItem: truncatePath(path),
-		}
-		runtime.EventsEmit(a.ctx, "clean:progress", progress)
-
-		// Check if path exists
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			continue // Skip non-existent paths
-		}
-
-		// Get size before deletion
-		walkResult := scanner.WalkDirectory(path)
-		size := walkResult.Size
+// If dryRun is true, returns the plan without deleting anything
+// Each path is rated by its git repository, see CheckPathSafety.
+func (a *App) DeletePaths(paths []string, permanent bool, dryRun bool) scanner.CleanResult {
+	return a.deletePaths(paths, cleaner.Options{
+		Permanent: permanent,
+		Source:    journal.SourceDeletePaths,
+		DryRun:    dryRun,
+		CheckGit:  true,
+	})
+}
 
-		var err error
-		if permanent {
-			err = os.RemoveAll(path)
-		} else {
-			err = moveToTrash(path)
-		}
+// deletePaths deletes paths, recording them in the journal under the
+// options' source
+// Dry runs emit no events, so the UI doesn't treat the paths as gone.
+func (a *App) deletePaths(paths []string, options cleaner.Options) scanner.CleanResult {
+	if options.DryRun {
+		return cleaner.Plan(paths, options)
+	}
 
-		if err != nil {
-			errorCode := getErrorCode(err)
-			errorMsg := getErrorMessage(err, path)
-			result.Errors = append(result.Errors, errorMsg)
-			result.DetailedErrors = append(result.DetailedErrors, scanner.CleanError{
-				Path:    path,
-				Message: errorMsg,
-				Code:    errorCode,
-			})
-			continue
-		}
+	runtime.EventsEmit(a.ctx, "clean:started", nil)
 
-		result.FreedBytes += size
-		result.DeletedPaths = append(result.DeletedPaths, path)
+	options.Progress = func(progress scanner.CleanProgress) {
+		runtime.EventsEmit(a.ctx, "clean:progress", progress)
 	}
+	result := cleaner.DeletePaths(paths, options)
 
 	runtime.EventsEmit(a.ctx, "clean:completed", result)
 	return result
 }
 
-// getErrorCode returns a code for the error type
-func getErrorCode(err error) string {
-	if os.IsPermission(err) {
-		return "PERMISSION_DENIED"
-	}
-	if os.IsNotExist(err) {
-		return "NOT_FOUND"
-	}
-	if os.IsExist(err) {
-		return "ALREADY_EXISTS"
-	}
-	return "UNKNOWN"
+// ShredPaths permanently deletes paths holding sensitive data, overwriting
+// their files first where that destroys the old contents. The result's
+// Shredded reports say, file by file, what was overwritten and verified,
+// and warn about copy-on-write filesystems and flash storage where old
+// copies may survive.
+// If dryRun is true, only the storage of each file is checked.
+func (a *App) ShredPaths(paths []string, dryRun bool) scanner.CleanResult {
+	return a.deletePaths(paths, cleaner.Options{
+		Shred:    true,
+		Source:   journal.SourceShredPaths,
+		DryRun:   dryRun,
+		CheckGit: true,
+	})
 }
 
-// getErrorMessage returns a user-friendly error message
-func getErrorMessage(err error, path string) string {
-	if os.IsPermission(err) {
-		return "Permission denied: " + truncatePath(path) + ". Try running with elevated permissions."
-	}
-	if os.IsNotExist(err) {
-		return "File not found: " + truncatePath(path)
-	}
-	// Default to original error message
-	return err.Error()
+// CheckPathSafety inspects the git repository around each path: tracked
+// files make deleting it dangerous, untracked files that aren't ignored
+// call for confirmation, ignored build output is safe
+func (a *App) CheckPathSafety(paths []string) []scanner.PathSafety {
+	return gitcheck.NewChecker().CheckAll(paths)
 }
 
 // DeletePath deletes a single path - convenience wrapper for DeletePaths
 func (a *App) DeletePath(path string, permanent bool) scanner.CleanResult {
-	return a.DeletePaths([]string{path}, permanent)
+	return a.DeletePaths([]string{path}, permanent, false)
 }
 
 // CleanCategories cleans the specified category IDs
 // Uses permanent delete setting from user preferences
-func (a *App) CleanCategories(categoryIDs []string) scanner.CleanResult {
-	// Get all categories and collect paths for the specified IDs
-	categories := scanner.GetCategories()
-	var pathsToClean []string
-
-	for _, id := range categoryIDs {
-		// Skip disabled categories
-		if !settings.IsCategoryEnabled(id) {
-			continue
-		}
-		cat := scanner.GetCategoryByID(categories, id)
-		if cat == nil {
-			continue
-		}
-		// Collect all paths from this category and its children
-		collectPathsFromCategory(cat, &pathsToClean, nil)
-	}
+// If dryRun is true, returns the plan without deleting anything
+func (a *App) CleanCategories(categoryIDs []string, dryRun bool) scanner.CleanResult {
+	pathsToClean := cleaner.CategoryPaths(scanner.GetCategories(), categoryIDs)
+
+	// Delete using user's preference
+	return a.deletePaths(pathsToClean, cleaner.Options{
+		Permanent: settings.GetPermanentDelete(),
+		Source:    journal.SourceCleanCategories,
+		DryRun:    dryRun,
+	})
+}
 
-	// Remove duplicates and delete using user's preference
-	permanent := settings.GetPermanentDelete()
-	return a.DeletePaths(uniquePaths(pathsToClean), permanent)
+// GetStaleCategoryItems returns the items of a category unused for as long
+// as filter asks
+func (a *App) GetStaleCategoryItems(categoryID string, filter scanner.AgeFilter) ([]scanner.FileNode, error) {
+	return a.devScanner.GetStaleCategoryItems(categoryID, filter)
 }
 
-// collectPathsFromCategory recursively collects all paths from a category
-func collectPathsFromCategory(cat *scanner.Category, paths *[]string, sizes map[string]int64) {
-	for _, path := range cat.Paths {
-		*paths = append(*paths, path)
-		if sizes != nil {
-			sizes[path] = cat.Size
-		}
-	}
-	for i := range cat.Children {
-		collectPathsFromCategory(&cat.Children[i], paths, sizes)
+// CleanStaleItems cleans the items of the given categories that filter
+// matches, leaving recently used ones
+// If dryRun is true, returns the plan without deleting anything
+func (a *App) CleanStaleItems(categoryIDs []string, filter scanner.AgeFilter, dryRun bool) scanner.CleanResult {
+	var paths []string
+	for _, item := range cleaner.StaleItems(categoryIDs, filter) {
+		paths = append(paths, item.Path)
 	}
+
+	return a.deletePaths(paths, cleaner.Options{
+		Permanent: settings.GetPermanentDelete(),
+		Source:    journal.SourceCleanStaleItems,
+		DryRun:    dryRun,
+	})
 }
 
-// uniquePaths removes duplicate paths
-func uniquePaths(paths []string) []string {
-	seen := make(map[string]bool)
-	result := []string{}
-	for _, path := range paths {
-		if !seen[path] {
-			seen[path] = true
-			result = append(result, path)
-		}
-	}
-	return result
+// ListOperations returns the journal of delete operations, newest first
+func (a *App) ListOperations() []journal.Operation {
+	return journal.List()
 }
 
-// truncatePath shortens a path for display
-func truncatePath(path string) string {
-	parts := []string{}
-	for _, part := range []byte(path) {
-		if part == '/' {
-			parts = append(parts, "")
-		}
-	}
-	// Simple truncation - show last 3 components
-	pathParts := splitPath(path)
-	if len(pathParts) <= 3 {
-		return path
-	}
-	return ".../" + joinPath(pathParts[len(pathParts)-3:])
+// RestoreOperation puts back everything an operation moved to the trash
+// onConflict is "skip" (default), "rename" or "replace".
+func (a *App) RestoreOperation(id string, onConflict string) cleaner.RestoreResult {
+	return cleaner.RestoreOperation(id, cleaner.ConflictPolicy(onConflict))
 }
 
-func splitPath(path string) []string {
-	result := []string{}
-	current := ""
-	for _, c := range path {
-		if c == '/' {
-			if current != "" {
-				result = append(result, current)
-				current = ""
-			}
-		} else {
-			current += string(c)
-		}
-	}
-	if current != "" {
-		result = append(result, current)
-	}
-	return result
+// RestorePaths puts back the most recently trashed item of each path
+func (a *App) RestorePaths(paths []string, onConflict string) cleaner.RestoreResult {
+	return cleaner.RestorePaths(paths, cleaner.ConflictPolicy(onConflict))
 }
 
-func joinPath(parts []string) string {
-	result := ""
-	for i, part := range parts {
-		if i > 0 {
-			result += "/"
-		}
-		result += part
-	}
-	return result
+// ListTrash returns what is in the home and per-mount trash directories,
+// largest first
+func (a *App) ListTrash() ([]trash.Item, error) {
+	return trash.List()
+}
+
+// RestoreTrashItem puts a trashed item back at its original path and marks
+// its journal entry restored
+func (a *App) RestoreTrashItem(id string) error {
+	return cleaner.RestoreTrashItem(id)
 }
 
-// moveToTrash moves a file/directory to the system trash (cross-platform)
-func moveToTrash(path string) error {
-	return trash.MoveToTrash(path)
+// PurgeTrash permanently deletes items trashed more than olderThanDays ago
+func (a *App) PurgeTrash(olderThanDays int) trash.PurgeResult {
+	return trash.Purge(time.Duration(olderThanDays) * 24 * time.Hour)
+}
+
+// EmptyTrash permanently deletes everything in the trash
+func (a *App) EmptyTrash() trash.PurgeResult {
+	return trash.Empty()
+}
+
+// QueryAuditLog returns the audit log records filter matches, oldest first
+func (a *App) QueryAuditLog(filter audit.Filter) ([]audit.Record, error) {
+	return audit.Query(filter)
+}
+
+// ExportAuditLog renders the records filter matches as "csv" or "json"
+func (a *App) ExportAuditLog(filter audit.Filter, format string) (string, error) {
+	records, err := audit.Query(filter)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "csv":
+		err = audit.WriteCSV(&buf, records)
+	case "json":
+		err = export.WriteJSON(&buf, records)
+	default:
+		err = fmt.Errorf("unknown format %q, expected csv or json", format)
+	}
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
 
 // --- Settings Methods ---
@@ -424,7 +619,11 @@ func (a *App) GetSettings() *settings.Settings {
 
 // SaveSettings saves the settings
 func (a *App) SaveSettings(s *settings.Settings) error {
-	return settings.Save(s)
+	if err := settings.Save(s); err != nil {
+		return err
+	}
+	applyExclusions(s.Exclusions)
+	return nil
 }
 
 // SetPermanentDelete sets the permanent delete preference
@@ -447,13 +646,63 @@ func (a *App) IsCategoryEnabled(categoryID string) bool {
 	return settings.IsCategoryEnabled(categoryID)
 }
 
+// GetExclusions returns the paths and patterns skipped by every scan
+func (a *App) GetExclusions() settings.Exclusions {
+	return settings.GetExclusions()
+}
+
+// SetExclusions saves the exclusion rules and applies them to later scans
+func (a *App) SetExclusions(exclusions settings.Exclusions) error {
+	if err := settings.SetExclusions(exclusions); err != nil {
+		return err
+	}
+	applyExclusions(exclusions)
+	return nil
+}
+
+// GetPolicies returns the automatic cleanup policies
+func (a *App) GetPolicies() []settings.Policy {
+	return settings.GetPolicies()
+}
+
+// SetPolicies saves the automatic cleanup policies
+// The scheduler reads them on its next check.
+func (a *App) SetPolicies(policies []settings.Policy) error {
+	return settings.SetPolicies(policies, policy.IsCategory)
+}
+
+// RunPolicies applies every enabled policy now, whether due or not
+// If dryRun is true, returns what each policy would clean
+func (a *App) RunPolicies(dryRun bool) []policy.Run {
+	return policy.Apply(context.Background(), settings.GetPolicies(), policy.Options{
+		DryRun: dryRun,
+		Force:  true,
+	})
+}
+
 // --- Node Modules Scanner Methods ---
 
+// GetProjectSearch returns where node_modules and artifacts are looked for
+func (a *App) GetProjectSearch() settings.ProjectSearch {
+	return settings.GetProjectSearch()
+}
+
+// SetProjectSearch saves where node_modules and artifacts are looked for
+func (a *App) SetProjectSearch(search settings.ProjectSearch) error {
+	return settings.SetProjectSearch(search)
+}
+
 // ScanNodeModules finds all node_modules directories across projects
 func (a *App) ScanNodeModules() scanner.NodeModulesResult {
+	return a.ScanNodeModulesIn(settings.GetProjectSearch())
+}
+
+// ScanNodeModulesIn finds node_modules directories using the given search
+// roots and limits instead of the saved ones
+func (a *App) ScanNodeModulesIn(search settings.ProjectSearch) scanner.NodeModulesResult {
 	runtime.EventsEmit(a.ctx, "nodemodules:started", nil)
 
-	result := scanner.FindNodeModules(func(current int, path string) {
+	result := scanner.FindNodeModules(scanner.ProjectSearch(search), func(current int, path string) {
 		runtime.EventsEmit(a.ctx, "nodemodules:progress", map[string]interface{}{
 			"current": current,
 			"path":    path,
@@ -465,61 +714,72 @@ func (a *App) ScanNodeModules() scanner.NodeModulesResult {
 }
 
 // DeleteNodeModules deletes the specified node_modules directories
-func (a *App) DeleteNodeModules(paths []string) scanner.CleanResult {
+// If dryRun is true, returns the plan without deleting anything
+func (a *App) DeleteNodeModules(paths []string, dryRun bool) scanner.CleanResult {
+	options := cleaner.Options{
+		Permanent: settings.GetPermanentDelete(),
+		Source:    journal.SourceDeleteNodeModules,
+		DryRun:    dryRun,
+		CheckGit:  true,
+	}
+	if dryRun {
+		return cleaner.DeleteNodeModules(paths, options)
+	}
+
 	runtime.EventsEmit(a.ctx, "nodemodules:clean:started", nil)
 
-	permanent := settings.GetPermanentDelete()
-	result := scanner.CleanResult{
-		FreedBytes:     0,
-		DeletedPaths:   []string{},
-		Errors:         []string{},
-		DetailedErrors: []scanner.CleanError{},
-	}
-
-	total := len(paths)
-	for i, path := range paths {
-		// Get size before deletion (use 4 workers for speed)
-		walkResult := scanner.WalkDirectoryFast(path, 4)
-		size := walkResult.Size
-
-		progress := scanner.CleanProgress{
-			Current:     i + 1,
-			Total:       total,
-			CurrentPath: path,
-			BytesFreed:  result.FreedBytes,
-			CurrentItem: truncatePath(path),
-		}
+	options.Progress = func(progress scanner.CleanProgress) {
 		runtime.EventsEmit(a.ctx, "nodemodules:clean:progress", progress)
+	}
+	result := cleaner.DeleteNodeModules(paths, options)
 
-		// Check if path exists
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			continue
-		}
+	runtime.EventsEmit(a.ctx, "nodemodules:clean:completed", result)
+	return result
+}
 
-		var err error
-		if permanent {
-			err = os.RemoveAll(path)
-		} else {
-			err = moveToTrash(path)
-		}
+// --- Project Artifact Methods ---
 
-		if err != nil {
-			errorCode := getErrorCode(err)
-			errorMsg := getErrorMessage(err, path)
-			result.Errors = append(result.Errors, errorMsg)
-			result.DetailedErrors = append(result.DetailedErrors, scanner.CleanError{
-				Path:    path,
-				Message: errorMsg,
-				Code:    errorCode,
-			})
-			continue
-		}
+// ScanArtifacts finds build artifacts (node_modules, target, .venv, ...)
+// across projects, grouped by ecosystem
+func (a *App) ScanArtifacts() scanner.ArtifactsResult {
+	return a.ScanArtifactsIn(settings.GetProjectSearch())
+}
 
-		result.FreedBytes += size
-		result.DeletedPaths = append(result.DeletedPaths, path)
+// ScanArtifactsIn finds build artifacts using the given search roots and
+// limits instead of the saved ones
+func (a *App) ScanArtifactsIn(search settings.ProjectSearch) scanner.ArtifactsResult {
+	runtime.EventsEmit(a.ctx, "artifacts:started", nil)
+
+	result := scanner.FindArtifacts(nil, scanner.ProjectSearch(search), func(current int, path string) {
+		runtime.EventsEmit(a.ctx, "artifacts:progress", map[string]interface{}{
+			"current": current,
+			"path":    path,
+		})
+	})
+
+	runtime.EventsEmit(a.ctx, "artifacts:completed", result)
+	return result
+}
+
+// DeleteArtifacts deletes the specified project artifacts
+// If dryRun is true, returns the plan without deleting anything
+func (a *App) DeleteArtifacts(paths []string, dryRun bool) scanner.CleanResult {
+	if dryRun {
+		return cleaner.Plan(paths, cleaner.Options{Permanent: settings.GetPermanentDelete(), CheckGit: true})
 	}
 
-	runtime.EventsEmit(a.ctx, "nodemodules:clean:completed", result)
+	runtime.EventsEmit(a.ctx, "artifacts:clean:started", nil)
+
+	result := cleaner.DeletePaths(paths, cleaner.Options{
+		Permanent: settings.GetPermanentDelete(),
+		Source:    journal.SourceDeleteArtifacts,
+		CheckGit:  true,
+		Progress: func(progress scanner.CleanProgress) {
+			runtime.EventsEmit(a.ctx, "artifacts:clean:progress", progress)
+		},
+	})
+
+	runtime.EventsEmit(a.ctx, "artifacts:clean:completed", result)
 	return result
 }
 
@@ -541,7 +801,10 @@ func (a *App) LoadCachedNormalScan() *cache.CachedNormalScan {
 }
 
 // ClearCache removes all cached scan results
+// The next normal scan is a full one
 func (a *App) ClearCache() error {
+	a.fingerprints = nil
+	a.normalScanner.SetFingerprintIndex(nil)
 	return cache.ClearCache()
 }
 
@@ -599,13 +862,7 @@ func (a *App) FindDuplicates() scanner.DuplicatesResult {
 	home, _ := os.UserHomeDir()
 	options := scanner.DefaultDuplicatesOptions()
 
-	result := scanner.FindDuplicates(home, options, func(phase string, current int, total int) {
-		runtime.EventsEmit(a.ctx, "duplicates:progress", map[string]interface{}{
-			"phase":   phase,
-			"current": current,
-			"total":   total,
-		})
-	})
+	result := a.findDuplicates(home, options)
 
 	runtime.EventsEmit(a.ctx, "duplicates:completed", result)
 	return result
@@ -622,6 +879,35 @@ func (a *App) FindDuplicatesInPath(rootPath string, minSizeKB int) scanner.Dupli
 	options := scanner.DefaultDuplicatesOptions()
 	options.MinSize = int64(minSizeKB) * 1024
 
+	result := a.findDuplicates(rootPath, options)
+
+	runtime.EventsEmit(a.ctx, "duplicates:completed", result)
+	return result
+}
+
+// FindDuplicateDirsInPath scans for identical directory trees in a specific path
+func (a *App) FindDuplicateDirsInPath(rootPath string, minSizeKB int) scanner.DuplicatesResult {
+	runtime.EventsEmit(a.ctx, "duplicates:started", nil)
+
+	if rootPath == "" {
+		rootPath, _ = os.UserHomeDir()
+	}
+
+	options := scanner.DefaultDuplicatesOptions()
+	options.MinSize = int64(minSizeKB) * 1024
+	options.Directories = true
+
+	result := a.findDuplicates(rootPath, options)
+
+	runtime.EventsEmit(a.ctx, "duplicates:completed", result)
+	return result
+}
+
+// findDuplicates runs a duplicate scan with progress events, reusing the
+// hashes of files unchanged since earlier scans
+func (a *App) findDuplicates(rootPath string, options scanner.DuplicatesOptions) scanner.DuplicatesResult {
+	options.Hashes = cache.LoadHashIndex()
+
 	result := scanner.FindDuplicates(rootPath, options, func(phase string, current int, total int) {
 		runtime.EventsEmit(a.ctx, "duplicates:progress", map[string]interface{}{
 			"phase":   phase,
@@ -630,14 +916,53 @@ func (a *App) FindDuplicatesInPath(rootPath string, minSizeKB int) scanner.Dupli
 		})
 	})
 
-	runtime.EventsEmit(a.ctx, "duplicates:completed", result)
+	_ = cache.SaveHashIndex(options.Hashes)
 	return result
 }
 
 // DeleteDuplicateGroup deletes duplicates from a group, keeping the file at keepIndex
-func (a *App) DeleteDuplicateGroup(group scanner.DuplicateGroup, keepIndex int) scanner.CleanResult {
-	permanent := settings.GetPermanentDelete()
-	return scanner.DeleteDuplicates([]scanner.DuplicateGroup{group}, keepIndex, permanent, trash.MoveToTrash)
+// If dryRun is true, returns the plan without deleting anything
+func (a *App) DeleteDuplicateGroup(group scanner.DuplicateGroup, keepIndex int, dryRun bool) scanner.CleanResult {
+	op := journal.NewOperation(journal.SourceDeleteDuplicateGroup)
+	deleter := cleaner.Journaled(op, settings.GetPermanentDelete())
+	if dryRun {
+		deleter = scanner.DryRunDeleter(deleter)
+	}
+
+	result := scanner.DeleteDuplicates([]scanner.DuplicateGroup{group}, keepIndex, deleter)
+	if len(op.Entries) > 0 && journal.Record(op) == nil {
+		result.OperationID = op.ID
+	}
+	return result
+}
+
+// PlanDuplicateDeletion applies keep rules to many groups at once and
+// returns the decisions for review; nothing is deleted
+func (a *App) PlanDuplicateDeletion(groups []scanner.DuplicateGroup, rules scanner.KeepRules) ([]scanner.DuplicateDecision, error) {
+	return scanner.DecideDuplicates(groups, rules)
+}
+
+// DeleteDuplicateDecisions deletes what reviewed decisions mark for deletion
+// If dryRun is true, returns the plan without deleting anything
+func (a *App) DeleteDuplicateDecisions(decisions []scanner.DuplicateDecision, dryRun bool) scanner.CleanResult {
+	op := journal.NewOperation(journal.SourceDeleteDuplicateDecisions)
+	deleter := cleaner.Journaled(op, settings.GetPermanentDelete())
+	if dryRun {
+		deleter = scanner.DryRunDeleter(deleter)
+	}
+
+	result := scanner.DeleteDecided(decisions, deleter)
+	if len(op.Entries) > 0 && journal.Record(op) == nil {
+		result.OperationID = op.ID
+	}
+	return result
+}
+
+// DedupeDuplicateGroup replaces the duplicates of a group with links to the
+// file at keepIndex instead of deleting them. action is scanner.ActionReflink
+// or scanner.ActionHardlink. If dryRun is true, only checks the duplicates.
+func (a *App) DedupeDuplicateGroup(group scanner.DuplicateGroup, keepIndex int, action string, dryRun bool) scanner.CleanResult {
+	return cleaner.Dedupe(context.Background(), []scanner.DuplicateGroup{group}, keepIndex, action, dryRun, audit.SourceDedupeDuplicateGroup)
 }
 
 // GetDiskTrends returns disk usage trends
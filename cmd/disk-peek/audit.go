@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"disk-peek/internal/audit"
+	"disk-peek/internal/export"
+	"disk-peek/internal/scanner"
+)
+
+// formatCSV is only offered by the audit command
+const formatCSV = "csv"
+
+func runAudit(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	format := fs.String("format", formatText, "output format: text, csv, json or ndjson")
+	since := fs.String("since", "", "only records from this date on (YYYY-MM-DD or RFC 3339)")
+	until := fs.String("until", "", "only records before this date (YYYY-MM-DD or RFC 3339)")
+	path := fs.String("path", "", "only records of this path and everything below it")
+	method := fs.String("method", "", "only records of this method: trash, permanent, shred, reflink or hardlink")
+	source := fs.String("source", "", "only records started by this API, e.g. CleanCategories")
+	outcome := fs.String("outcome", "", "only records with this outcome: success or failure")
+	limit := fs.Int("limit", 0, "only the most recent records")
+	parseArgs(fs, args)
+
+	if *format != formatCSV {
+		if err := checkFormat(*format); err != nil {
+			return fmt.Errorf("unknown format %q, expected text, csv, json or ndjson", *format)
+		}
+	}
+
+	filter := audit.Filter{Method: *method, Source: *source, Outcome: *outcome, Limit: *limit}
+	var err error
+	if filter.Since, err = parseDate(*since); err != nil {
+		return err
+	}
+	if filter.Until, err = parseDate(*until); err != nil {
+		return err
+	}
+	if *path != "" {
+		if filter.PathPrefix, err = filepath.Abs(*path); err != nil {
+			return err
+		}
+	}
+
+	records, err := audit.Query(filter)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatCSV:
+		return audit.WriteCSV(os.Stdout, records)
+	case formatJSON:
+		return export.WriteJSON(os.Stdout, records)
+	case formatNDJSON:
+		return export.NewNDJSONWriter(os.Stdout).WriteItems(records)
+	}
+
+	if len(records) == 0 {
+		fmt.Println("No deletions recorded")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, record := range records {
+		result := record.Outcome
+		if record.Error != "" {
+			result += ": " + record.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", record.Time.Local().Format("2006-01-02 15:04:05"),
+			record.User, record.Source, record.Method, scanner.FormatSize(record.Size), record.Path, result)
+	}
+	return w.Flush()
+}
+
+// parseDate parses a -since or -until flag; an empty value is the zero time
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", value)
+	}
+	return t, nil
+}
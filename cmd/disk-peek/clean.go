@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"disk-peek/internal/cleaner"
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+	"disk-peek/internal/settings"
+)
+
+func runClean(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	permanent := fs.Bool("permanent", settings.GetPermanentDelete(), "delete permanently instead of moving to trash")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "show what would be deleted without deleting anything")
+	unusedDays := fs.Int("unused-days", 0, "only clean items not read or written for this many days")
+	parseArgs(fs, args)
+
+	if fs.NArg() == 0 {
+		return errors.New(`expected at least one category ID (see "disk-peek categories")`)
+	}
+
+	categories := scanner.GetCategories()
+	for _, id := range fs.Args() {
+		if scanner.GetCategoryByID(categories, id) == nil {
+			return fmt.Errorf("unknown category %q", id)
+		}
+	}
+
+	var paths []string
+	if *unusedDays > 0 {
+		for _, item := range cleaner.StaleItems(fs.Args(), scanner.AgeFilter{OlderThanDays: *unusedDays}) {
+			paths = append(paths, item.Path)
+		}
+	} else {
+		paths = cleaner.CategoryPaths(categories, fs.Args())
+	}
+	if len(paths) == 0 {
+		fmt.Println("Nothing to clean")
+		return nil
+	}
+
+	if *dryRun {
+		return printPlan(cleaner.Plan(paths, cleaner.Options{Permanent: *permanent}))
+	}
+
+	if !*yes {
+		action := "Move to trash"
+		if *permanent {
+			action = "Permanently delete"
+		}
+		fmt.Printf("%s:\n", action)
+		for _, path := range paths {
+			fmt.Printf("  %s\n", path)
+		}
+		if err := confirm(ctx, "Continue?"); err != nil {
+			return err
+		}
+	}
+
+	source := journal.SourceCleanCategories
+	if *unusedDays > 0 {
+		source = journal.SourceCleanStaleItems
+	}
+	result := cleaner.DeletePaths(paths, cleaner.Options{
+		Permanent: *permanent,
+		Source:    source,
+		Ctx:       ctx,
+	})
+
+	for _, path := range result.DeletedPaths {
+		fmt.Printf("removed %s\n", path)
+	}
+	for _, cleanErr := range result.DetailedErrors {
+		fmt.Fprintf(os.Stderr, "failed %s: %s\n", cleanErr.Path, cleanErr.Message)
+	}
+	fmt.Printf("\nFreed %s\n", scanner.FormatSize(result.FreedBytes))
+	if result.OperationID != "" && !*permanent {
+		fmt.Printf("Undo with: disk-peek restore %s\n", result.OperationID)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(result.DetailedErrors) > 0 {
+		return fmt.Errorf("%d paths could not be cleaned", len(result.DetailedErrors))
+	}
+	return nil
+}
+
+// printPlan prints the result of a dry run
+func printPlan(result scanner.CleanResult) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, planned := range result.Plan {
+		problem := ""
+		if planned.Error != nil {
+			problem = planned.Error.Code
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", planned.Action, scanner.FormatSize(planned.Size), planned.Path, problem)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nWould free %s (dry run, nothing was deleted)\n", scanner.FormatSize(result.FreedBytes))
+
+	if len(result.DetailedErrors) > 0 {
+		return fmt.Errorf("%d paths could not be cleaned", len(result.DetailedErrors))
+	}
+	return nil
+}
+
+// errAborted is returned when a confirmation is declined
+var errAborted = errors.New("aborted")
+
+// confirm asks a yes/no question on stdin, defaulting to no
+// It returns errAborted unless the answer is yes, or ctx's error if ctx is
+// done before an answer is given.
+func confirm(ctx context.Context, question string) error {
+	fmt.Printf("%s [y/N] ", question)
+
+	answers := make(chan string, 1)
+	go func() {
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			answer = ""
+		}
+		answers <- answer
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Println()
+		return ctx.Err()
+	case answer := <-answers:
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			return errAborted
+		}
+		return nil
+	}
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"disk-peek/internal/audit"
+	"disk-peek/internal/cache"
+	"disk-peek/internal/cleaner"
+	"disk-peek/internal/export"
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+	"disk-peek/internal/settings"
+)
+
+func runLarge(ctx context.Context, args []string) error {
+	options := scanner.DefaultLargeFilesOptions()
+
+	fs := flag.NewFlagSet("large", flag.ExitOnError)
+	root := fs.String("root", "", "directory to search (default: home directory)")
+	minSizeMB := fs.Int("min-size", int(options.MinSize/(1024*1024)), "minimum file size in MB")
+	maxResults := fs.Int("max", options.MaxResults, "maximum number of results (0 = no limit)")
+	types := fs.String("types", "", "comma separated extensions to include, e.g. .iso,.dmg")
+	verbose := fs.Bool("v", false, "report progress on stderr")
+	format := formatFlag(fs)
+	parseArgs(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	options.MinSize = int64(*minSizeMB) * 1024 * 1024
+	options.MaxResults = *maxResults
+	if *types != "" {
+		options.FileTypes = strings.Split(*types, ",")
+	}
+
+	var progress func(scanned int, currentPath string)
+	if *verbose {
+		progress = func(scanned int, currentPath string) {
+			fmt.Fprintf(os.Stderr, "[%d] %s\n", scanned, currentPath)
+		}
+	}
+
+	// Stream every match as it is found; the stream is not limited by -max
+	var stream *export.NDJSONWriter
+	if *format == formatNDJSON {
+		stream = export.NewNDJSONWriter(os.Stdout)
+		options.OnFile = func(file scanner.LargeFile) {
+			_ = stream.LargeFile(file)
+		}
+	}
+
+	result := scanner.FindLargeFilesWithContext(ctx, *root, options, progress)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatNDJSON:
+		return stream.Summary(export.Summary{
+			Kind:         export.KindLargeFiles,
+			TotalSize:    result.TotalSize,
+			TotalCount:   result.TotalCount,
+			ScanDuration: result.ScanDuration,
+		})
+	case formatJSON:
+		return export.WriteJSON(os.Stdout, result)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, file := range result.Files {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", scanner.FormatSize(file.Size), file.ModTime.Format("2006-01-02"), file.Path)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d files, %s total, scanned in %v\n",
+		result.TotalCount, scanner.FormatSize(result.TotalSize), result.ScanDuration.Round(1e6))
+	return nil
+}
+
+func runDupes(ctx context.Context, args []string) error {
+	options := scanner.DefaultDuplicatesOptions()
+
+	fs := flag.NewFlagSet("dupes", flag.ExitOnError)
+	root := fs.String("root", "", "directory to search (default: home directory)")
+	minSizeKB := fs.Int("min-size", int(options.MinSize/1024), "minimum file size in KB")
+	maxGroups := fs.Int("max", options.MaxGroups, "maximum number of groups (0 = no limit)")
+	workers := fs.Int("workers", options.Workers, "number of concurrent hashing workers")
+	dirs := fs.Bool("dirs", false, "find identical directory trees instead of single files")
+	rehash := fs.Bool("rehash", false, "ignore the hashes remembered from earlier scans")
+	dedupe := fs.String("dedupe", "", "replace duplicates with links to the first file of each group: reflink or hardlink")
+	remove := fs.Bool("delete", false, "delete the duplicates, keeping the copies chosen by -keep, -prefer and -protect")
+	keep := fs.String("keep", scanner.KeepOldest, "with -delete, the copy to keep: oldest, newest or shortest-path")
+	prefer := fs.String("prefer", "", "with -delete, comma-separated roots whose copies are kept first, e.g. ~/Pictures,~/Documents")
+	protect := fs.String("protect", "", "with -delete, comma-separated roots nothing is deleted from")
+	permanent := fs.Bool("permanent", settings.GetPermanentDelete(), "with -delete, delete permanently instead of moving to trash")
+	dryRun := fs.Bool("dry-run", false, "with -dedupe or -delete, show the plan without changing anything")
+	yes := fs.Bool("yes", false, "with -dedupe or -delete, do not ask for confirmation")
+	verbose := fs.Bool("v", false, "report progress on stderr")
+	format := formatFlag(fs)
+	parseArgs(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if *dedupe != "" && *dedupe != scanner.ActionReflink && *dedupe != scanner.ActionHardlink {
+		return fmt.Errorf("unknown -dedupe %q, expected reflink or hardlink", *dedupe)
+	}
+	if *dedupe != "" && *dirs {
+		return errors.New("-dedupe links single files and can't be used with -dirs")
+	}
+	if *dedupe != "" && *remove {
+		return errors.New("-dedupe and -delete can't be used together")
+	}
+	rules := scanner.KeepRules{Strategy: *keep}
+	if *prefer != "" {
+		rules.PreferredRoots = strings.Split(*prefer, ",")
+	}
+	if *protect != "" {
+		rules.ProtectedRoots = strings.Split(*protect, ",")
+	}
+	// Catch a bad -keep before scanning
+	if _, err := scanner.DecideDuplicates(nil, rules); err != nil {
+		return err
+	}
+
+	options.MinSize = int64(*minSizeKB) * 1024
+	options.MaxGroups = *maxGroups
+	options.Directories = *dirs
+	if *workers > 0 {
+		options.Workers = *workers
+	}
+
+	var progress func(phase string, current int, total int)
+	if *verbose {
+		progress = func(phase string, current int, total int) {
+			fmt.Fprintf(os.Stderr, "%s: %d/%d\n", phase, current, total)
+		}
+	}
+
+	// Files unchanged since the last scan are not hashed again
+	options.Hashes = cache.LoadHashIndex()
+	if *rehash {
+		options.Hashes = scanner.NewHashIndex()
+	}
+
+	// The hashes taken before an interrupt are kept for the next scan
+	result := scanner.FindDuplicatesWithContext(ctx, *root, options, progress)
+	if err := cache.SaveHashIndex(options.Hashes); err != nil {
+		fmt.Fprintf(os.Stderr, "disk-peek: saving hashes: %v\n", err)
+	}
+	if *verbose {
+		reused, hashed := options.Hashes.Stats()
+		fmt.Fprintf(os.Stderr, "%d files hashed, %d hashes reused\n", hashed, reused)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if *dedupe != "" {
+		return dedupeGroups(ctx, result.Groups, *dedupe, *dryRun, *yes)
+	}
+	if *remove {
+		return deleteGroups(ctx, result.Groups, rules, *permanent, *dryRun, *yes)
+	}
+
+	return writeResult(*format, result, func() error {
+		for _, group := range result.Groups {
+			fmt.Printf("%s x %d (%s wasted)\n", scanner.FormatSize(group.Size), len(group.Files), scanner.FormatSize(group.WastedSize))
+			for _, file := range group.Files {
+				fmt.Printf("  %s\n", file.Path)
+			}
+		}
+
+		fmt.Printf("\n%d groups, %d files, %s wasted, scanned in %v\n",
+			result.TotalGroups, result.TotalFiles, scanner.FormatSize(result.TotalWasted), result.ScanDuration.Round(1e6))
+		return nil
+	})
+}
+
+// dedupeGroups replaces the duplicates found by runDupes with links,
+// keeping the first file of each group
+func dedupeGroups(ctx context.Context, groups []scanner.DuplicateGroup, action string, dryRun, yes bool) error {
+	if len(groups) == 0 {
+		fmt.Println("No duplicates found")
+		return nil
+	}
+	if dryRun {
+		plan := scanner.DedupeDuplicatesWithContext(ctx, groups, 0, action, true)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return printPlan(plan)
+	}
+
+	if !yes {
+		fmt.Printf("Replace with %ss:\n", action)
+		for _, file := range scanner.DuplicatesToDelete(groups, 0) {
+			fmt.Printf("  %s\n", file.Path)
+		}
+		if err := confirm(ctx, "Continue?"); err != nil {
+			return err
+		}
+	}
+
+	result := cleaner.Dedupe(ctx, groups, 0, action, false, audit.SourceDedupeDuplicateGroup)
+	for _, path := range result.DedupedPaths {
+		fmt.Printf("linked %s\n", path)
+	}
+	for _, cleanErr := range result.DetailedErrors {
+		fmt.Fprintf(os.Stderr, "failed %s: %s\n", cleanErr.Path, cleanErr.Message)
+	}
+	fmt.Printf("\nReclaimed %s\n", scanner.FormatSize(result.FreedBytes))
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(result.DetailedErrors) > 0 {
+		return fmt.Errorf("%d duplicates could not be replaced", len(result.DetailedErrors))
+	}
+	return nil
+}
+
+// deleteGroups deletes the duplicates found by runDupes, keeping the
+// copies the rules choose
+func deleteGroups(ctx context.Context, groups []scanner.DuplicateGroup, rules scanner.KeepRules, permanent, dryRun, yes bool) error {
+	decisions, err := scanner.DecideDuplicates(groups, rules)
+	if err != nil {
+		return err
+	}
+	var freed int64
+	for _, decision := range decisions {
+		for i, file := range decision.Keep {
+			if i == 0 {
+				fmt.Printf("keep    %s (%s)\n", file.Path, decision.Reason)
+			} else {
+				fmt.Printf("keep    %s (protected)\n", file.Path)
+			}
+		}
+		for _, file := range decision.Delete {
+			fmt.Printf("delete  %s\n", file.Path)
+		}
+		freed += decision.FreedSize
+	}
+	if freed == 0 {
+		fmt.Println("\nNothing to delete")
+		return nil
+	}
+
+	op := journal.NewOperation(journal.SourceDeleteDuplicateDecisions)
+	deleter := cleaner.Journaled(op, permanent)
+	if dryRun {
+		// The same checks as the real run, without deleting anything
+		plan := scanner.DeleteDecidedWithContext(ctx, decisions, scanner.DryRunDeleter(deleter))
+		for _, cleanErr := range plan.DetailedErrors {
+			fmt.Fprintf(os.Stderr, "cannot delete %s: %s\n", cleanErr.Path, cleanErr.Message)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fmt.Printf("\nWould free %s (dry run, nothing was deleted)\n", scanner.FormatSize(plan.FreedBytes))
+		return nil
+	}
+	if !yes {
+		if err := confirm(ctx, "Continue?"); err != nil {
+			return err
+		}
+	}
+
+	// The files deleted before an interrupt are still journaled
+	result := scanner.DeleteDecidedWithContext(ctx, decisions, deleter)
+	if len(op.Entries) > 0 && journal.Record(op) == nil {
+		result.OperationID = op.ID
+	}
+
+	for _, cleanErr := range result.DetailedErrors {
+		fmt.Fprintf(os.Stderr, "failed %s: %s\n", cleanErr.Path, cleanErr.Message)
+	}
+	fmt.Printf("\nFreed %s\n", scanner.FormatSize(result.FreedBytes))
+	if result.OperationID != "" && !permanent {
+		fmt.Printf("Undo with: disk-peek restore %s\n", result.OperationID)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(result.DetailedErrors) > 0 {
+		return fmt.Errorf("%d duplicates could not be deleted", len(result.DetailedErrors))
+	}
+	return nil
+}
+
+func runNodeModules(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("node-modules", flag.ExitOnError)
+	search := projectSearchFlags(fs)
+	remove := fs.Bool("delete", false, "delete the node_modules directories found")
+	permanent := fs.Bool("permanent", settings.GetPermanentDelete(), "with -delete, delete permanently instead of moving to trash")
+	dryRun := fs.Bool("dry-run", false, "with -delete, show the plan without deleting anything")
+	yes := fs.Bool("yes", false, "with -delete, do not ask for confirmation")
+	verbose := fs.Bool("v", false, "report progress on stderr")
+	format := formatFlag(fs)
+	parseArgs(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	var progress func(current int, path string)
+	if *verbose {
+		progress = func(current int, path string) {
+			fmt.Fprintf(os.Stderr, "[%d] %s\n", current, path)
+		}
+	}
+
+	result := scanner.FindNodeModulesWithContext(ctx, search(), progress)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if *remove {
+		return deleteNodeModules(ctx, result.Projects, *permanent, *dryRun, *yes)
+	}
+
+	return writeResult(*format, result, func() error {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, project := range result.Projects {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", scanner.FormatSize(project.Size), project.ProjectName, project.Path)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Printf("\n%d projects, %s total, scanned in %v\n",
+			result.TotalCount, scanner.FormatSize(result.TotalSize), result.ScanDuration.Round(1e6))
+		return nil
+	})
+}
+
+// deleteNodeModules deletes the node_modules directories found by
+// runNodeModules
+func deleteNodeModules(ctx context.Context, projects []scanner.NodeModulesProject, permanent, dryRun, yes bool) error {
+	if len(projects) == 0 {
+		fmt.Println("No node_modules found")
+		return nil
+	}
+	var paths []string
+	for _, project := range projects {
+		paths = append(paths, project.Path)
+	}
+
+	options := cleaner.Options{
+		Permanent: permanent,
+		Source:    journal.SourceDeleteNodeModules,
+		DryRun:    dryRun,
+		CheckGit:  true,
+		Ctx:       ctx,
+	}
+	if dryRun {
+		plan := cleaner.DeleteNodeModules(paths, options)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return printPlan(plan)
+	}
+
+	if !yes {
+		action := "Move to trash"
+		if permanent {
+			action = "Permanently delete"
+		}
+		fmt.Printf("%s:\n", action)
+		for _, project := range projects {
+			fmt.Printf("  %s (%s)\n", project.Path, scanner.FormatSize(project.Size))
+		}
+		if err := confirm(ctx, "Continue?"); err != nil {
+			return err
+		}
+	}
+
+	result := cleaner.DeleteNodeModules(paths, options)
+	for _, path := range result.DeletedPaths {
+		fmt.Printf("removed %s\n", path)
+	}
+	for _, cleanErr := range result.DetailedErrors {
+		fmt.Fprintf(os.Stderr, "failed %s: %s\n", cleanErr.Path, cleanErr.Message)
+	}
+	fmt.Printf("\nFreed %s\n", scanner.FormatSize(result.FreedBytes))
+	if result.OperationID != "" && !permanent {
+		fmt.Printf("Undo with: disk-peek restore %s\n", result.OperationID)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(result.DetailedErrors) > 0 {
+		return fmt.Errorf("%d directories could not be deleted", len(result.DetailedErrors))
+	}
+	return nil
+}
+
+func runArtifacts(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("artifacts", flag.ExitOnError)
+	search := projectSearchFlags(fs)
+	ecosystems := fs.String("ecosystem", "", "comma-separated ecosystems to look for (node, rust, maven, gradle, python, terraform, bazel, zig)")
+	verbose := fs.Bool("v", false, "report progress on stderr")
+	format := formatFlag(fs)
+	parseArgs(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	var detectors []scanner.ArtifactDetector
+	if *ecosystems != "" {
+		detectors = scanner.ArtifactDetectorsFor(strings.Split(*ecosystems, ","))
+		if len(detectors) == 0 {
+			return fmt.Errorf("no known ecosystem in %q", *ecosystems)
+		}
+	}
+
+	var progress func(current int, path string)
+	if *verbose {
+		progress = func(current int, path string) {
+			fmt.Fprintf(os.Stderr, "[%d] %s\n", current, path)
+		}
+	}
+
+	result := scanner.FindArtifactsWithContext(ctx, detectors, search(), progress)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return writeResult(*format, result, func() error {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, group := range result.Ecosystems {
+			fmt.Fprintf(w, "%s\t%s\t%d artifacts\t\n", group.Ecosystem, scanner.FormatSize(group.TotalSize), len(group.Artifacts))
+			for _, artifact := range group.Artifacts {
+				fmt.Fprintf(w, "  %s\t%s\t%s\t\n", scanner.FormatSize(artifact.Size), artifact.ProjectName, artifact.Path)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Printf("\n%d artifacts, %s total, scanned in %v\n",
+			result.TotalCount, scanner.FormatSize(result.TotalSize), result.ScanDuration.Round(1e6))
+		return nil
+	})
+}
+
+// projectSearchFlags adds the -roots, -max-depth and -stop-at-project flags,
+// which default to the project search in settings
+func projectSearchFlags(fs *flag.FlagSet) func() scanner.ProjectSearch {
+	saved := settings.GetProjectSearch()
+	roots := fs.String("roots", strings.Join(saved.Roots, ","), "comma-separated directories to search (default: common project folders)")
+	maxDepth := fs.Int("max-depth", saved.MaxDepth, "levels below each root to search, 0 for no limit")
+	stop := fs.Bool("stop-at-project", saved.StopAtProject, "don't search below a directory once an artifact is found in it")
+
+	return func() scanner.ProjectSearch {
+		search := scanner.ProjectSearch{MaxDepth: *maxDepth, StopAtProject: *stop}
+		if *roots != "" {
+			search.Roots = strings.Split(*roots, ",")
+		}
+		return search
+	}
+}
+
+func runTrends(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("trends", flag.ExitOnError)
+	format := formatFlag(fs)
+	parseArgs(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	tm, err := scanner.NewTrendsManager()
+	if err != nil {
+		return err
+	}
+	result := tm.GetTrends(scanner.GetCategories())
+
+	return writeResult(*format, result, func() error {
+		if len(result.Snapshots) == 0 {
+			fmt.Println("No snapshots recorded yet")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, trend := range result.CategoryTrends {
+			fmt.Fprintf(w, "%s\t%s/day\t%s\n", trend.CategoryName,
+				scanner.FormatSize(int64(trend.GrowthRate)), scanner.FormatSize(trend.TotalChange))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Printf("\n%d snapshots between %s and %s\n", len(result.Snapshots),
+			result.OldestSnapshot.Format("2006-01-02"), result.NewestSnapshot.Format("2006-01-02"))
+		return nil
+	})
+}
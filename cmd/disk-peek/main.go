@@ -0,0 +1,140 @@
+// Command disk-peek is a headless command line interface to the Disk Peek
+// scanners. It runs without Wails so it can be used over SSH and on build
+// machines that have no display.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"disk-peek/internal/scanner"
+	"disk-peek/internal/settings"
+	"disk-peek/internal/xdg"
+)
+
+const usage = `Usage: disk-peek <command> [flags] [args]
+
+Commands:
+  scan dev             Scan developer cache categories
+  scan path <dir>      Scan a directory and list its largest children
+  large                Find large files
+  dupes                Find duplicate files
+  node-modules         Find node_modules directories in project folders, or delete them
+  artifacts            Find build artifacts (target, .venv, ...) in project folders
+  trends               Show disk usage trends from recorded snapshots
+  clean <ids...>       Clean the given dev categories
+  policies [run]       List cleanup policies, or apply the due ones
+  shred <paths...>     Overwrite sensitive files, then delete them permanently
+  journal              List recorded delete operations
+  restore <id>         Put back what an operation moved to the trash
+  audit                Show the audit log of deletions, or export it as CSV or JSON
+  trash [list]         List what is in the trash, largest first
+  trash restore <id>   Put a trashed item back where it came from
+  trash purge          Permanently delete items trashed long ago
+  trash empty          Empty the trash
+  categories           List the available dev category IDs
+
+Scan commands accept -format text|json|ndjson. JSON output is wrapped in a
+versioned envelope; NDJSON streams one record per item as it is found.
+
+Run "disk-peek <command> -h" for the flags of a command.
+`
+
+// command is a CLI subcommand entry point
+type command func(ctx context.Context, args []string) error
+
+var commands = map[string]command{
+	"scan":         runScan,
+	"large":        runLarge,
+	"dupes":        runDupes,
+	"node-modules": runNodeModules,
+	"artifacts":    runArtifacts,
+	"trends":       runTrends,
+	"clean":        runClean,
+	"policies":     runPolicies,
+	"shred":        runShred,
+	"journal":      runJournal,
+	"restore":      runRestore,
+	"trash":        runTrash,
+	"audit":        runAudit,
+	"categories":   runCategories,
+}
+
+// interruptible lists the commands that stop cleanly once their context is
+// cancelled. The others are left to the default Ctrl+C handling, which
+// kills the process.
+var interruptible = map[string]bool{
+	"scan":         true,
+	"large":        true,
+	"dupes":        true,
+	"node-modules": true,
+	"artifacts":    true,
+	"clean":        true,
+	"policies":     true,
+	"shred":        true,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "-h" || name == "--help" || name == "help" {
+		fmt.Print(usage)
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "disk-peek: unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	// Move files from the pre-XDG ~/.config/disk-peek layout
+	if err := xdg.MigrateLegacy(); err != nil {
+		fmt.Fprintf(os.Stderr, "disk-peek: migrating config files: %v\n", err)
+	}
+
+	// Skip the exclusions configured in settings.json, like the app does
+	exclusions := settings.GetExclusions()
+	scanner.SetExcluder(scanner.NewExcluder(exclusions.Patterns, exclusions.Paths, exclusions.Mounts))
+
+	// Cancel long running scans and deletes on Ctrl+C
+	ctx, stop := context.Background(), func() {}
+	if interruptible[name] {
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	}
+	defer stop()
+
+	if err := cmd(ctx, os.Args[2:]); err != nil {
+		stop()
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "disk-peek %s: interrupted\n", name)
+			os.Exit(130)
+		}
+		fmt.Fprintf(os.Stderr, "disk-peek %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// parseArgs parses flags that may be interleaved with positional arguments,
+// so both "clean -yes npm-cache" and "clean npm-cache -yes" work
+func parseArgs(fs *flag.FlagSet, args []string) {
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	_ = fs.Parse(append([]string{"--"}, positional...))
+}
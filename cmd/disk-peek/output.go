@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"disk-peek/internal/export"
+)
+
+// Output formats supported by the -format flag
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// formatFlag registers the -format flag on a command
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", formatText, "output format: text, json or ndjson")
+}
+
+// checkFormat validates the value of the -format flag
+func checkFormat(format string) error {
+	switch format {
+	case formatText, formatJSON, formatNDJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, expected text, json or ndjson", format)
+	}
+}
+
+// writeResult writes a completed result in the requested format, calling
+// text for human readable output
+func writeResult(format string, result interface{}, text func() error) error {
+	switch format {
+	case formatJSON:
+		return export.WriteJSON(os.Stdout, result)
+	case formatNDJSON:
+		return export.NewNDJSONWriter(os.Stdout).WriteItems(result)
+	default:
+		return text()
+	}
+}
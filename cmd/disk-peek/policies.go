@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"disk-peek/internal/policy"
+	"disk-peek/internal/scanner"
+	"disk-peek/internal/settings"
+)
+
+// runPolicies lists the cleanup policies, or applies them with "run"
+// Running "disk-peek policies run" from cron applies the due policies
+// without the app being open.
+func runPolicies(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "run" {
+		return runPoliciesNow(ctx, args[1:])
+	}
+
+	fs := flag.NewFlagSet("policies", flag.ExitOnError)
+	parseArgs(fs, args)
+
+	policies := settings.GetPolicies()
+	if len(policies) == 0 {
+		fmt.Println(`No policies configured (add them to "policies" in settings.json)`)
+		return nil
+	}
+
+	lastRuns := policy.LastRuns()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTARGET\tRULE\tLAST RUN\t")
+	for _, p := range policies {
+		lastRun := "never"
+		if t, ok := lastRuns[p.ID]; ok {
+			lastRun = t.Format("2006-01-02 15:04")
+		}
+		if !p.Enabled {
+			lastRun += " (disabled)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", p.ID, p.Target, describePolicy(p), lastRun)
+	}
+	return w.Flush()
+}
+
+func runPoliciesNow(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("policies run", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "show what each policy would clean without deleting anything")
+	force := fs.Bool("force", false, "run policies that are not due yet")
+	parseArgs(fs, args)
+
+	runs := policy.Apply(ctx, settings.GetPolicies(), policy.Options{DryRun: *dryRun, Force: *force})
+
+	failed := 0
+	for _, run := range runs {
+		if run.Result == nil {
+			fmt.Printf("%s: skipped, %s\n", run.PolicyID, run.Skipped)
+			continue
+		}
+		verb := "freed"
+		if *dryRun {
+			verb = "would free"
+		}
+		fmt.Printf("%s: %s %s from %d paths\n", run.PolicyID, verb,
+			scanner.FormatSize(run.Result.FreedBytes), len(run.Result.DeletedPaths))
+		for _, cleanErr := range run.Result.DetailedErrors {
+			fmt.Fprintf(os.Stderr, "  failed %s: %s\n", cleanErr.Path, cleanErr.Message)
+		}
+		failed += len(run.Result.DetailedErrors)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d paths could not be cleaned", failed)
+	}
+	return nil
+}
+
+// describePolicy summarizes the conditions of a policy
+func describePolicy(p settings.Policy) string {
+	var rule []string
+	if p.MinSize > 0 {
+		rule = append(rule, "over "+scanner.FormatSize(p.MinSize))
+	}
+	if p.MaxAgeDays > 0 {
+		rule = append(rule, fmt.Sprintf("untouched %dd", p.MaxAgeDays))
+	}
+	if p.IntervalHours > 0 {
+		rule = append(rule, fmt.Sprintf("every %dh", p.IntervalHours))
+	}
+	if p.Permanent {
+		rule = append(rule, "permanent")
+	}
+	if len(rule) == 0 {
+		return "always"
+	}
+	return strings.Join(rule, ", ")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"disk-peek/internal/cleaner"
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+)
+
+func runJournal(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("journal", flag.ExitOnError)
+	verbose := fs.Bool("v", false, "list every path of each operation")
+	parseArgs(fs, args)
+
+	operations := journal.List()
+	if len(operations) == 0 {
+		fmt.Println("No operations recorded yet")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, op := range operations {
+		var size int64
+		restorable := 0
+		for _, entry := range op.Entries {
+			size += entry.Size
+			if entry.Restorable() {
+				restorable++
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d paths\t%s\t%d restorable\n", op.ID,
+			op.Timestamp.Format("2006-01-02 15:04"), op.Source, len(op.Entries),
+			scanner.FormatSize(size), restorable)
+		if *verbose {
+			for _, entry := range op.Entries {
+				fmt.Fprintf(w, "  %s\t\t\t\t%s\t\n", entry.Path, scanner.FormatSize(entry.Size))
+			}
+		}
+	}
+	return w.Flush()
+}
+
+func runRestore(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	onConflict := fs.String("on-conflict", string(cleaner.ConflictSkip), "when the original path exists: skip, rename or replace")
+	paths := fs.Bool("paths", false, "arguments are original paths instead of an operation ID")
+	parseArgs(fs, args)
+
+	switch cleaner.ConflictPolicy(*onConflict) {
+	case cleaner.ConflictSkip, cleaner.ConflictRename, cleaner.ConflictReplace:
+	default:
+		return fmt.Errorf("unknown conflict policy %q, expected skip, rename or replace", *onConflict)
+	}
+
+	var result cleaner.RestoreResult
+	if *paths {
+		if fs.NArg() == 0 {
+			return errors.New("expected at least one path")
+		}
+		var originals []string
+		for _, arg := range fs.Args() {
+			path, err := filepath.Abs(arg)
+			if err != nil {
+				return err
+			}
+			originals = append(originals, path)
+		}
+		result = cleaner.RestorePaths(originals, cleaner.ConflictPolicy(*onConflict))
+	} else {
+		if fs.NArg() != 1 {
+			return errors.New(`expected an operation ID (see "disk-peek journal")`)
+		}
+		result = cleaner.RestoreOperation(fs.Arg(0), cleaner.ConflictPolicy(*onConflict))
+	}
+
+	for _, path := range result.RestoredPaths {
+		fmt.Printf("restored %s\n", path)
+	}
+	for _, path := range result.Conflicts {
+		fmt.Fprintf(os.Stderr, "skipped %s: path exists (use -on-conflict rename or replace)\n", path)
+	}
+	for _, restoreErr := range result.DetailedErrors {
+		fmt.Fprintf(os.Stderr, "failed %s: %s\n", restoreErr.Path, restoreErr.Message)
+	}
+
+	if n := len(result.Conflicts) + len(result.DetailedErrors); n > 0 {
+		return fmt.Errorf("%d paths could not be restored", n)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"disk-peek/internal/cache"
+	"disk-peek/internal/export"
+	"disk-peek/internal/scanner"
+)
+
+func runScan(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(`expected "dev" or "path <dir>"`)
+	}
+
+	switch args[0] {
+	case "dev":
+		return runScanDev(ctx, args[1:])
+	case "path":
+		return runScanPath(ctx, args[1:])
+	default:
+		return fmt.Errorf(`unknown scan mode %q, expected "dev" or "path"`, args[0])
+	}
+}
+
+func runScanDev(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("scan dev", flag.ExitOnError)
+	workers := fs.Int("workers", 8, "number of concurrent workers")
+	quick := fs.Bool("quick", false, "run the faster quick scan without progress reporting")
+	verbose := fs.Bool("v", false, "report progress on stderr")
+	format := formatFlag(fs)
+	parseArgs(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	devScanner := scanner.NewDevScanner(*workers)
+	devScanner.SetContext(ctx)
+	if *verbose {
+		devScanner.SetProgressCallback(func(progress scanner.ScanProgress) {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", progress.Current, progress.Total, progress.CurrentPath)
+		})
+	}
+
+	var result scanner.ScanResult
+	if *quick {
+		result = devScanner.QuickScan()
+	} else {
+		result = devScanner.Scan()
+	}
+	if devScanner.IsCancelled() {
+		return context.Canceled
+	}
+
+	return writeResult(*format, result, func() error {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		printCategories(w, result.Categories, 0)
+		fmt.Fprintf(w, "Total\t%s\n", scanner.FormatSize(result.TotalSize))
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nScanned in %v\n", result.ScanDuration.Round(1e6))
+		return nil
+	})
+}
+
+// printCategories writes an indented category tree, skipping empty categories
+func printCategories(w *tabwriter.Writer, categories []scanner.Category, depth int) {
+	for _, cat := range categories {
+		if cat.Size == 0 {
+			continue
+		}
+		indent := strings.Repeat("  ", depth)
+		fmt.Fprintf(w, "%s%s (%s)\t%s\n", indent, cat.Name, cat.ID, scanner.FormatSize(cat.Size))
+		printCategories(w, cat.Children, depth+1)
+	}
+}
+
+func runScanPath(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("scan path", flag.ExitOnError)
+	workers := fs.Int("workers", 0, "number of concurrent workers (0 = 2x CPU cores)")
+	top := fs.Int("top", 20, "number of children to list (0 = all)")
+	verbose := fs.Bool("v", false, "report progress on stderr")
+	full := fs.Bool("full", false, "re-read every directory instead of reusing unchanged ones")
+	format := formatFlag(fs)
+	parseArgs(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one directory")
+	}
+	root, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(root); err != nil {
+		return err
+	}
+
+	// Directories unchanged since the last scan are not read again
+	fingerprints := cache.LoadFingerprintIndex()
+	if *full {
+		fingerprints = scanner.NewFingerprintIndex()
+	}
+
+	normalScanner := scanner.NewNormalScanner(*workers)
+	normalScanner.SetContext(ctx)
+	normalScanner.SetFingerprintIndex(fingerprints)
+	if *verbose {
+		normalScanner.SetProgressCallback(func(progress scanner.ScanProgress) {
+			fmt.Fprintf(os.Stderr, "%s (%s)\n", progress.CurrentPath, scanner.FormatSize(progress.BytesScanned))
+		})
+	}
+
+	// Stream each node as soon as it is sized instead of waiting for the tree
+	var stream *export.NDJSONWriter
+	if *format == formatNDJSON {
+		stream = export.NewNDJSONWriter(os.Stdout)
+		normalScanner.SetNodeCallback(func(node *scanner.FileNode) {
+			_ = stream.FileNode(node)
+		})
+	}
+
+	result := normalScanner.ScanPath(root)
+	if normalScanner.IsCancelled() {
+		return context.Canceled
+	}
+	if err := cache.SaveFingerprintIndex(fingerprints); err != nil {
+		fmt.Fprintf(os.Stderr, "disk-peek: saving fingerprints: %v\n", err)
+	}
+	if *verbose {
+		reused, walked := fingerprints.Stats()
+		fmt.Fprintf(os.Stderr, "%d directories read, %d unchanged\n", walked, reused)
+	}
+
+	switch *format {
+	case formatNDJSON:
+		return stream.Summary(export.Summary{
+			Kind:         export.KindNormalScan,
+			TotalSize:    result.TotalSize,
+			TotalCount:   len(result.Root.Children),
+			ScanDuration: result.ScanDuration,
+		})
+	case formatJSON:
+		return export.WriteJSON(os.Stdout, result)
+	}
+
+	children := result.Root.Children
+	if *top > 0 && len(children) > *top {
+		children = children[:*top]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, child := range children {
+		name := child.Name
+		if child.IsDir {
+			name += "/"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, scanner.FormatSize(child.Size))
+	}
+	fmt.Fprintf(w, "Total\t%s\n", scanner.FormatSize(result.TotalSize))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nScanned %s in %v\n", result.Root.Path, result.ScanDuration.Round(1e6))
+	return nil
+}
+
+func runCategories(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("categories", flag.ExitOnError)
+	check := fs.Bool("check", false, "validate the custom categories file and exit")
+	parseArgs(fs, args)
+
+	if *check {
+		path, err := scanner.CustomCategoriesPath()
+		if err != nil {
+			return err
+		}
+		if err := scanner.ValidateCustomCategories(); err != nil {
+			return err
+		}
+		fmt.Printf("%s is valid\n", path)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var list func(categories []scanner.Category, depth int)
+	list = func(categories []scanner.Category, depth int) {
+		for _, cat := range categories {
+			fmt.Fprintf(w, "%s%s\t%s\n", strings.Repeat("  ", depth), cat.ID, cat.Name)
+			list(cat.Children, depth+1)
+		}
+	}
+	list(scanner.GetCategories(), 0)
+	return w.Flush()
+}
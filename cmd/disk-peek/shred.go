@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"disk-peek/internal/cleaner"
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+)
+
+func runShred(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("shred", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "only check whether overwriting would reach the data")
+	parseArgs(fs, args)
+
+	if fs.NArg() == 0 {
+		return errors.New("expected at least one path")
+	}
+	var paths []string
+	for _, arg := range fs.Args() {
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+
+	options := cleaner.Options{Shred: true, Source: journal.SourceShredPaths, CheckGit: true, Ctx: ctx}
+	if *dryRun {
+		options.DryRun = true
+		result := cleaner.DeletePaths(paths, options)
+		if err := printShredReports(result.Shredded, true); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return printPlan(result)
+	}
+
+	if !*yes {
+		fmt.Println("Overwrite and permanently delete:")
+		for _, path := range paths {
+			fmt.Printf("  %s\n", path)
+		}
+		if err := confirm(ctx, "This cannot be undone. Continue?"); err != nil {
+			return err
+		}
+	}
+
+	result := cleaner.DeletePaths(paths, options)
+	if err := printShredReports(result.Shredded, false); err != nil {
+		return err
+	}
+	for _, cleanErr := range result.DetailedErrors {
+		fmt.Fprintf(os.Stderr, "failed %s: %s\n", cleanErr.Path, cleanErr.Message)
+	}
+	fmt.Printf("\nShredded %d paths, %s\n", len(result.DeletedPaths), scanner.FormatSize(result.FreedBytes))
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(result.DetailedErrors) > 0 {
+		return fmt.Errorf("%d paths could not be shredded", len(result.DetailedErrors))
+	}
+	return nil
+}
+
+// printShredReports prints one line per file: what it is stored on, whether
+// it was overwritten and verified, and why its data may survive
+func printShredReports(reports []scanner.ShredReport, dryRun bool) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, report := range reports {
+		status := "not overwritten"
+		switch {
+		case dryRun:
+			status = "checked"
+		case report.Verified:
+			status = "overwritten, verified"
+		case report.Overwritten:
+			status = "overwritten"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", status, report.Storage,
+			scanner.FormatSize(report.Size), report.Path, report.Warning)
+	}
+	return w.Flush()
+}
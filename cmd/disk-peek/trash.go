@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"disk-peek/internal/cleaner"
+	"disk-peek/internal/scanner"
+	"disk-peek/internal/trash"
+)
+
+func runTrash(_ context.Context, args []string) error {
+	subcommand := "list"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		subcommand, args = args[0], args[1:]
+	}
+
+	switch subcommand {
+	case "list":
+		return runTrashList(args)
+	case "restore":
+		return runTrashRestore(args)
+	case "purge":
+		return runTrashPurge(args)
+	case "empty":
+		return runTrashEmpty(args)
+	default:
+		return fmt.Errorf("unknown subcommand %q, expected list, restore, purge or empty", subcommand)
+	}
+}
+
+func runTrashList(args []string) error {
+	fs := flag.NewFlagSet("trash list", flag.ExitOnError)
+	parseArgs(fs, args)
+
+	items, err := trash.List()
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("The trash is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var total int64
+	for _, item := range items {
+		total += item.Size
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", scanner.FormatSize(item.Size),
+			formatDeletedAt(item.DeletedAt), item.OriginalPath, item.ID)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d items, %s\n", len(items), scanner.FormatSize(total))
+	return nil
+}
+
+func runTrashRestore(args []string) error {
+	fs := flag.NewFlagSet("trash restore", flag.ExitOnError)
+	parseArgs(fs, args)
+
+	if fs.NArg() == 0 {
+		return errors.New(`expected at least one item ID (see "disk-peek trash list")`)
+	}
+	failed := 0
+	for _, id := range fs.Args() {
+		if err := cleaner.RestoreTrashItem(id); err != nil {
+			fmt.Fprintf(os.Stderr, "failed %s: %v\n", id, err)
+			failed++
+			continue
+		}
+		fmt.Printf("restored %s\n", id)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d items could not be restored", failed)
+	}
+	return nil
+}
+
+func runTrashPurge(args []string) error {
+	fs := flag.NewFlagSet("trash purge", flag.ExitOnError)
+	olderThanDays := fs.Int("older-than-days", 30, "purge items trashed more than this many days ago")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	parseArgs(fs, args)
+
+	if *olderThanDays < 0 {
+		return errors.New("-older-than-days must not be negative")
+	}
+	olderThan := time.Duration(*olderThanDays) * 24 * time.Hour
+
+	if !*yes {
+		items, err := trash.List()
+		if err != nil {
+			return err
+		}
+		cutoff := time.Now().Add(-olderThan)
+		var due []trash.Item
+		for _, item := range items {
+			if !item.DeletedAt.IsZero() && item.DeletedAt.Before(cutoff) {
+				due = append(due, item)
+			}
+		}
+		if len(due) == 0 {
+			fmt.Println("Nothing to purge")
+			return nil
+		}
+		if err := confirmPurge(due); err != nil {
+			return err
+		}
+	}
+	return printPurge(trash.Purge(olderThan))
+}
+
+func runTrashEmpty(args []string) error {
+	fs := flag.NewFlagSet("trash empty", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	parseArgs(fs, args)
+
+	if !*yes {
+		items, err := trash.List()
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			fmt.Println("The trash is empty")
+			return nil
+		}
+		if err := confirmPurge(items); err != nil {
+			return err
+		}
+	}
+	return printPurge(trash.Empty())
+}
+
+// confirmPurge lists the items about to be deleted and asks to go ahead
+// The trash command keeps the default Ctrl+C handling, which ends the
+// prompt with the process.
+func confirmPurge(items []trash.Item) error {
+	var total int64
+	fmt.Println("Permanently delete from the trash:")
+	for _, item := range items {
+		total += item.Size
+		fmt.Printf("  %s (%s)\n", item.OriginalPath, scanner.FormatSize(item.Size))
+	}
+	return confirm(context.Background(), fmt.Sprintf("Free %s?", scanner.FormatSize(total)))
+}
+
+// printPurge reports the outcome of trash.Purge or trash.Empty
+func printPurge(result trash.PurgeResult) error {
+	for _, item := range result.Removed {
+		fmt.Printf("purged %s\n", item.OriginalPath)
+	}
+	for _, message := range result.Errors {
+		fmt.Fprintf(os.Stderr, "failed: %s\n", message)
+	}
+	fmt.Printf("\nFreed %s\n", scanner.FormatSize(result.FreedBytes))
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d items could not be purged", len(result.Errors))
+	}
+	return nil
+}
+
+// formatDeletedAt formats a deletion date, which .trashinfo files may omit
+func formatDeletedAt(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -1,10 +1,11 @@
-import { useState, useMemo, useCallback } from "react";
+import { useState, useMemo, useCallback, useEffect } from "react";
 import type { scanner } from "../../../wailsjs/go/models";
 import { Breadcrumbs } from "./Breadcrumbs";
 import { DeleteConfirmDialog } from "./DeleteConfirmDialog";
 import { ArrowLeft, Folder, File, ChevronRight, FolderOpen, Loader2, Trash2 } from "lucide-react";
 import { Button } from "@/components/ui/button";
 import { GetDirectoryChildren, DeletePath } from "../../../wailsjs/go/main/App";
+import { EventsOn } from "../../../wailsjs/runtime/runtime";
 import { formatSize } from "@/lib/formatters";
 
 interface BreadcrumbItem {
@@ -17,6 +18,14 @@ interface FileTreeResultsProps {
   result: scanner.FullScanResult;
 }
 
+// Sent by the backend when a watched directory changes
+interface TreeUpdate {
+  path: string;
+  node: scanner.FileNode;
+  delta: number;
+  totalSize: number;
+}
+
 // Warmer color palette for files/folders based on their relative size
 const SIZE_COLORS = [
   "#ff7f6e", // coral - largest
@@ -58,6 +67,22 @@ export function FileTreeResults({ result }: FileTreeResultsProps) {
 
   const currentLevel = navigationStack[navigationStack.length - 1];
 
+  // Apply live updates from the directory watcher to the visible level
+  useEffect(() => {
+    const unsubscribe = EventsOn("tree:updated", (update: TreeUpdate) => {
+      if (update.path === currentLevel.path && update.node.children) {
+        setCurrentChildren(update.node.children);
+        return;
+      }
+      setCurrentChildren((prev) =>
+        prev.map((node) =>
+          node.path === update.path ? { ...node, size: update.node.size } : node
+        )
+      );
+    });
+    return () => unsubscribe();
+  }, [currentLevel.path]);
+
   // Calculate total size for current level
   const currentTotalSize = useMemo(() => {
     return currentChildren.reduce((sum, node) => sum + node.size, 0);
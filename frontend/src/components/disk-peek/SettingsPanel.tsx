@@ -1,5 +1,5 @@
 import { useState, useEffect } from "react";
-import { Settings, Trash2, FolderX, X } from "lucide-react";
+import { Settings, Trash2, FolderX, X, Eye } from "lucide-react";
 import { Button } from "../ui/button";
 import {
   AlertDialog,
@@ -13,6 +13,7 @@ import {
 import {
   GetSettings,
   SetPermanentDelete,
+  SetWatchEnabled,
   SetCategoryEnabled,
   GetDevCategories,
 } from "../../../wailsjs/go/main/App";
@@ -25,12 +26,14 @@ interface SettingsPanelProps {
 
 interface AppSettings {
   permanentDelete: boolean;
+  watchEnabled: boolean;
   disabledCategories: Record<string, boolean>;
 }
 
 export function SettingsPanel({ open, onClose }: SettingsPanelProps) {
   const [settings, setSettings] = useState<AppSettings>({
     permanentDelete: false,
+    watchEnabled: false,
     disabledCategories: {},
   });
   const [categories, setCategories] = useState<scanner.Category[]>([]);
@@ -51,6 +54,7 @@ export function SettingsPanel({ open, onClose }: SettingsPanelProps) {
       ]);
       setSettings({
         permanentDelete: settingsData?.permanentDelete ?? false,
+        watchEnabled: settingsData?.watchEnabled ?? false,
         disabledCategories: settingsData?.disabledCategories ?? {},
       });
       setCategories(categoriesData);
@@ -70,6 +74,15 @@ export function SettingsPanel({ open, onClose }: SettingsPanelProps) {
     }
   };
 
+  const handleWatchChange = async (enabled: boolean) => {
+    try {
+      await SetWatchEnabled(enabled);
+      setSettings((prev) => ({ ...prev, watchEnabled: enabled }));
+    } catch (err) {
+      console.error("Failed to update live updates setting:", err);
+    }
+  };
+
   const handleCategoryToggle = async (categoryId: string, enabled: boolean) => {
     try {
       await SetCategoryEnabled(categoryId, enabled);
@@ -158,6 +171,40 @@ export function SettingsPanel({ open, onClose }: SettingsPanelProps) {
               </div>
             </div>
 
+            {/* Live Updates */}
+            <div className="space-y-3">
+              <h3 className="text-sm font-semibold text-[var(--color-text)] flex items-center gap-2">
+                <Eye size={16} />
+                Live Updates
+              </h3>
+              <div className="bg-[var(--color-bg-elevated)] rounded-[var(--radius-lg)] border border-[var(--color-border)] p-4">
+                <label className="flex items-center justify-between cursor-pointer">
+                  <div>
+                    <p className="text-sm font-medium text-[var(--color-text)]">
+                      Watch Scanned Folders
+                    </p>
+                    <p className="text-xs text-[var(--color-text-muted)]">
+                      Keep sizes current after a scan as files change (takes effect on the next scan)
+                    </p>
+                  </div>
+                  <button
+                    onClick={() => handleWatchChange(!settings.watchEnabled)}
+                    className={`relative w-11 h-6 rounded-full transition-colors ${
+                      settings.watchEnabled
+                        ? "bg-[var(--color-accent)]"
+                        : "bg-[var(--color-border)]"
+                    }`}
+                  >
+                    <span
+                      className={`absolute top-0.5 left-0.5 w-5 h-5 bg-white rounded-full transition-transform shadow-sm ${
+                        settings.watchEnabled ? "translate-x-5" : ""
+                      }`}
+                    />
+                  </button>
+                </label>
+              </div>
+            </div>
+
             {/* Category Toggles */}
             <div className="space-y-3">
               <h3 className="text-sm font-semibold text-[var(--color-text)] flex items-center gap-2">
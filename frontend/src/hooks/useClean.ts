@@ -94,7 +94,7 @@ export function useClean(): UseCleanReturn {
         currentItem: "",
       });
 
-      const cleanResult = await CleanCategories(categoryIds);
+      const cleanResult = await CleanCategories(categoryIds, false);
       setResult(cleanResult);
       setState("completed");
     } catch (err) {
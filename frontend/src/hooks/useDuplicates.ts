@@ -35,7 +35,7 @@ export function useDuplicates(): UseDuplicatesReturn {
   const deleteGroup = useCallback(async (group: scanner.DuplicateGroup, keepIndex: number) => {
     try {
       // Pass the group and keepIndex to the backend
-      await DeleteDuplicateGroup(group, keepIndex);
+      await DeleteDuplicateGroup(group, keepIndex, false);
 
       // Remove the group from results
       if (result) {
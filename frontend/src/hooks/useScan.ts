@@ -87,6 +87,17 @@ export function useScan(mode: ScanMode): UseScanReturn {
       }
     );
 
+    // Live updates of dev category sizes from the directory watcher
+    const unsubscribeCategoriesUpdated = EventsOn(
+      "categories:updated",
+      (data: scanner.ScanResult) => {
+        scanCache.dev = { state: "completed", result: data };
+        if (prevModeRef.current === "dev") {
+          setResult(data);
+        }
+      }
+    );
+
     // Scan cancelled
     const unsubscribeCancelled = EventsOn("scan:cancelled", () => {
       setState("cancelled");
@@ -98,6 +109,7 @@ export function useScan(mode: ScanMode): UseScanReturn {
       unsubscribeStarted();
       unsubscribeCompleted();
       unsubscribeCompletedNormal();
+      unsubscribeCategoriesUpdated();
       unsubscribeCancelled();
     };
   }, []);
@@ -1,10 +1,15 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {updater} from '../models';
+import {audit} from '../models';
 import {scanner} from '../models';
 import {cache} from '../models';
 import {settings} from '../models';
 import {main} from '../models';
+import {journal} from '../models';
+import {cleaner} from '../models';
+import {policy} from '../models';
+import {trash} from '../models';
 
 export function CancelClean():Promise<void>;
 
@@ -12,22 +17,38 @@ export function CancelScan():Promise<void>;
 
 export function CheckForUpdate():Promise<updater.UpdateInfo>;
 
-export function CleanCategories(arg1:Array<string>):Promise<scanner.CleanResult>;
+export function CheckPathSafety(arg1:Array<string>):Promise<Array<scanner.PathSafety>>;
+
+export function CleanCategories(arg1:Array<string>,arg2:boolean):Promise<scanner.CleanResult>;
+
+export function CleanStaleItems(arg1:Array<string>,arg2:scanner.AgeFilter,arg3:boolean):Promise<scanner.CleanResult>;
 
 export function ClearCache():Promise<void>;
 
 export function ClearTrendsHistory():Promise<void>;
 
-export function DeleteDuplicateGroup(arg1:scanner.DuplicateGroup,arg2:number):Promise<scanner.CleanResult>;
+export function DedupeDuplicateGroup(arg1:scanner.DuplicateGroup,arg2:number,arg3:string,arg4:boolean):Promise<scanner.CleanResult>;
+
+export function DeleteArtifacts(arg1:Array<string>,arg2:boolean):Promise<scanner.CleanResult>;
+
+export function DeleteDuplicateDecisions(arg1:Array<scanner.DuplicateDecision>,arg2:boolean):Promise<scanner.CleanResult>;
 
-export function DeleteNodeModules(arg1:Array<string>):Promise<scanner.CleanResult>;
+export function DeleteDuplicateGroup(arg1:scanner.DuplicateGroup,arg2:number,arg3:boolean):Promise<scanner.CleanResult>;
+
+export function DeleteNodeModules(arg1:Array<string>,arg2:boolean):Promise<scanner.CleanResult>;
 
 export function DeletePath(arg1:string,arg2:boolean):Promise<scanner.CleanResult>;
 
-export function DeletePaths(arg1:Array<string>,arg2:boolean):Promise<scanner.CleanResult>;
+export function DeletePaths(arg1:Array<string>,arg2:boolean,arg3:boolean):Promise<scanner.CleanResult>;
 
 export function DownloadUpdate(arg1:string):Promise<string>;
 
+export function EmptyTrash():Promise<trash.PurgeResult>;
+
+export function ExportAuditLog(arg1:audit.Filter,arg2:string):Promise<string>;
+
+export function FindDuplicateDirsInPath(arg1:string,arg2:number):Promise<scanner.DuplicatesResult>;
+
 export function FindDuplicates():Promise<scanner.DuplicatesResult>;
 
 export function FindDuplicatesInPath(arg1:string,arg2:number):Promise<scanner.DuplicatesResult>;
@@ -48,42 +69,88 @@ export function GetDirectoryChildren(arg1:string):Promise<Array<scanner.FileNode
 
 export function GetDiskTrends():Promise<scanner.TrendsResult>;
 
+export function GetExclusions():Promise<settings.Exclusions>;
+
 export function GetGrowthAlerts(arg1:number):Promise<Array<scanner.DiskUsageTrend>>;
 
 export function GetHomeDir():Promise<string>;
 
 export function GetPermanentDelete():Promise<boolean>;
 
+export function GetPolicies():Promise<Array<settings.Policy>>;
+
+export function GetProjectSearch():Promise<settings.ProjectSearch>;
+
 export function GetSettings():Promise<settings.Settings>;
 
+export function GetStaleCategoryItems(arg1:string,arg2:scanner.AgeFilter):Promise<Array<scanner.FileNode>>;
+
 export function GetVersion():Promise<main.VersionInfo>;
 
+export function GetWatchEnabled():Promise<boolean>;
+
 export function InstallUpdate(arg1:string):Promise<void>;
 
 export function IsCategoryEnabled(arg1:string):Promise<boolean>;
 
+export function ListOperations():Promise<Array<journal.Operation>>;
+
+export function ListTrash():Promise<Array<trash.Item>>;
+
 export function LoadCachedDevScan():Promise<cache.CachedDevScan>;
 
 export function LoadCachedNormalScan():Promise<cache.CachedNormalScan>;
 
 export function OpenReleasePage(arg1:string):Promise<void>;
 
+export function PlanDuplicateDeletion(arg1:Array<scanner.DuplicateGroup>,arg2:scanner.KeepRules):Promise<Array<scanner.DuplicateDecision>>;
+
+export function PurgeTrash(arg1:number):Promise<trash.PurgeResult>;
+
+export function QueryAuditLog(arg1:audit.Filter):Promise<Array<audit.Record>>;
+
 export function QuickScanDev():Promise<scanner.ScanResult>;
 
 export function RecordDiskSnapshot(arg1:scanner.ScanResult):Promise<void>;
 
+export function RestoreOperation(arg1:string,arg2:string):Promise<cleaner.RestoreResult>;
+
+export function RestorePaths(arg1:Array<string>,arg2:string):Promise<cleaner.RestoreResult>;
+
+export function RestoreTrashItem(arg1:string):Promise<void>;
+
+export function RunPolicies(arg1:boolean):Promise<Array<policy.Run>>;
+
 export function SaveSettings(arg1:settings.Settings):Promise<void>;
 
+export function ScanArtifacts():Promise<scanner.ArtifactsResult>;
+
+export function ScanArtifactsIn(arg1:settings.ProjectSearch):Promise<scanner.ArtifactsResult>;
+
 export function ScanCategory(arg1:string):Promise<scanner.Category>;
 
 export function ScanDev():Promise<scanner.ScanResult>;
 
 export function ScanNodeModules():Promise<scanner.NodeModulesResult>;
 
+export function ScanNodeModulesIn(arg1:settings.ProjectSearch):Promise<scanner.NodeModulesResult>;
+
 export function ScanNormal():Promise<scanner.FullScanResult>;
 
 export function ScanNormalPath(arg1:string):Promise<scanner.FullScanResult>;
 
 export function SetCategoryEnabled(arg1:string,arg2:boolean):Promise<void>;
 
+export function SetExclusions(arg1:settings.Exclusions):Promise<void>;
+
 export function SetPermanentDelete(arg1:boolean):Promise<void>;
+
+export function SetPolicies(arg1:Array<settings.Policy>):Promise<void>;
+
+export function SetProjectSearch(arg1:settings.ProjectSearch):Promise<void>;
+
+export function SetWatchEnabled(arg1:boolean):Promise<void>;
+
+export function ShredPaths(arg1:Array<string>,arg2:boolean):Promise<scanner.CleanResult>;
+
+export function ValidateCustomCategories():Promise<void>;
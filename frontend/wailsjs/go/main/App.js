@@ -14,8 +14,16 @@ export function CheckForUpdate() {
   return window['go']['main']['App']['CheckForUpdate']();
 }
 
-export function CleanCategories(arg1) {
-  return window['go']['main']['App']['CleanCategories'](arg1);
+export function CheckPathSafety(arg1) {
+  return window['go']['main']['App']['CheckPathSafety'](arg1);
+}
+
+export function CleanCategories(arg1, arg2) {
+  return window['go']['main']['App']['CleanCategories'](arg1, arg2);
+}
+
+export function CleanStaleItems(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CleanStaleItems'](arg1, arg2, arg3);
 }
 
 export function ClearCache() {
@@ -26,26 +34,50 @@ export function ClearTrendsHistory() {
   return window['go']['main']['App']['ClearTrendsHistory']();
 }
 
-export function DeleteDuplicateGroup(arg1, arg2) {
-  return window['go']['main']['App']['DeleteDuplicateGroup'](arg1, arg2);
+export function DedupeDuplicateGroup(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['DedupeDuplicateGroup'](arg1, arg2, arg3, arg4);
+}
+
+export function DeleteArtifacts(arg1, arg2) {
+  return window['go']['main']['App']['DeleteArtifacts'](arg1, arg2);
+}
+
+export function DeleteDuplicateDecisions(arg1, arg2) {
+  return window['go']['main']['App']['DeleteDuplicateDecisions'](arg1, arg2);
 }
 
-export function DeleteNodeModules(arg1) {
-  return window['go']['main']['App']['DeleteNodeModules'](arg1);
+export function DeleteDuplicateGroup(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteDuplicateGroup'](arg1, arg2, arg3);
+}
+
+export function DeleteNodeModules(arg1, arg2) {
+  return window['go']['main']['App']['DeleteNodeModules'](arg1, arg2);
 }
 
 export function DeletePath(arg1, arg2) {
   return window['go']['main']['App']['DeletePath'](arg1, arg2);
 }
 
-export function DeletePaths(arg1, arg2) {
-  return window['go']['main']['App']['DeletePaths'](arg1, arg2);
+export function DeletePaths(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeletePaths'](arg1, arg2, arg3);
 }
 
 export function DownloadUpdate(arg1) {
   return window['go']['main']['App']['DownloadUpdate'](arg1);
 }
 
+export function EmptyTrash() {
+  return window['go']['main']['App']['EmptyTrash']();
+}
+
+export function ExportAuditLog(arg1, arg2) {
+  return window['go']['main']['App']['ExportAuditLog'](arg1, arg2);
+}
+
+export function FindDuplicateDirsInPath(arg1, arg2) {
+  return window['go']['main']['App']['FindDuplicateDirsInPath'](arg1, arg2);
+}
+
 export function FindDuplicates() {
   return window['go']['main']['App']['FindDuplicates']();
 }
@@ -86,6 +118,10 @@ export function GetDiskTrends() {
   return window['go']['main']['App']['GetDiskTrends']();
 }
 
+export function GetExclusions() {
+  return window['go']['main']['App']['GetExclusions']();
+}
+
 export function GetGrowthAlerts(arg1) {
   return window['go']['main']['App']['GetGrowthAlerts'](arg1);
 }
@@ -98,14 +134,30 @@ export function GetPermanentDelete() {
   return window['go']['main']['App']['GetPermanentDelete']();
 }
 
+export function GetPolicies() {
+  return window['go']['main']['App']['GetPolicies']();
+}
+
+export function GetProjectSearch() {
+  return window['go']['main']['App']['GetProjectSearch']();
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetStaleCategoryItems(arg1, arg2) {
+  return window['go']['main']['App']['GetStaleCategoryItems'](arg1, arg2);
+}
+
 export function GetVersion() {
   return window['go']['main']['App']['GetVersion']();
 }
 
+export function GetWatchEnabled() {
+  return window['go']['main']['App']['GetWatchEnabled']();
+}
+
 export function InstallUpdate(arg1) {
   return window['go']['main']['App']['InstallUpdate'](arg1);
 }
@@ -114,6 +166,14 @@ export function IsCategoryEnabled(arg1) {
   return window['go']['main']['App']['IsCategoryEnabled'](arg1);
 }
 
+export function ListOperations() {
+  return window['go']['main']['App']['ListOperations']();
+}
+
+export function ListTrash() {
+  return window['go']['main']['App']['ListTrash']();
+}
+
 export function LoadCachedDevScan() {
   return window['go']['main']['App']['LoadCachedDevScan']();
 }
@@ -126,6 +186,18 @@ export function OpenReleasePage(arg1) {
   return window['go']['main']['App']['OpenReleasePage'](arg1);
 }
 
+export function PlanDuplicateDeletion(arg1, arg2) {
+  return window['go']['main']['App']['PlanDuplicateDeletion'](arg1, arg2);
+}
+
+export function PurgeTrash(arg1) {
+  return window['go']['main']['App']['PurgeTrash'](arg1);
+}
+
+export function QueryAuditLog(arg1) {
+  return window['go']['main']['App']['QueryAuditLog'](arg1);
+}
+
 export function QuickScanDev() {
   return window['go']['main']['App']['QuickScanDev']();
 }
@@ -134,10 +206,34 @@ export function RecordDiskSnapshot(arg1) {
   return window['go']['main']['App']['RecordDiskSnapshot'](arg1);
 }
 
+export function RestoreOperation(arg1, arg2) {
+  return window['go']['main']['App']['RestoreOperation'](arg1, arg2);
+}
+
+export function RestorePaths(arg1, arg2) {
+  return window['go']['main']['App']['RestorePaths'](arg1, arg2);
+}
+
+export function RestoreTrashItem(arg1) {
+  return window['go']['main']['App']['RestoreTrashItem'](arg1);
+}
+
+export function RunPolicies(arg1) {
+  return window['go']['main']['App']['RunPolicies'](arg1);
+}
+
 export function SaveSettings(arg1) {
   return window['go']['main']['App']['SaveSettings'](arg1);
 }
 
+export function ScanArtifacts() {
+  return window['go']['main']['App']['ScanArtifacts']();
+}
+
+export function ScanArtifactsIn(arg1) {
+  return window['go']['main']['App']['ScanArtifactsIn'](arg1);
+}
+
 export function ScanCategory(arg1) {
   return window['go']['main']['App']['ScanCategory'](arg1);
 }
@@ -150,6 +246,10 @@ export function ScanNodeModules() {
   return window['go']['main']['App']['ScanNodeModules']();
 }
 
+export function ScanNodeModulesIn(arg1) {
+  return window['go']['main']['App']['ScanNodeModulesIn'](arg1);
+}
+
 export function ScanNormal() {
   return window['go']['main']['App']['ScanNormal']();
 }
@@ -162,6 +262,30 @@ export function SetCategoryEnabled(arg1, arg2) {
   return window['go']['main']['App']['SetCategoryEnabled'](arg1, arg2);
 }
 
+export function SetExclusions(arg1) {
+  return window['go']['main']['App']['SetExclusions'](arg1);
+}
+
 export function SetPermanentDelete(arg1) {
   return window['go']['main']['App']['SetPermanentDelete'](arg1);
 }
+
+export function SetPolicies(arg1) {
+  return window['go']['main']['App']['SetPolicies'](arg1);
+}
+
+export function SetProjectSearch(arg1) {
+  return window['go']['main']['App']['SetProjectSearch'](arg1);
+}
+
+export function SetWatchEnabled(arg1) {
+  return window['go']['main']['App']['SetWatchEnabled'](arg1);
+}
+
+export function ShredPaths(arg1, arg2) {
+  return window['go']['main']['App']['ShredPaths'](arg1, arg2);
+}
+
+export function ValidateCustomCategories() {
+  return window['go']['main']['App']['ValidateCustomCategories']();
+}
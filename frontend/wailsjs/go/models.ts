@@ -1,3 +1,99 @@
+export namespace audit {
+	
+	export class Filter {
+	    // Go type: time
+	    since: any;
+	    // Go type: time
+	    until: any;
+	    pathPrefix: string;
+	    method: string;
+	    source: string;
+	    outcome: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Filter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.since = this.convertValues(source["since"], null);
+	        this.until = this.convertValues(source["until"], null);
+	        this.pathPrefix = source["pathPrefix"];
+	        this.method = source["method"];
+	        this.source = source["source"];
+	        this.outcome = source["outcome"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Record {
+	    // Go type: time
+	    time: any;
+	    user: string;
+	    path: string;
+	    size: number;
+	    method: string;
+	    source: string;
+	    outcome: string;
+	    error?: string;
+	    operationId?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Record(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.user = source["user"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.method = source["method"];
+	        this.source = source["source"];
+	        this.outcome = source["outcome"];
+	        this.error = source["error"];
+	        this.operationId = source["operationId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace cache {
 	
 	export class CacheInfo {
@@ -45,16 +141,363 @@ export namespace cache {
 	    version: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new CachedDevScan(source);
+	        return new CachedDevScan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.result = this.convertValues(source["result"], scanner.ScanResult);
+	        this.timestamp = this.convertValues(source["timestamp"], null);
+	        this.version = source["version"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CachedNormalScan {
+	    result: scanner.FullScanResult;
+	    // Go type: time
+	    timestamp: any;
+	    rootPath: string;
+	    version: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CachedNormalScan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.result = this.convertValues(source["result"], scanner.FullScanResult);
+	        this.timestamp = this.convertValues(source["timestamp"], null);
+	        this.rootPath = source["rootPath"];
+	        this.version = source["version"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace cleaner {
+	
+	export class RestoreResult {
+	    restoredPaths: string[];
+	    conflicts: string[];
+	    detailedErrors: scanner.CleanError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.restoredPaths = source["restoredPaths"];
+	        this.conflicts = source["conflicts"];
+	        this.detailedErrors = this.convertValues(source["detailedErrors"], scanner.CleanError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace journal {
+	
+	export class Entry {
+	    path: string;
+	    trashPath?: string;
+	    infoPath?: string;
+	    size: number;
+	    permanent: boolean;
+	    // Go type: time
+	    restoredAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.trashPath = source["trashPath"];
+	        this.infoPath = source["infoPath"];
+	        this.size = source["size"];
+	        this.permanent = source["permanent"];
+	        this.restoredAt = this.convertValues(source["restoredAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Operation {
+	    id: string;
+	    source: string;
+	    // Go type: time
+	    timestamp: any;
+	    entries: Entry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Operation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source = source["source"];
+	        this.timestamp = this.convertValues(source["timestamp"], null);
+	        this.entries = this.convertValues(source["entries"], Entry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace main {
+	
+	export class VersionInfo {
+	    version: string;
+	    buildTime: string;
+	    gitCommit: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new VersionInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.buildTime = source["buildTime"];
+	        this.gitCommit = source["gitCommit"];
+	    }
+	}
+
+}
+
+export namespace policy {
+	
+	export class Run {
+	    policyId: string;
+	    // Go type: time
+	    time: any;
+	    size: number;
+	    skipped?: string;
+	    result?: scanner.CleanResult;
+	
+	    static createFrom(source: any = {}) {
+	        return new Run(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.policyId = source["policyId"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.size = source["size"];
+	        this.skipped = source["skipped"];
+	        this.result = this.convertValues(source["result"], scanner.CleanResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace scanner {
+	
+	export class AgeFilter {
+	    olderThanDays?: number;
+	    // Go type: time
+	    before?: any;
+	    ignoreAccess?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AgeFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.olderThanDays = source["olderThanDays"];
+	        this.before = this.convertValues(source["before"], null);
+	        this.ignoreAccess = source["ignoreAccess"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProjectArtifact {
+	    path: string;
+	    linkPath?: string;
+	    name: string;
+	    ecosystem: string;
+	    projectRoot: string;
+	    projectName: string;
+	    marker?: string;
+	    size: number;
+	    // Go type: time
+	    modTime: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectArtifact(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.linkPath = source["linkPath"];
+	        this.name = source["name"];
+	        this.ecosystem = source["ecosystem"];
+	        this.projectRoot = source["projectRoot"];
+	        this.projectName = source["projectName"];
+	        this.marker = source["marker"];
+	        this.size = source["size"];
+	        this.modTime = this.convertValues(source["modTime"], null);
+	    }
+	
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EcosystemArtifacts {
+	    ecosystem: string;
+	    artifacts: ProjectArtifact[];
+	    totalSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EcosystemArtifacts(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.result = this.convertValues(source["result"], scanner.ScanResult);
-	        this.timestamp = this.convertValues(source["timestamp"], null);
-	        this.version = source["version"];
+	        this.ecosystem = source["ecosystem"];
+	        this.artifacts = this.convertValues(source["artifacts"], ProjectArtifact);
+	        this.totalSize = source["totalSize"];
 	    }
 	
+
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
 		    if (!a) {
 		        return a;
@@ -73,25 +516,25 @@ export namespace cache {
 		    return a;
 		}
 	}
-	export class CachedNormalScan {
-	    result: scanner.FullScanResult;
-	    // Go type: time
-	    timestamp: any;
-	    rootPath: string;
-	    version: string;
+	export class ArtifactsResult {
+	    ecosystems: EcosystemArtifacts[];
+	    totalSize: number;
+	    totalCount: number;
+	    scanDuration: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new CachedNormalScan(source);
+	        return new ArtifactsResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.result = this.convertValues(source["result"], scanner.FullScanResult);
-	        this.timestamp = this.convertValues(source["timestamp"], null);
-	        this.rootPath = source["rootPath"];
-	        this.version = source["version"];
+	        this.ecosystems = this.convertValues(source["ecosystems"], EcosystemArtifacts);
+	        this.totalSize = source["totalSize"];
+	        this.totalCount = source["totalCount"];
+	        this.scanDuration = source["scanDuration"];
 	    }
 	
+
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
 		    if (!a) {
 		        return a;
@@ -110,32 +553,6 @@ export namespace cache {
 		    return a;
 		}
 	}
-
-}
-
-export namespace main {
-	
-	export class VersionInfo {
-	    version: string;
-	    buildTime: string;
-	    gitCommit: string;
-	
-	    static createFrom(source: any = {}) {
-	        return new VersionInfo(source);
-	    }
-	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.version = source["version"];
-	        this.buildTime = source["buildTime"];
-	        this.gitCommit = source["gitCommit"];
-	    }
-	}
-
-}
-
-export namespace scanner {
-	
 	export class Category {
 	    id: string;
 	    name: string;
@@ -198,11 +615,43 @@ export namespace scanner {
 	        this.code = source["code"];
 	    }
 	}
+	export class ShredReport {
+	    path: string;
+	    size: number;
+	    storage: string;
+	    overwritten: boolean;
+	    verified: boolean;
+	    warning?: string;
+	    removed: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShredReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.storage = source["storage"];
+	        this.overwritten = source["overwritten"];
+	        this.verified = source["verified"];
+	        this.warning = source["warning"];
+	        this.removed = source["removed"];
+	        this.error = source["error"];
+	    }
+	}
 	export class CleanResult {
 	    freedBytes: number;
 	    deletedPaths: string[];
 	    errors?: string[];
 	    detailedErrors?: CleanError[];
+	    operationId?: string;
+	    dryRun?: boolean;
+	    plan?: PlannedDeletion[];
+	    safety?: PathSafety[];
+	    dedupedPaths?: string[];
+	    shredded?: ShredReport[];
 	
 	    static createFrom(source: any = {}) {
 	        return new CleanResult(source);
@@ -214,6 +663,12 @@ export namespace scanner {
 	        this.deletedPaths = source["deletedPaths"];
 	        this.errors = source["errors"];
 	        this.detailedErrors = this.convertValues(source["detailedErrors"], CleanError);
+	        this.operationId = source["operationId"];
+	        this.dryRun = source["dryRun"];
+	        this.plan = this.convertValues(source["plan"], PlannedDeletion);
+	        this.safety = this.convertValues(source["safety"], PathSafety);
+	        this.dedupedPaths = source["dedupedPaths"];
+	        this.shredded = this.convertValues(source["shredded"], ShredReport);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -269,6 +724,72 @@ export namespace scanner {
 		    return a;
 		}
 	}
+	export class PathSafety {
+	    path: string;
+	    level: string;
+	    repo?: string;
+	    ignored: boolean;
+	    trackedFiles: number;
+	    modifiedFiles: number;
+	    untrackedFiles: number;
+	    repoDirty: boolean;
+	    reasons?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PathSafety(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.level = source["level"];
+	        this.repo = source["repo"];
+	        this.ignored = source["ignored"];
+	        this.trackedFiles = source["trackedFiles"];
+	        this.modifiedFiles = source["modifiedFiles"];
+	        this.untrackedFiles = source["untrackedFiles"];
+	        this.repoDirty = source["repoDirty"];
+	        this.reasons = source["reasons"];
+	    }
+	}
+	export class PlannedDeletion {
+	    path: string;
+	    size: number;
+	    action: string;
+	    error?: CleanError;
+	    safety?: PathSafety;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlannedDeletion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.action = source["action"];
+	        this.error = this.convertValues(source["error"], CleanError);
+	        this.safety = this.convertValues(source["safety"], PathSafety);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class TrendDataPoint {
 	    // Go type: time
 	    timestamp: any;
@@ -379,11 +900,52 @@ export namespace scanner {
 		    return a;
 		}
 	}
+	export class DuplicateDecision {
+	    hash: string;
+	    directory?: boolean;
+	    keep: DuplicateFile[];
+	    delete: DuplicateFile[];
+	    reason: string;
+	    freedSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DuplicateDecision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.directory = source["directory"];
+	        this.keep = this.convertValues(source["keep"], DuplicateFile);
+	        this.delete = this.convertValues(source["delete"], DuplicateFile);
+	        this.reason = source["reason"];
+	        this.freedSize = source["freedSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class DuplicateGroup {
 	    hash: string;
 	    size: number;
 	    files: DuplicateFile[];
 	    wastedSize: number;
+	    directory?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new DuplicateGroup(source);
@@ -395,6 +957,7 @@ export namespace scanner {
 	        this.size = source["size"];
 	        this.files = this.convertValues(source["files"], DuplicateFile);
 	        this.wastedSize = source["wastedSize"];
+	        this.directory = source["directory"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -421,6 +984,7 @@ export namespace scanner {
 	    totalFiles: number;
 	    totalGroups: number;
 	    scanDuration: number;
+	    cachedHashes?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new DuplicatesResult(source);
@@ -433,6 +997,7 @@ export namespace scanner {
 	        this.totalFiles = source["totalFiles"];
 	        this.totalGroups = source["totalGroups"];
 	        this.scanDuration = source["scanDuration"];
+	        this.cachedHashes = source["cachedHashes"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -461,6 +1026,10 @@ export namespace scanner {
 	    // Go type: time
 	    modTime?: any;
 	    children?: FileNode[];
+	    // Go type: time
+	    lastAccess?: any;
+	    // Go type: time
+	    lastModified?: any;
 	
 	    static createFrom(source: any = {}) {
 	        return new FileNode(source);
@@ -474,6 +1043,8 @@ export namespace scanner {
 	        this.isDir = source["isDir"];
 	        this.modTime = this.convertValues(source["modTime"], null);
 	        this.children = this.convertValues(source["children"], FileNode);
+	        this.lastAccess = this.convertValues(source["lastAccess"], null);
+	        this.lastModified = this.convertValues(source["lastModified"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -530,6 +1101,22 @@ export namespace scanner {
 		    return a;
 		}
 	}
+	export class KeepRules {
+	    strategy: string;
+	    preferredRoots?: string[];
+	    protectedRoots?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new KeepRules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.strategy = source["strategy"];
+	        this.preferredRoots = source["preferredRoots"];
+	        this.protectedRoots = source["protectedRoots"];
+	    }
+	}
 	export class LargeFile {
 	    path: string;
 	    name: string;
@@ -764,9 +1351,69 @@ export namespace scanner {
 
 export namespace settings {
 	
+	export class Exclusions {
+	    patterns: string[];
+	    paths: string[];
+	    mounts: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Exclusions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.patterns = source["patterns"];
+	        this.paths = source["paths"];
+	        this.mounts = source["mounts"];
+	    }
+	}
+	export class Policy {
+	    id: string;
+	    enabled: boolean;
+	    target: string;
+	    minSize?: number;
+	    maxAgeDays?: number;
+	    intervalHours?: number;
+	    permanent: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Policy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.enabled = source["enabled"];
+	        this.target = source["target"];
+	        this.minSize = source["minSize"];
+	        this.maxAgeDays = source["maxAgeDays"];
+	        this.intervalHours = source["intervalHours"];
+	        this.permanent = source["permanent"];
+	    }
+	}
+	export class ProjectSearch {
+	    roots: string[];
+	    maxDepth: number;
+	    stopAtProject: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectSearch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.roots = source["roots"];
+	        this.maxDepth = source["maxDepth"];
+	        this.stopAtProject = source["stopAtProject"];
+	    }
+	}
 	export class Settings {
 	    permanentDelete: boolean;
 	    disabledCategories: Record<string, boolean>;
+	    exclusions: Exclusions;
+	    watchEnabled: boolean;
+	    policies: Policy[];
+	    projectSearch: ProjectSearch;
 	
 	    static createFrom(source: any = {}) {
 	        return new Settings(source);
@@ -776,7 +1423,113 @@ export namespace settings {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.permanentDelete = source["permanentDelete"];
 	        this.disabledCategories = source["disabledCategories"];
+	        this.exclusions = this.convertValues(source["exclusions"], Exclusions);
+	        this.watchEnabled = source["watchEnabled"];
+	        this.policies = this.convertValues(source["policies"], Policy);
+	        this.projectSearch = this.convertValues(source["projectSearch"], ProjectSearch);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace trash {
+	
+	export class Item {
+	    id: string;
+	    name: string;
+	    originalPath: string;
+	    // Go type: time
+	    deletedAt: any;
+	    size: number;
+	    isDir: boolean;
+	    trashDir: string;
+	    trashPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Item(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.originalPath = source["originalPath"];
+	        this.deletedAt = this.convertValues(source["deletedAt"], null);
+	        this.size = source["size"];
+	        this.isDir = source["isDir"];
+	        this.trashDir = source["trashDir"];
+	        this.trashPath = source["trashPath"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PurgeResult {
+	    removed: Item[];
+	    freedBytes: number;
+	    errors?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PurgeResult(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.removed = this.convertValues(source["removed"], Item);
+	        this.freedBytes = source["freedBytes"];
+	        this.errors = source["errors"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -0,0 +1,254 @@
+// Package audit keeps an append-only log of every path Disk Peek deleted
+// or tried to delete, for review and export
+package audit
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"disk-peek/internal/xdg"
+)
+
+// Methods of deletion
+const (
+	MethodTrash     = "trash"
+	MethodPermanent = "permanent"
+	MethodShred     = "shred"
+	// A duplicate replaced by a link to the kept copy loses its own data
+	MethodReflink  = "reflink"
+	MethodHardlink = "hardlink"
+)
+
+// Outcomes of a deletion
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Sources of deletions that don't go through the journal, see
+// journal.Source for the others
+const (
+	SourcePurgeTrash           = "PurgeTrash"
+	SourceEmptyTrash           = "EmptyTrash"
+	SourceDedupeDuplicateGroup = "DedupeDuplicateGroup"
+)
+
+// Record is one deletion of one path
+type Record struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user"`
+	Path string    `json:"path"`
+	Size int64     `json:"size"`
+	// Method is one of the Method constants
+	Method string `json:"method"`
+	// Source names the API that initiated the deletion, see journal.Source
+	Source string `json:"source"`
+	// Outcome is OutcomeSuccess or OutcomeFailure, with Error saying why
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+	// OperationID links the record to its journal operation, if any
+	OperationID string `json:"operationId,omitempty"`
+}
+
+// Deletion returns the record of deleting path, a failure when err is set
+func Deletion(path string, size int64, method, source string, err error) Record {
+	record := Record{
+		Path:    path,
+		Size:    size,
+		Method:  method,
+		Source:  source,
+		Outcome: OutcomeSuccess,
+	}
+	if err != nil {
+		record.Outcome = OutcomeFailure
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// Filter selects records; zero fields match everything
+type Filter struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// PathPrefix matches the path and everything below it
+	PathPrefix string `json:"pathPrefix"`
+	Method     string `json:"method"`
+	Source     string `json:"source"`
+	Outcome    string `json:"outcome"`
+	// Limit keeps only the most recent records
+	Limit int `json:"limit"`
+}
+
+var mu sync.Mutex
+
+// getLogPath returns the path to the audit log ($XDG_DATA_HOME/disk-peek)
+func getLogPath() (string, error) {
+	return xdg.DataFile("audit.jsonl")
+}
+
+// Append adds records to the end of the log, one JSON line each. The file
+// is only ever opened for appending; Time and User are filled in when
+// missing.
+func Append(records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	path, err := getLogPath()
+	if err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	now := time.Now()
+	for _, record := range records {
+		if record.Time.IsZero() {
+			record.Time = now
+		}
+		if record.User == "" {
+			record.User = currentUser()
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(buf.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Query returns the records filter matches, oldest first. Lines that
+// can't be parsed, such as one cut short by a crash, are skipped.
+func Query(filter Filter) ([]Record, error) {
+	path, err := getLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []Record{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+	return records, nil
+}
+
+// matches reports whether filter selects record
+func (filter Filter) matches(record Record) bool {
+	switch {
+	case !filter.Since.IsZero() && record.Time.Before(filter.Since):
+		return false
+	case !filter.Until.IsZero() && !record.Time.Before(filter.Until):
+		return false
+	case filter.Method != "" && record.Method != filter.Method:
+		return false
+	case filter.Source != "" && record.Source != filter.Source:
+		return false
+	case filter.Outcome != "" && record.Outcome != filter.Outcome:
+		return false
+	case filter.PathPrefix != "" && !under(record.Path, filter.PathPrefix):
+		return false
+	}
+	return true
+}
+
+// under reports whether path is dir or inside it
+func under(path, dir string) bool {
+	dir = filepath.Clean(dir)
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// csvHeader names the columns written by WriteCSV
+var csvHeader = []string{"time", "user", "path", "size", "method", "source", "outcome", "error", "operation_id"}
+
+// WriteCSV writes records as CSV with a header row
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := cw.Write([]string{
+			record.Time.Format(time.RFC3339),
+			record.User,
+			record.Path,
+			strconv.FormatInt(record.Size, 10),
+			record.Method,
+			record.Source,
+			record.Outcome,
+			record.Error,
+			record.OperationID,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var (
+	userOnce sync.Once
+	userName string
+)
+
+// currentUser returns the name of the user running Disk Peek
+func currentUser() string {
+	userOnce.Do(func() {
+		if u, err := user.Current(); err == nil {
+			userName = u.Username
+		} else {
+			userName = os.Getenv("USER")
+		}
+	})
+	return userName
+}
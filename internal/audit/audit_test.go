@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAppendAndQuery(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := Append(
+		Record{Time: day, Path: "/home/u/.npm/_cacache", Size: 100, Method: MethodTrash, Source: "CleanCategories", Outcome: OutcomeSuccess},
+		Record{Time: day.Add(24 * time.Hour), Path: "/home/u/dump.sql", Size: 50, Method: MethodShred, Source: "ShredPaths", Outcome: OutcomeSuccess},
+	)
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	failed := Deletion("/home/u/.npm/locked", 10, MethodPermanent, "DeletePaths", errors.New("permission denied"))
+	if err := Append(failed); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	all, err := Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(all) != 3 || all[0].Path != "/home/u/.npm/_cacache" {
+		t.Fatalf("Query = %+v, want 3 records oldest first", all)
+	}
+	if all[2].User == "" || all[2].Time.IsZero() || all[2].Outcome != OutcomeFailure || all[2].Error != "permission denied" {
+		t.Errorf("failed record = %+v", all[2])
+	}
+
+	for name, test := range map[string]struct {
+		filter Filter
+		want   int
+	}{
+		"since":   {Filter{Since: day.Add(time.Hour)}, 2},
+		"until":   {Filter{Until: day.Add(time.Hour)}, 1},
+		"path":    {Filter{PathPrefix: "/home/u/.npm"}, 2},
+		"method":  {Filter{Method: MethodShred}, 1},
+		"source":  {Filter{Source: "CleanCategories"}, 1},
+		"outcome": {Filter{Outcome: OutcomeFailure}, 1},
+		"limit":   {Filter{Limit: 2}, 2},
+	} {
+		records, err := Query(test.filter)
+		if err != nil || len(records) != test.want {
+			t.Errorf("%s: Query = %d records, %v; want %d", name, len(records), err, test.want)
+		}
+	}
+}
+
+func TestQuerySkipsTornLines(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := Append(Record{Path: "/a", Outcome: OutcomeSuccess}); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := getLogPath()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"path":"/b","out`)
+	f.Close()
+
+	records, err := Query(Filter{})
+	if err != nil || len(records) != 1 {
+		t.Errorf("Query = %+v, %v; want the one complete record", records, err)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	records := []Record{{
+		Time:    time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		User:    "u",
+		Path:    "/home/u/a, b.txt",
+		Size:    42,
+		Method:  MethodTrash,
+		Source:  "DeletePaths",
+		Outcome: OutcomeSuccess,
+	}}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, records); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(csvHeader) {
+		t.Fatalf("rows = %q, want a header and one record", rows)
+	}
+	if rows[1][0] != "2026-03-01T12:00:00Z" || rows[1][2] != "/home/u/a, b.txt" || rows[1][3] != "42" {
+		t.Errorf("record row = %q", rows[1])
+	}
+}
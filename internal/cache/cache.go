@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"disk-peek/internal/scanner"
+	"disk-peek/internal/xdg"
 )
 
 // CachedDevScan represents a cached dev scan result with metadata
@@ -25,22 +26,16 @@ type CachedNormalScan struct {
 }
 
 const (
-	cacheVersion    = "1.0"
-	devCacheFile    = "dev_scan_cache.json"
-	normalCacheFile = "normal_scan_cache.json"
+	cacheVersion     = "1.0"
+	devCacheFile     = "dev_scan_cache.json"
+	normalCacheFile  = "normal_scan_cache.json"
+	fingerprintsFile = "fingerprints.gob"
+	hashesFile       = "hashes.gob"
 )
 
-// getCacheDir returns the cache directory path
+// getCacheDir returns the cache directory path ($XDG_CACHE_HOME/disk-peek)
 func getCacheDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	cacheDir := filepath.Join(home, ".config", "disk-peek", "cache")
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return "", err
-	}
-	return cacheDir, nil
+	return xdg.CacheDir()
 }
 
 // SaveDevScan saves a dev scan result to cache
@@ -138,6 +133,51 @@ func LoadNormalScan() *CachedNormalScan {
 	return &cached
 }
 
+// LoadFingerprintIndex loads the directory fingerprints used for
+// incremental normal scans
+// Returns an empty index if none exists or it can't be read
+func LoadFingerprintIndex() *scanner.FingerprintIndex {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return scanner.NewFingerprintIndex()
+	}
+
+	idx, _ := scanner.LoadFingerprintIndex(filepath.Join(cacheDir, fingerprintsFile))
+	return idx
+}
+
+// SaveFingerprintIndex saves the directory fingerprints for the next scan
+func SaveFingerprintIndex(idx *scanner.FingerprintIndex) error {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return err
+	}
+
+	return idx.Save(filepath.Join(cacheDir, fingerprintsFile))
+}
+
+// LoadHashIndex loads the file hashes remembered from earlier duplicate scans
+// Returns an empty index if none exists or it can't be read
+func LoadHashIndex() *scanner.HashIndex {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return scanner.NewHashIndex()
+	}
+
+	idx, _ := scanner.LoadHashIndex(filepath.Join(cacheDir, hashesFile))
+	return idx
+}
+
+// SaveHashIndex saves the file hashes for the next duplicate scan
+func SaveHashIndex(idx *scanner.HashIndex) error {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return err
+	}
+
+	return idx.Save(filepath.Join(cacheDir, hashesFile))
+}
+
 // ClearCache removes all cached scan results
 func ClearCache() error {
 	cacheDir, err := getCacheDir()
@@ -147,6 +187,8 @@ func ClearCache() error {
 
 	_ = os.Remove(filepath.Join(cacheDir, devCacheFile))
 	_ = os.Remove(filepath.Join(cacheDir, normalCacheFile))
+	_ = os.Remove(filepath.Join(cacheDir, fingerprintsFile))
+	_ = os.Remove(filepath.Join(cacheDir, hashesFile))
 	return nil
 }
 
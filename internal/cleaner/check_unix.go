@@ -0,0 +1,94 @@
+//go:build !windows
+
+package cleaner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Modes for access(2)
+const (
+	accessRead   = 0x4
+	accessWrite  = 0x2
+	accessSearch = 0x1
+)
+
+// checkDeletable reports permission problems that would stop path from
+// being deleted. It can't predict every failure (read-only mounts, files
+// in use), only what the mode bits and ownership already rule out.
+func checkDeletable(path string, info os.FileInfo, permanent bool) error {
+	// Unlinking or renaming an entry needs write access to its directory
+	parent := filepath.Dir(path)
+	if err := checkUnlink(parent, info); err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+	if !permanent {
+		// Moving a directory to another parent rewrites its ".." entry
+		if owned(info) {
+			return nil
+		}
+		return access(path, accessWrite)
+	}
+
+	// Removing a tree needs every directory in it to be listable and
+	// writable, which the user's own directories are made by makeWritable
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if dirInfo, err := d.Info(); err == nil && owned(dirInfo) {
+			return nil
+		}
+		return access(p, accessRead|accessWrite|accessSearch)
+	})
+}
+
+// owned reports whether info belongs to the user, who may change its mode
+func owned(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Uid == uint32(os.Geteuid())
+}
+
+// checkUnlink checks that the entry described by info can be removed from dir
+func checkUnlink(dir string, info os.FileInfo) error {
+	if err := access(dir, accessWrite|accessSearch); err != nil {
+		return err
+	}
+
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	// In sticky directories such as /tmp only owners may remove entries
+	if dirInfo.Mode()&os.ModeSticky == 0 {
+		return nil
+	}
+	uid := uint32(os.Geteuid())
+	if uid == 0 {
+		return nil
+	}
+	dirStat, ok1 := dirInfo.Sys().(*syscall.Stat_t)
+	stat, ok2 := info.Sys().(*syscall.Stat_t)
+	if ok1 && ok2 && dirStat.Uid != uid && stat.Uid != uid {
+		return &os.PathError{Op: "remove", Path: dir, Err: syscall.EPERM}
+	}
+	return nil
+}
+
+// access wraps access(2) in a PathError so ErrorCode recognizes it
+func access(path string, mode uint32) error {
+	if err := syscall.Access(path, mode); err != nil {
+		return &os.PathError{Op: "access", Path: path, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+//go:build !windows
+
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// otherMountDir returns a new directory on /dev/shm, a filesystem other
+// than the home trash's, with no trash directory at its top yet
+func otherMountDir(t *testing.T) string {
+	t.Helper()
+	// /dev/shm is usually a tmpfs, away from the home trash
+	shm, err := os.Stat("/dev/shm")
+	home, _ := os.Stat(os.Getenv("XDG_DATA_HOME"))
+	if err != nil || shm.Sys().(*syscall.Stat_t).Dev == home.Sys().(*syscall.Stat_t).Dev {
+		t.Skip("no separate filesystem at /dev/shm")
+	}
+	for _, name := range []string{".Trash", ".Trash-" + strconv.Itoa(os.Getuid())} {
+		if _, err := os.Lstat(filepath.Join("/dev/shm", name)); err == nil {
+			t.Skipf("/dev/shm/%s already exists", name)
+		}
+	}
+
+	dir, err := os.MkdirTemp("/dev/shm", "disk-peek-")
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestPlanReportsMissingTrash(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := otherMountDir(t)
+
+	// A file where the per-user trash would go leaves the mount without one
+	blocker := filepath.Join("/dev/shm", ".Trash-"+strconv.Itoa(os.Getuid()))
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { os.Remove(blocker) })
+
+	file := filepath.Join(dir, "file.bin")
+	if err := os.WriteFile(file, make([]byte, 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := Plan([]string{file}, Options{})
+	if len(result.Plan) != 1 || result.Plan[0].Error == nil || result.Plan[0].Error.Code != "NO_TRASH" {
+		t.Fatalf("plan = %+v, want NO_TRASH", result.Plan)
+	}
+	if len(result.DeletedPaths) != 0 {
+		t.Errorf("deleted paths = %v, want none", result.DeletedPaths)
+	}
+
+	if result := Plan([]string{file}, Options{Permanent: true}); len(result.DetailedErrors) != 0 {
+		t.Errorf("permanent plan = %+v, want no trash needed", result.DetailedErrors)
+	}
+}
+
+func TestPlanCreatesNoTrash(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := otherMountDir(t)
+
+	file := filepath.Join(dir, "file.bin")
+	if err := os.WriteFile(file, make([]byte, 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := Plan([]string{file}, Options{})
+	if len(result.DetailedErrors) != 0 {
+		t.Fatalf("plan = %+v, want the file trashable", result.DetailedErrors)
+	}
+	trashDir := filepath.Join("/dev/shm", ".Trash-"+strconv.Itoa(os.Getuid()))
+	if _, err := os.Lstat(trashDir); !os.IsNotExist(err) {
+		os.RemoveAll(trashDir)
+		t.Errorf("Plan created %s", trashDir)
+	}
+}
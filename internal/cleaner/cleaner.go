@@ -0,0 +1,561 @@
+package cleaner
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"disk-peek/internal/audit"
+	"disk-peek/internal/gitcheck"
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+	"disk-peek/internal/settings"
+	"disk-peek/internal/trash"
+)
+
+// Options controls how DeletePaths removes paths
+type Options struct {
+	// Permanent uses os.RemoveAll instead of moving paths to the system Trash
+	Permanent bool
+	// Shred overwrites files before removing them permanently, see
+	// scanner.Shred; it implies Permanent
+	Shred bool
+	// Source names the API recorded in the journal, see the journal.Source constants
+	Source string
+	// Progress (if any) is invoked before each path is processed
+	Progress scanner.CleanProgressCallback
+	// Ctx (if set) stops the deletion or plan before the next path once it
+	// is done; the paths deleted until then are still journaled
+	Ctx context.Context
+	// DryRun plans the deletion without touching the filesystem
+	DryRun bool
+	// CheckGit inspects the git repository around each path first and
+	// reports the verdicts in the result
+	CheckGit bool
+	// SkipTracked refuses paths holding files tracked by git (SafetyDanger);
+	// it implies CheckGit
+	SkipTracked bool
+}
+
+// permanent reports whether paths are removed rather than trashed
+func (options Options) permanent() bool {
+	return options.Permanent || options.Shred
+}
+
+// method names how paths are deleted in the audit log
+func (options Options) method() string {
+	switch {
+	case options.Shred:
+		return audit.MethodShred
+	case options.Permanent:
+		return audit.MethodPermanent
+	default:
+		return audit.MethodTrash
+	}
+}
+
+// ErrTracked is reported for paths refused because git tracks files in them
+var ErrTracked = errors.New("holds files tracked by git")
+
+// checkGit returns the git verdict on path and the error refusing it, if
+// the options ask for either
+func (options Options) checkGit(checker *gitcheck.Checker, path string) (*scanner.PathSafety, error) {
+	if !options.CheckGit && !options.SkipTracked {
+		return nil, nil
+	}
+	safety := checker.Check(path)
+	if options.SkipTracked && safety.Level == scanner.SafetyDanger {
+		return &safety, ErrTracked
+	}
+	return &safety, nil
+}
+
+// DeletePaths is the unified method for deleting files/directories
+// Every removed path is recorded in the journal under one operation, whose
+// ID is returned so trashed items can be restored. Every attempt, failed or
+// not, is also written to the audit log.
+func DeletePaths(paths []string, options Options) scanner.CleanResult {
+	if options.DryRun {
+		return Plan(paths, options)
+	}
+
+	result := scanner.CleanResult{
+		FreedBytes:     0,
+		DeletedPaths:   []string{},
+		Errors:         []string{},
+		DetailedErrors: []scanner.CleanError{},
+	}
+
+	op := journal.NewOperation(options.Source)
+	checker := gitcheck.NewChecker()
+
+	total := len(paths)
+	for i, path := range paths {
+		if scanner.IsCancelled(options.Ctx) {
+			break
+		}
+		if options.Progress != nil {
+			options.Progress(scanner.CleanProgress{
+				Current:     i + 1,
+				Total:       total,
+				CurrentPath: path,
+				BytesFreed:  result.FreedBytes,
+				CurrentItem: TruncatePath(path),
+			})
+		}
+
+		// Check if path exists
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue // Skip non-existent paths
+		}
+
+		safety, err := options.checkGit(checker, path)
+		if safety != nil {
+			result.Safety = append(result.Safety, *safety)
+		}
+
+		// Get size before deletion
+		walkResult := scanner.WalkDirectory(path)
+		size := walkResult.Size
+
+		var loc trash.Location
+		if err == nil {
+			switch {
+			case options.Shred:
+				var reports []scanner.ShredReport
+				reports, err = scanner.Shred(path)
+				result.Shredded = append(result.Shredded, reports...)
+			case options.Permanent:
+				makeWritable(path)
+				err = os.RemoveAll(path)
+			default:
+				makeWritable(path)
+				loc, err = trash.Move(path)
+			}
+		}
+
+		// The audit log is best effort, like the journal
+		method := options.method()
+		if loc.Removed {
+			method = audit.MethodPermanent
+		}
+		record := audit.Deletion(path, size, method, options.Source, err)
+		if err == nil {
+			record.OperationID = op.ID
+		}
+		_ = audit.Append(record)
+
+		if err != nil {
+			errorMsg := ErrorMessage(err, path)
+			result.Errors = append(result.Errors, errorMsg)
+			result.DetailedErrors = append(result.DetailedErrors, scanner.CleanError{
+				Path:    path,
+				Message: errorMsg,
+				Code:    ErrorCode(err),
+			})
+			continue
+		}
+
+		result.FreedBytes += size
+		result.DeletedPaths = append(result.DeletedPaths, path)
+		op.Add(journal.Entry{
+			Path:      path,
+			TrashPath: loc.TrashPath,
+			InfoPath:  loc.InfoPath,
+			Size:      size,
+			Permanent: options.permanent() || loc.Removed,
+		})
+	}
+
+	// A journal that can't be written doesn't undo the deletes
+	if len(op.Entries) > 0 && journal.Record(op) == nil {
+		result.OperationID = op.ID
+	}
+
+	return result
+}
+
+// Plan reports what DeletePaths would do with paths without deleting
+// anything. DeletedPaths and FreedBytes cover the paths expected to succeed;
+// permission problems and paths without a usable trash found up front are
+// reported as errors. When shredding,
+// Shredded tells for each file whether an overwrite would reach its data.
+func Plan(paths []string, options Options) scanner.CleanResult {
+	result := scanner.CleanResult{
+		FreedBytes:     0,
+		DeletedPaths:   []string{},
+		Errors:         []string{},
+		DetailedErrors: []scanner.CleanError{},
+		DryRun:         true,
+		Plan:           []scanner.PlannedDeletion{},
+	}
+
+	action := scanner.ActionTrash
+	if options.Shred {
+		action = scanner.ActionShred
+	} else if options.Permanent {
+		action = scanner.ActionRemove
+	}
+	checker := gitcheck.NewChecker()
+
+	total := len(paths)
+	for i, path := range paths {
+		if scanner.IsCancelled(options.Ctx) {
+			break
+		}
+		if options.Progress != nil {
+			options.Progress(scanner.CleanProgress{
+				Current:     i + 1,
+				Total:       total,
+				CurrentPath: path,
+				BytesFreed:  result.FreedBytes,
+				CurrentItem: TruncatePath(path),
+			})
+		}
+
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			continue // Skip non-existent paths, as DeletePaths does
+		}
+
+		planned := scanner.PlannedDeletion{
+			Path:   path,
+			Size:   scanner.WalkDirectory(path).Size,
+			Action: action,
+		}
+		if err == nil {
+			planned.Safety, err = options.checkGit(checker, path)
+		}
+		if err == nil {
+			err = checkDeletable(path, info, options.permanent())
+		}
+		if err == nil && !options.permanent() {
+			err = trash.Available(path)
+		}
+		if options.Shred {
+			result.Shredded = append(result.Shredded, scanner.PlanShred(path)...)
+		}
+
+		if err != nil {
+			errorMsg := ErrorMessage(err, path)
+			cleanErr := scanner.CleanError{
+				Path:    path,
+				Message: errorMsg,
+				Code:    ErrorCode(err),
+			}
+			planned.Error = &cleanErr
+			result.Errors = append(result.Errors, errorMsg)
+			result.DetailedErrors = append(result.DetailedErrors, cleanErr)
+		} else {
+			result.FreedBytes += planned.Size
+			result.DeletedPaths = append(result.DeletedPaths, path)
+		}
+		result.Plan = append(result.Plan, planned)
+	}
+
+	return result
+}
+
+// Journaled returns a deletion backend for the scanner's delete functions
+// that trashes paths, or removes them when permanent, and records each one
+// in op so trashed paths can be restored. Every attempt, failed or not, is
+// also written to the audit log.
+func Journaled(op *journal.Operation, permanent bool) scanner.Deleter {
+	return journaled{op: op, permanent: permanent}
+}
+
+type journaled struct {
+	op        *journal.Operation
+	permanent bool
+}
+
+func (j journaled) Delete(path string, size int64) error {
+	var loc trash.Location
+	var err error
+	makeWritable(path)
+	if j.permanent {
+		err = remove(path)
+	} else {
+		loc, err = trash.Move(path)
+	}
+
+	// The platform trash may have fallen back to deleting permanently
+	permanent := j.permanent || loc.Removed
+	method := audit.MethodTrash
+	if permanent {
+		method = audit.MethodPermanent
+	}
+	record := audit.Deletion(path, size, method, j.op.Source, err)
+	if err == nil {
+		record.OperationID = j.op.ID
+	}
+	_ = audit.Append(record)
+
+	if err != nil {
+		return err
+	}
+	j.op.Add(journal.Entry{
+		Path:      path,
+		TrashPath: loc.TrashPath,
+		InfoPath:  loc.InfoPath,
+		Size:      size,
+		Permanent: permanent,
+	})
+	return nil
+}
+
+// remove deletes a file with os.Remove, and a directory, as only directory
+// duplicate groups delete, with everything in it
+func remove(path string) error {
+	if info, err := os.Lstat(path); err == nil && info.IsDir() {
+		return os.RemoveAll(path)
+	}
+	return os.Remove(path)
+}
+
+// makeWritable gives the owner full access to the directories in the tree
+// at path that lack it, as go clean -modcache does, so read-only trees like
+// Go's module cache can be removed now or purged from the trash later.
+// Failures are left for the deletion to report.
+func makeWritable(path string) {
+	if info, err := os.Lstat(path); err != nil || !info.IsDir() {
+		return
+	}
+	_ = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		// Directories are changed before they are read, so a directory
+		// that wasn't listable is by the time WalkDir lists it
+		if info, err := d.Info(); err == nil && info.Mode().Perm()&0700 != 0700 {
+			_ = os.Chmod(p, info.Mode().Perm()|0700)
+		}
+		return nil
+	})
+}
+
+// Check reports the problems Plan would find with path up front
+func (j journaled) Check(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if err := checkDeletable(path, info, j.permanent); err != nil {
+		return err
+	}
+	if !j.permanent {
+		return trash.Available(path)
+	}
+	return nil
+}
+
+func (j journaled) Action() string {
+	if j.permanent {
+		return scanner.ActionRemove
+	}
+	return scanner.ActionTrash
+}
+
+func (j journaled) DryRun() bool { return false }
+
+// Dedupe replaces duplicates with links to the kept copies like
+// scanner.DedupeDuplicates, and writes every replaced or failed file to the
+// audit log under source. It stops between files once ctx is done.
+func Dedupe(ctx context.Context, groups []scanner.DuplicateGroup, keepIndex int, action string, dryRun bool, source string) scanner.CleanResult {
+	result := scanner.DedupeDuplicatesWithContext(ctx, groups, keepIndex, action, dryRun)
+	if dryRun {
+		return result
+	}
+
+	sizes := make(map[string]int64)
+	for _, group := range groups {
+		for _, file := range group.Files {
+			sizes[file.Path] = file.Size
+		}
+	}
+	var records []audit.Record
+	for _, path := range result.DedupedPaths {
+		records = append(records, audit.Deletion(path, sizes[path], action, source, nil))
+	}
+	for _, cleanErr := range result.DetailedErrors {
+		records = append(records, audit.Deletion(cleanErr.Path, sizes[cleanErr.Path], action, source, errors.New(cleanErr.Message)))
+	}
+	_ = audit.Append(records...)
+	return result
+}
+
+// DeleteNodeModules deletes node_modules directories with
+// scanner.DeleteNodeModules and Journaled, recording them in the journal
+// under the options' source. Permanent, DryRun, CheckGit, Progress and Ctx
+// apply as they do for DeletePaths; the git verdicts are taken before
+// anything is deleted.
+func DeleteNodeModules(paths []string, options Options) scanner.CleanResult {
+	op := journal.NewOperation(options.Source)
+	deleter := Journaled(op, options.Permanent)
+	if options.DryRun {
+		deleter = scanner.DryRunDeleter(deleter)
+	}
+
+	var safety []scanner.PathSafety
+	if options.CheckGit {
+		safety = gitcheck.NewChecker().CheckAll(paths)
+	}
+
+	var progress func(current, total int, path string, bytesFreed int64)
+	if options.Progress != nil {
+		progress = func(current, total int, path string, bytesFreed int64) {
+			options.Progress(scanner.CleanProgress{
+				Current:     current,
+				Total:       total,
+				CurrentPath: path,
+				BytesFreed:  bytesFreed,
+				CurrentItem: TruncatePath(path),
+			})
+		}
+	}
+	ctx := options.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result := scanner.DeleteNodeModulesWithContext(ctx, paths, deleter, progress)
+
+	if options.DryRun {
+		// Dry runs carry the verdicts on each planned path
+		verdicts := make(map[string]*scanner.PathSafety, len(safety))
+		for i := range safety {
+			verdicts[safety[i].Path] = &safety[i]
+		}
+		for i := range result.Plan {
+			result.Plan[i].Safety = verdicts[result.Plan[i].Path]
+		}
+		return result
+	}
+	result.Safety = safety
+
+	// A journal that can't be written doesn't undo the deletes
+	if len(op.Entries) > 0 && journal.Record(op) == nil {
+		result.OperationID = op.ID
+	}
+	return result
+}
+
+// CategoryPaths collects the unique paths of the given category IDs
+// Categories disabled in the user's settings are skipped
+func CategoryPaths(categories []scanner.Category, categoryIDs []string) []string {
+	var paths []string
+
+	for _, id := range categoryIDs {
+		// Skip disabled categories
+		if !settings.IsCategoryEnabled(id) {
+			continue
+		}
+		cat := scanner.GetCategoryByID(categories, id)
+		if cat == nil {
+			continue
+		}
+		// Collect all paths from this category and its children
+		collectPathsFromCategory(cat, &paths)
+	}
+
+	return uniquePaths(paths)
+}
+
+// StaleItems collects the category items filter matches, for cleaning only
+// what hasn't been used recently
+// Categories disabled in the user's settings are skipped
+func StaleItems(categoryIDs []string, filter scanner.AgeFilter) []scanner.FileNode {
+	devScanner := scanner.NewDevScanner(4)
+	seen := make(map[string]bool)
+	items := []scanner.FileNode{}
+
+	for _, id := range categoryIDs {
+		if !settings.IsCategoryEnabled(id) {
+			continue
+		}
+		stale, _ := devScanner.GetStaleCategoryItems(id, filter)
+		for _, item := range stale {
+			if !seen[item.Path] {
+				seen[item.Path] = true
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items
+}
+
+// collectPathsFromCategory recursively collects all resolved paths from a category
+func collectPathsFromCategory(cat *scanner.Category, paths *[]string) {
+	*paths = append(*paths, scanner.ResolvePaths(cat.Paths)...)
+	for i := range cat.Children {
+		collectPathsFromCategory(&cat.Children[i], paths)
+	}
+}
+
+// uniquePaths removes duplicate paths
+func uniquePaths(paths []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// ErrorCode returns a code for the error type
+func ErrorCode(err error) string {
+	if os.IsPermission(err) {
+		return "PERMISSION_DENIED"
+	}
+	if os.IsNotExist(err) {
+		return "NOT_FOUND"
+	}
+	if os.IsExist(err) {
+		return "ALREADY_EXISTS"
+	}
+	if errors.Is(err, ErrTracked) {
+		return "TRACKED_BY_GIT"
+	}
+	if errors.Is(err, trash.ErrCrossDevice) {
+		return "NO_TRASH"
+	}
+	return "UNKNOWN"
+}
+
+// ErrorMessage returns a user-friendly error message
+func ErrorMessage(err error, path string) string {
+	if os.IsPermission(err) {
+		return "Permission denied: " + TruncatePath(path) + ". Try running with elevated permissions."
+	}
+	if os.IsNotExist(err) {
+		return "File not found: " + TruncatePath(path)
+	}
+	if errors.Is(err, ErrTracked) {
+		return "Holds files tracked by git: " + TruncatePath(path)
+	}
+	if errors.Is(err, trash.ErrCrossDevice) {
+		return "No trash on the filesystem of " + TruncatePath(path) + ". Delete it permanently instead."
+	}
+	// Default to original error message
+	return err.Error()
+}
+
+// TruncatePath shortens a path for display by keeping the last 3 components
+func TruncatePath(path string) string {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) <= 3 {
+		return path
+	}
+	return ".../" + strings.Join(parts[len(parts)-3:], "/")
+}
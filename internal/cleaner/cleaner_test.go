@@ -0,0 +1,269 @@
+package cleaner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"disk-peek/internal/audit"
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+)
+
+func TestPlanTouchesNothing(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.bin")
+	tree := filepath.Join(dir, "tree")
+	if err := os.MkdirAll(filepath.Join(tree, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{file, filepath.Join(tree, "sub", "a.bin")} {
+		if err := os.WriteFile(path, make([]byte, 8192), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, permanent := range []bool{false, true} {
+		paths := []string{file, tree, filepath.Join(dir, "missing")}
+		result := DeletePaths(paths, Options{Permanent: permanent, DryRun: true})
+
+		if !result.DryRun || result.OperationID != "" {
+			t.Errorf("result = %+v, want an unjournaled dry run", result)
+		}
+		if len(result.Plan) != 2 || len(result.DeletedPaths) != 2 {
+			t.Fatalf("plan = %+v, want file and tree", result.Plan)
+		}
+
+		wantAction := scanner.ActionTrash
+		if permanent {
+			wantAction = scanner.ActionRemove
+		}
+		for _, planned := range result.Plan {
+			if planned.Action != wantAction || planned.Size <= 0 || planned.Error != nil {
+				t.Errorf("planned = %+v, want %s with a size", planned, wantAction)
+			}
+		}
+		if result.FreedBytes != result.Plan[0].Size+result.Plan[1].Size {
+			t.Errorf("freed = %d, want the sum of planned sizes", result.FreedBytes)
+		}
+	}
+
+	for _, path := range []string{file, tree} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("dry run removed %s", path)
+		}
+	}
+}
+
+func TestPlanReportsPermissionProblems(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root bypasses permission checks")
+	}
+
+	dir := t.TempDir()
+	locked := filepath.Join(dir, "locked")
+	if err := os.MkdirAll(filepath.Join(locked, "inner"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(locked, 0755)
+
+	result := Plan([]string{filepath.Join(locked, "inner")}, Options{})
+	if len(result.Plan) != 1 || result.Plan[0].Error == nil || result.Plan[0].Error.Code != "PERMISSION_DENIED" {
+		t.Fatalf("plan = %+v, want a permission problem", result.Plan)
+	}
+	if len(result.DeletedPaths) != 0 || result.FreedBytes != 0 {
+		t.Errorf("result = %+v, want nothing counted as deleted", result)
+	}
+
+	// The user's own read-only tree is made writable before it is removed
+	result = Plan([]string{locked}, Options{Permanent: true})
+	if len(result.DetailedErrors) != 0 {
+		t.Errorf("permanent plan = %+v, want no permission problem", result)
+	}
+}
+
+func TestDeleteReadOnlyTrees(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	// Go extracts modules read-only
+	modCache := t.TempDir()
+	module := func(name string) string {
+		t.Helper()
+		dir := filepath.Join(modCache, name)
+		if err := os.MkdirAll(filepath.Join(dir, "internal"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "internal", "go.mod"), make([]byte, 4096), 0444); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{filepath.Join(dir, "internal"), dir} {
+			if err := os.Chmod(path, 0555); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+	removed, trashed := module("lib@v1.0.0"), module("lib@v1.1.0")
+
+	result := DeletePaths([]string{removed}, Options{Permanent: true})
+	if len(result.DetailedErrors) != 0 {
+		t.Fatalf("errors = %+v, want the module removed", result.DetailedErrors)
+	}
+	if _, err := os.Lstat(removed); !os.IsNotExist(err) {
+		t.Errorf("%s survived: %v", removed, err)
+	}
+
+	result = DeletePaths([]string{trashed}, Options{})
+	if len(result.DetailedErrors) != 0 {
+		t.Fatalf("errors = %+v, want the module trashed", result.DetailedErrors)
+	}
+	op, ok := journal.Get(result.OperationID)
+	if !ok || len(op.Entries) != 1 || op.Entries[0].TrashPath == "" {
+		t.Fatalf("journal = %+v, want one trashed entry", op)
+	}
+	// The trashed copy can be purged later
+	for _, dir := range []string{op.Entries[0].TrashPath, filepath.Join(op.Entries[0].TrashPath, "internal")} {
+		if info, err := os.Stat(dir); err != nil || info.Mode().Perm()&0200 == 0 {
+			t.Errorf("%s is not writable: %v", dir, err)
+		}
+	}
+}
+
+func TestShredOption(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	file := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(file, []byte(`{"token":"secret"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := DeletePaths([]string{file}, Options{Shred: true, DryRun: true})
+	if len(plan.Plan) != 1 || plan.Plan[0].Action != scanner.ActionShred || len(plan.Shredded) != 1 {
+		t.Fatalf("plan = %+v, want one shred with its storage checked", plan)
+	}
+
+	result := DeletePaths([]string{file}, Options{Shred: true, Source: journal.SourceShredPaths})
+	if len(result.DeletedPaths) != 1 || len(result.Shredded) != 1 || !result.Shredded[0].Removed {
+		t.Fatalf("result = %+v, want the file shredded", result)
+	}
+	if _, err := os.Lstat(file); !os.IsNotExist(err) {
+		t.Errorf("%s survived: %v", file, err)
+	}
+
+	op, ok := journal.Get(result.OperationID)
+	if !ok || len(op.Entries) != 1 || !op.Entries[0].Permanent {
+		t.Errorf("journal = %+v, want one permanent entry", op)
+	}
+}
+
+func TestDeletePathsAudited(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	file := filepath.Join(t.TempDir(), "old.log")
+	if err := os.WriteFile(file, []byte("log"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := DeletePaths([]string{file}, Options{Permanent: true, Source: journal.SourceDeletePaths})
+	if len(result.DeletedPaths) != 1 {
+		t.Fatalf("DeletePaths = %+v", result)
+	}
+
+	records, err := audit.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("audit.Query: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("audit log = %+v, want one record", records)
+	}
+	record := records[0]
+	if record.Path != file || record.Method != audit.MethodPermanent || record.Source != journal.SourceDeletePaths ||
+		record.Outcome != audit.OutcomeSuccess || record.OperationID != result.OperationID {
+		t.Errorf("record = %+v", record)
+	}
+}
+
+func TestDedupeAudited(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	group := scanner.DuplicateGroup{}
+	for _, name := range []string{"a.iso", "b.iso"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, 8192), 0644); err != nil {
+			t.Fatal(err)
+		}
+		group.Files = append(group.Files, scanner.DuplicateFile{Path: path, Name: name, Size: 8192})
+	}
+	groups := []scanner.DuplicateGroup{group}
+
+	Dedupe(context.Background(), groups, 0, scanner.ActionHardlink, true, audit.SourceDedupeDuplicateGroup)
+	if records, _ := audit.Query(audit.Filter{}); len(records) != 0 {
+		t.Fatalf("dry run audited %+v", records)
+	}
+
+	result := Dedupe(context.Background(), groups, 0, scanner.ActionHardlink, false, audit.SourceDedupeDuplicateGroup)
+	if len(result.DedupedPaths) != 1 {
+		t.Fatalf("Dedupe = %+v, want b.iso linked", result)
+	}
+	records, err := audit.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("audit.Query: %v", err)
+	}
+	if len(records) != 1 || records[0].Path != group.Files[1].Path || records[0].Method != audit.MethodHardlink ||
+		records[0].Source != audit.SourceDedupeDuplicateGroup || records[0].Size != 8192 || records[0].Outcome != audit.OutcomeSuccess {
+		t.Errorf("audit log = %+v, want the linked copy", records)
+	}
+}
+
+func TestDeleteNodeModules(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	root := t.TempDir()
+	var paths []string
+	for _, project := range []string{"app", "site"} {
+		path := filepath.Join(root, project, "node_modules")
+		if err := os.MkdirAll(filepath.Join(path, "dep"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(path, "dep", "index.js"), make([]byte, 4096), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	options := Options{Source: journal.SourceDeleteNodeModules, CheckGit: true}
+
+	dryRun := options
+	dryRun.DryRun = true
+	plan := DeleteNodeModules(paths, dryRun)
+	if !plan.DryRun || len(plan.Plan) != 2 || plan.OperationID != "" {
+		t.Fatalf("plan = %+v, want both directories planned", plan)
+	}
+	for _, planned := range plan.Plan {
+		if planned.Action != scanner.ActionTrash || planned.Error != nil || planned.Safety == nil {
+			t.Errorf("planned %+v, want a checked trash without error", planned)
+		}
+		if _, err := os.Stat(planned.Path); err != nil {
+			t.Errorf("dry run touched %s: %v", planned.Path, err)
+		}
+	}
+
+	result := DeleteNodeModules(paths, options)
+	if len(result.DeletedPaths) != 2 || len(result.Safety) != 2 {
+		t.Fatalf("result = %+v, want both directories trashed and checked", result)
+	}
+	op, ok := journal.Get(result.OperationID)
+	if !ok || len(op.Entries) != 2 || op.Entries[0].TrashPath == "" {
+		t.Errorf("journal = %+v, want 2 trashed entries", op)
+	}
+	for _, path := range paths {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s survived: %v", path, err)
+		}
+	}
+}
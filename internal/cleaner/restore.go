@@ -0,0 +1,171 @@
+package cleaner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+	"disk-peek/internal/trash"
+)
+
+// ConflictPolicy decides what happens when something already exists at a
+// path being restored
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the item in the trash and reports the conflict
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictRename restores the item next to the existing one as "name (restored)"
+	ConflictRename ConflictPolicy = "rename"
+	// ConflictReplace moves the existing item to the trash first
+	ConflictReplace ConflictPolicy = "replace"
+)
+
+// RestoreResult contains the results of a restore
+type RestoreResult struct {
+	// RestoredPaths are where items were put back, which differ from their
+	// original paths when renamed
+	RestoredPaths []string `json:"restoredPaths"`
+	// Conflicts are original paths skipped because they exist
+	Conflicts      []string             `json:"conflicts"`
+	DetailedErrors []scanner.CleanError `json:"detailedErrors"`
+}
+
+// RestoreOperation puts back everything an operation moved to the trash
+func RestoreOperation(id string, policy ConflictPolicy) RestoreResult {
+	result := newRestoreResult()
+
+	op, ok := journal.Get(id)
+	if !ok {
+		result.DetailedErrors = append(result.DetailedErrors, scanner.CleanError{
+			Message: fmt.Sprintf("Unknown operation %s", id),
+			Code:    "NOT_FOUND",
+		})
+		return result
+	}
+
+	var restored []string
+	for _, entry := range op.Entries {
+		if entry.Permanent {
+			continue
+		}
+		if restoreEntry(entry, policy, &result) {
+			restored = append(restored, entry.Path)
+		}
+	}
+	_ = journal.MarkRestored(op.ID, restored)
+
+	return result
+}
+
+// RestorePaths puts back the most recently trashed item of each path
+func RestorePaths(paths []string, policy ConflictPolicy) RestoreResult {
+	result := newRestoreResult()
+
+	for _, path := range paths {
+		id, entry, ok := journal.Latest(path)
+		if !ok {
+			result.DetailedErrors = append(result.DetailedErrors, scanner.CleanError{
+				Path:    path,
+				Message: "Nothing to restore for " + TruncatePath(path),
+				Code:    "NOT_FOUND",
+			})
+			continue
+		}
+		if restoreEntry(entry, policy, &result) {
+			_ = journal.MarkRestored(id, []string{path})
+		}
+	}
+
+	return result
+}
+
+// RestoreTrashItem puts back an item chosen from the trash listing and marks
+// the journal entry that trashed it as restored
+func RestoreTrashItem(id string) error {
+	if err := trash.Restore(id); err != nil {
+		return err
+	}
+	// Item IDs are .trashinfo paths, which entries record as InfoPath
+	_ = journal.MarkTrashRestored(id)
+	return nil
+}
+
+func newRestoreResult() RestoreResult {
+	return RestoreResult{
+		RestoredPaths:  []string{},
+		Conflicts:      []string{},
+		DetailedErrors: []scanner.CleanError{},
+	}
+}
+
+// restoreEntry puts back a single entry, reporting whether it was restored
+func restoreEntry(entry journal.Entry, policy ConflictPolicy, result *RestoreResult) bool {
+	addError := func(err error) {
+		result.DetailedErrors = append(result.DetailedErrors, scanner.CleanError{
+			Path:    entry.Path,
+			Message: ErrorMessage(err, entry.Path),
+			Code:    ErrorCode(err),
+		})
+	}
+
+	if !entry.Restorable() {
+		if entry.RestoredAt != nil {
+			return false
+		}
+		addError(fmt.Errorf("trash location of %s is unknown", TruncatePath(entry.Path)))
+		return false
+	}
+	if _, err := os.Lstat(entry.TrashPath); err != nil {
+		// Emptied from the trash, or restored by other means
+		addError(err)
+		return false
+	}
+
+	target := entry.Path
+	if _, err := os.Lstat(target); err == nil {
+		switch policy {
+		case ConflictRename:
+			target = restoredName(target)
+		case ConflictReplace:
+			existing := DeletePaths([]string{target}, Options{Source: journal.SourceRestore})
+			if len(existing.DetailedErrors) > 0 {
+				result.DetailedErrors = append(result.DetailedErrors, existing.DetailedErrors...)
+				return false
+			}
+		default:
+			result.Conflicts = append(result.Conflicts, entry.Path)
+			return false
+		}
+	}
+
+	if err := trash.PutBack(entry.Location(), target); err != nil {
+		addError(err)
+		return false
+	}
+	result.RestoredPaths = append(result.RestoredPaths, target)
+	return true
+}
+
+// restoredName returns a free path next to path, e.g. "notes (restored).txt"
+func restoredName(path string) string {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		// Dotfiles such as ".bashrc" have no extension to preserve
+		stem, ext = base, ""
+	}
+
+	candidate := filepath.Join(dir, stem+" (restored)"+ext)
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = filepath.Join(dir, fmt.Sprintf("%s (restored %d)%s", stem, i, ext))
+	}
+}
@@ -0,0 +1,177 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+)
+
+// trashFiles creates files under a temp dir and trashes them into a
+// temp XDG data home, returning the operation ID
+func trashFiles(t *testing.T, names ...string) (string, []string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("the trash location is only known on Linux")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	result := DeletePaths(paths, Options{Source: journal.SourceDeletePaths})
+	if len(result.DeletedPaths) != len(paths) || result.OperationID == "" {
+		t.Fatalf("DeletePaths = %+v, want all paths trashed and journaled", result)
+	}
+	return result.OperationID, paths
+}
+
+func TestRestoreOperation(t *testing.T) {
+	id, paths := trashFiles(t, "a.txt", "b.txt")
+
+	op, ok := journal.Get(id)
+	if !ok || len(op.Entries) != 2 || !op.Entries[0].Restorable() {
+		t.Fatalf("journal operation = %+v, want 2 restorable entries", op)
+	}
+
+	result := RestoreOperation(id, ConflictSkip)
+	if len(result.RestoredPaths) != 2 || len(result.DetailedErrors) != 0 {
+		t.Fatalf("RestoreOperation = %+v, want both restored", result)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != filepath.Base(path) {
+			t.Errorf("%s not restored: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(op.Entries[0].InfoPath); !os.IsNotExist(err) {
+		t.Errorf(".trashinfo still exists: %v", err)
+	}
+
+	// Restored entries are not restored twice
+	if result := RestoreOperation(id, ConflictSkip); len(result.RestoredPaths) != 0 {
+		t.Errorf("second restore = %+v, want nothing", result)
+	}
+}
+
+func TestRestoreTrashItem(t *testing.T) {
+	id, paths := trashFiles(t, "a.txt", "b.txt")
+
+	op, _ := journal.Get(id)
+	if err := RestoreTrashItem(op.Entries[1].InfoPath); err != nil {
+		t.Fatalf("RestoreTrashItem: %v", err)
+	}
+	if _, err := os.Stat(paths[1]); err != nil {
+		t.Errorf("%s not restored: %v", paths[1], err)
+	}
+
+	op, _ = journal.Get(id)
+	if !op.Entries[0].Restorable() || op.Entries[1].RestoredAt == nil {
+		t.Errorf("journal entries = %+v, want only b.txt marked restored", op.Entries)
+	}
+	if _, entry, ok := journal.Latest(paths[1]); ok {
+		t.Errorf("Latest(%s) = %+v, want nothing left to restore", paths[1], entry)
+	}
+
+	// Saves leave no temporary files behind
+	tmps, _ := filepath.Glob(filepath.Join(os.Getenv("XDG_DATA_HOME"), "disk-peek", "journal.json.tmp*"))
+	if len(tmps) != 0 {
+		t.Errorf("temporary journals left: %v", tmps)
+	}
+}
+
+func TestRestoreConflicts(t *testing.T) {
+	tests := []struct {
+		policy       ConflictPolicy
+		wantRestored string
+		wantOriginal string
+	}{
+		{ConflictSkip, "", "new"},
+		{ConflictRename, "notes (restored).txt", "new"},
+		{ConflictReplace, "notes.txt", "notes.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			_, paths := trashFiles(t, "notes.txt")
+			path := paths[0]
+			if err := os.WriteFile(path, []byte("new"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			result := RestorePaths([]string{path}, tt.policy)
+
+			if tt.wantRestored == "" {
+				if len(result.Conflicts) != 1 || len(result.RestoredPaths) != 0 {
+					t.Errorf("result = %+v, want one conflict", result)
+				}
+			} else if len(result.RestoredPaths) != 1 || filepath.Base(result.RestoredPaths[0]) != tt.wantRestored {
+				t.Errorf("result = %+v, want %s restored", result, tt.wantRestored)
+			}
+
+			if data, _ := os.ReadFile(path); string(data) != tt.wantOriginal {
+				t.Errorf("original path holds %q, want %q", data, tt.wantOriginal)
+			}
+		})
+	}
+
+	t.Run("unknown path", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", t.TempDir())
+		result := RestorePaths([]string{"/nowhere"}, ConflictSkip)
+		if len(result.DetailedErrors) != 1 || result.DetailedErrors[0].Code != "NOT_FOUND" {
+			t.Errorf("result = %+v, want NOT_FOUND", result)
+		}
+	})
+}
+
+func TestJournaledDeleter(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the trash location is only known on Linux")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	group := scanner.DuplicateGroup{Files: []scanner.DuplicateFile{
+		{Path: filepath.Join(dir, "photo.jpg"), Size: 4},
+		{Path: filepath.Join(dir, "photo (1).jpg"), Size: 4},
+	}}
+	for _, file := range group.Files {
+		if err := os.WriteFile(file.Path, []byte("jpeg"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	op := journal.NewOperation(journal.SourceDeleteDuplicateGroup)
+	plan := scanner.DeleteDuplicates([]scanner.DuplicateGroup{group}, 0, scanner.DryRunDeleter(Journaled(op, false)))
+	if !plan.DryRun || len(plan.Plan) != 1 || plan.Plan[0].Action != scanner.ActionTrash || plan.Plan[0].Error != nil || len(op.Entries) != 0 {
+		t.Fatalf("plan = %+v, entries = %+v; want the copy planned for the trash", plan, op.Entries)
+	}
+	if _, err := os.Stat(group.Files[1].Path); err != nil {
+		t.Fatalf("dry run removed the copy: %v", err)
+	}
+
+	result := scanner.DeleteDuplicates([]scanner.DuplicateGroup{group}, 0, Journaled(op, false))
+	if len(result.DeletedPaths) != 1 || len(op.Entries) != 1 {
+		t.Fatalf("result = %+v, entries = %+v; want the copy trashed and journaled", result, op.Entries)
+	}
+	entry := op.Entries[0]
+	if entry.Path != group.Files[1].Path || entry.TrashPath == "" || entry.Permanent || entry.Size != 4 {
+		t.Errorf("entry = %+v, want the trashed copy", entry)
+	}
+	if _, err := os.Stat(entry.TrashPath); err != nil {
+		t.Errorf("copy is not in the trash: %v", err)
+	}
+	if _, err := os.Stat(group.Files[0].Path); err != nil {
+		t.Errorf("kept file is gone: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"disk-peek/internal/audit"
+	"disk-peek/internal/scanner"
+)
+
+// SchemaVersion is bumped whenever a field is renamed or removed from an
+// exported document. Adding fields does not change the version.
+const SchemaVersion = 1
+
+// Kind identifies the type of result carried by a document or record
+type Kind string
+
+const (
+	KindDevScan     Kind = "dev-scan"
+	KindNormalScan  Kind = "normal-scan"
+	KindLargeFiles  Kind = "large-files"
+	KindDuplicates  Kind = "duplicates"
+	KindNodeModules Kind = "node-modules"
+	KindArtifacts   Kind = "artifacts"
+	KindTrends      Kind = "trends"
+	KindAuditLog    Kind = "audit-log"
+
+	// Record kinds only used in NDJSON streams
+	KindFileNode       Kind = "file-node"
+	KindLargeFile      Kind = "large-file"
+	KindCategory       Kind = "category"
+	KindDuplicateGroup Kind = "duplicate-group"
+	KindProject        Kind = "node-modules-project"
+	KindArtifact       Kind = "artifact"
+	KindAuditRecord    Kind = "audit-record"
+	KindSummary        Kind = "summary"
+)
+
+// Document is the envelope written around a complete result in JSON mode
+type Document struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Kind          Kind        `json:"kind"`
+	GeneratedAt   time.Time   `json:"generatedAt"`
+	Data          interface{} `json:"data"`
+}
+
+// Record is a single line of an NDJSON stream
+type Record struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Kind          Kind        `json:"kind"`
+	Data          interface{} `json:"data"`
+}
+
+// Summary is the final record of an NDJSON stream. It carries the totals
+// of the result without repeating the items already streamed.
+type Summary struct {
+	Kind         Kind          `json:"kind"`
+	TotalSize    int64         `json:"totalSize"`
+	TotalCount   int           `json:"totalCount"`
+	ScanDuration time.Duration `json:"scanDuration"`
+}
+
+// KindOf returns the document kind for a scan result value
+func KindOf(result interface{}) (Kind, error) {
+	switch result.(type) {
+	case scanner.ScanResult, *scanner.ScanResult:
+		return KindDevScan, nil
+	case scanner.FullScanResult, *scanner.FullScanResult:
+		return KindNormalScan, nil
+	case scanner.LargeFilesResult, *scanner.LargeFilesResult:
+		return KindLargeFiles, nil
+	case scanner.DuplicatesResult, *scanner.DuplicatesResult:
+		return KindDuplicates, nil
+	case scanner.NodeModulesResult, *scanner.NodeModulesResult:
+		return KindNodeModules, nil
+	case scanner.ArtifactsResult, *scanner.ArtifactsResult:
+		return KindArtifacts, nil
+	case scanner.TrendsResult, *scanner.TrendsResult:
+		return KindTrends, nil
+	case []audit.Record:
+		return KindAuditLog, nil
+	default:
+		return "", fmt.Errorf("unsupported result type %T", result)
+	}
+}
+
+// WriteJSON writes a complete result as an indented, versioned JSON document
+func WriteJSON(w io.Writer, result interface{}) error {
+	kind, err := KindOf(result)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Document{
+		SchemaVersion: SchemaVersion,
+		Kind:          kind,
+		GeneratedAt:   time.Now(),
+		Data:          result,
+	})
+}
+
+// NDJSONWriter writes newline-delimited JSON records
+// It is safe for concurrent use so scanner callbacks can stream into it
+type NDJSONWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewNDJSONWriter creates a new NDJSON writer
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// Write emits a single record. After the first failure all further
+// writes are dropped and the error is returned by Err.
+func (n *NDJSONWriter) Write(kind Kind, data interface{}) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.err != nil {
+		return n.err
+	}
+	n.err = n.enc.Encode(Record{
+		SchemaVersion: SchemaVersion,
+		Kind:          kind,
+		Data:          data,
+	})
+	return n.err
+}
+
+// Err returns the first error encountered while writing
+func (n *NDJSONWriter) Err() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err
+}
+
+// FileNode streams a node without its children, which are streamed
+// as their own records
+func (n *NDJSONWriter) FileNode(node *scanner.FileNode) error {
+	flat := *node
+	flat.Children = nil
+	return n.Write(KindFileNode, flat)
+}
+
+// LargeFile streams a single large file
+func (n *NDJSONWriter) LargeFile(file scanner.LargeFile) error {
+	return n.Write(KindLargeFile, file)
+}
+
+// WriteItems writes the items of an already completed result, one record
+// per item, followed by a summary record. Use it for results that are not
+// streamed while scanning.
+func (n *NDJSONWriter) WriteItems(result interface{}) error {
+	kind, err := KindOf(result)
+	if err != nil {
+		return err
+	}
+	// KindOf accepts pointers to results too; list their items the same way
+	if v := reflect.ValueOf(result); v.Kind() == reflect.Pointer && !v.IsNil() {
+		result = v.Elem().Interface()
+	}
+
+	switch r := result.(type) {
+	case scanner.ScanResult:
+		leaves := scanner.FlattenCategories(r.Categories)
+		for _, cat := range leaves {
+			_ = n.Write(KindCategory, cat)
+		}
+		return n.Summary(Summary{Kind: kind, TotalSize: r.TotalSize, TotalCount: len(leaves), ScanDuration: r.ScanDuration})
+	case scanner.LargeFilesResult:
+		for _, file := range r.Files {
+			_ = n.LargeFile(file)
+		}
+		return n.Summary(Summary{Kind: kind, TotalSize: r.TotalSize, TotalCount: r.TotalCount, ScanDuration: r.ScanDuration})
+	case scanner.DuplicatesResult:
+		for _, group := range r.Groups {
+			_ = n.Write(KindDuplicateGroup, group)
+		}
+		return n.Summary(Summary{Kind: kind, TotalSize: r.TotalWasted, TotalCount: r.TotalGroups, ScanDuration: r.ScanDuration})
+	case scanner.NodeModulesResult:
+		for _, project := range r.Projects {
+			_ = n.Write(KindProject, project)
+		}
+		return n.Summary(Summary{Kind: kind, TotalSize: r.TotalSize, TotalCount: r.TotalCount, ScanDuration: r.ScanDuration})
+	case scanner.ArtifactsResult:
+		for _, artifact := range r.Artifacts() {
+			_ = n.Write(KindArtifact, artifact)
+		}
+		return n.Summary(Summary{Kind: kind, TotalSize: r.TotalSize, TotalCount: r.TotalCount, ScanDuration: r.ScanDuration})
+	case []audit.Record:
+		var total int64
+		for _, record := range r {
+			total += record.Size
+			_ = n.Write(KindAuditRecord, record)
+		}
+		return n.Summary(Summary{Kind: kind, TotalSize: total, TotalCount: len(r)})
+	default:
+		// Results without a natural item list are written as a single record
+		return n.Write(kind, result)
+	}
+}
+
+// Summary writes the closing summary record of a stream
+func (n *NDJSONWriter) Summary(summary Summary) error {
+	return n.Write(KindSummary, summary)
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"disk-peek/internal/scanner"
+)
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	result := scanner.LargeFilesResult{
+		Files:      []scanner.LargeFile{{Path: "/tmp/a.iso", Name: "a.iso", Size: 42}},
+		TotalSize:  42,
+		TotalCount: 1,
+	}
+
+	if err := WriteJSON(&buf, result); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+
+	var doc struct {
+		SchemaVersion int                      `json:"schemaVersion"`
+		Kind          Kind                     `json:"kind"`
+		Data          scanner.LargeFilesResult `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.SchemaVersion != SchemaVersion {
+		t.Errorf("schemaVersion = %d, want %d", doc.SchemaVersion, SchemaVersion)
+	}
+	if doc.Kind != KindLargeFiles {
+		t.Errorf("kind = %s, want %s", doc.Kind, KindLargeFiles)
+	}
+	if len(doc.Data.Files) != 1 || doc.Data.Files[0].Path != "/tmp/a.iso" {
+		t.Errorf("unexpected data: %+v", doc.Data)
+	}
+}
+
+func TestWriteJSONUnsupportedType(t *testing.T) {
+	if err := WriteJSON(&bytes.Buffer{}, "not a result"); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
+
+func TestNDJSONWriteItems(t *testing.T) {
+	var buf bytes.Buffer
+	result := scanner.DuplicatesResult{
+		Groups: []scanner.DuplicateGroup{
+			{Hash: "a", Size: 10, WastedSize: 10},
+			{Hash: "b", Size: 20, WastedSize: 20},
+		},
+		TotalWasted: 30,
+		TotalGroups: 2,
+	}
+
+	if err := NewNDJSONWriter(&buf).WriteItems(result); err != nil {
+		t.Fatalf("WriteItems: %v", err)
+	}
+
+	var kinds []Kind
+	sc := bufio.NewScanner(strings.NewReader(buf.String()))
+	for sc.Scan() {
+		var rec struct {
+			Kind Kind `json:"kind"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid record %q: %v", sc.Text(), err)
+		}
+		kinds = append(kinds, rec.Kind)
+	}
+
+	want := []Kind{KindDuplicateGroup, KindDuplicateGroup, KindSummary}
+	if len(kinds) != len(want) {
+		t.Fatalf("got %d records, want %d", len(kinds), len(want))
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("record %d kind = %s, want %s", i, kinds[i], want[i])
+		}
+	}
+}
+
+func TestNDJSONFileNodeDropsChildren(t *testing.T) {
+	var buf bytes.Buffer
+	node := &scanner.FileNode{
+		Name:     "dir",
+		IsDir:    true,
+		Children: []*scanner.FileNode{{Name: "child"}},
+	}
+
+	if err := NewNDJSONWriter(&buf).FileNode(node); err != nil {
+		t.Fatalf("FileNode: %v", err)
+	}
+	if strings.Contains(buf.String(), "child") {
+		t.Errorf("streamed node should not include children: %s", buf.String())
+	}
+	if len(node.Children) != 1 {
+		t.Error("original node should not be modified")
+	}
+}
+
+func TestNDJSONWriteItemsPointer(t *testing.T) {
+	var buf bytes.Buffer
+	result := &scanner.LargeFilesResult{
+		Files:      []scanner.LargeFile{{Path: "/tmp/a.iso", Size: 42}, {Path: "/tmp/b.iso", Size: 7}},
+		TotalSize:  49,
+		TotalCount: 2,
+	}
+
+	if err := NewNDJSONWriter(&buf).WriteItems(result); err != nil {
+		t.Fatalf("WriteItems: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[2], `"kind":"summary"`) {
+		t.Errorf("got %q, want two items and a summary", lines)
+	}
+}
@@ -0,0 +1,218 @@
+// Package gitcheck asks git how safe it is to delete a path: ignored build
+// output can go, tracked or untracked work should not
+package gitcheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"disk-peek/internal/scanner"
+)
+
+// Checker inspects paths, caching what it learns about each repository
+// It is safe for concurrent use.
+type Checker struct {
+	mu    sync.Mutex
+	roots map[string]string // directory -> work tree root, "" outside a repo
+	dirty map[string]bool   // work tree root -> has uncommitted changes
+}
+
+// NewChecker creates a checker with empty caches
+func NewChecker() *Checker {
+	return &Checker{
+		roots: make(map[string]string),
+		dirty: make(map[string]bool),
+	}
+}
+
+// Check inspects a single path with a new checker
+func Check(path string) scanner.PathSafety {
+	return NewChecker().Check(path)
+}
+
+// Check rates deleting path by the repository it is in
+func (c *Checker) Check(path string) scanner.PathSafety {
+	safety := scanner.PathSafety{Path: path, Level: scanner.SafetyUnknown}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		safety.Reasons = []string{"git is not installed"}
+		return safety
+	}
+
+	// git reports the work tree with symlinks resolved
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		safety.Reasons = []string{err.Error()}
+		return safety
+	}
+	dir := resolved
+	if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
+		dir = filepath.Dir(resolved)
+	}
+
+	root := c.repoRoot(dir)
+	if root == "" {
+		safety.Reasons = []string{"not in a git repository"}
+		return safety
+	}
+	safety.Repo = root
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil {
+		safety.Reasons = []string{err.Error()}
+		return safety
+	}
+	rel = filepath.ToSlash(rel)
+
+	if rel != "." {
+		// check-ignore exits with 1 when the path is not ignored
+		safety.Ignored = run(root, "check-ignore", "-q", "--", rel) == nil
+	}
+
+	tracked, err := output(root, "ls-files", "-z", "--", rel)
+	if err != nil {
+		safety.Reasons = []string{err.Error()}
+		return safety
+	}
+	safety.TrackedFiles = countRecords(tracked)
+
+	if safety.TrackedFiles > 0 {
+		if status, err := output(root, "status", "--porcelain", "-z", "--untracked-files=no", "--", rel); err == nil {
+			safety.ModifiedFiles = countStatusRecords(status)
+		}
+	}
+
+	// Everything below an ignored path is ignored as well
+	if !safety.Ignored {
+		if untracked, err := output(root, "ls-files", "-z", "--others", "--exclude-standard", "--", rel); err == nil {
+			safety.UntrackedFiles = countRecords(untracked)
+		}
+	}
+
+	safety.RepoDirty = c.repoDirty(root)
+	classify(&safety)
+	return safety
+}
+
+// CheckAll inspects every path
+func (c *Checker) CheckAll(paths []string) []scanner.PathSafety {
+	results := make([]scanner.PathSafety, 0, len(paths))
+	for _, path := range paths {
+		results = append(results, c.Check(path))
+	}
+	return results
+}
+
+// classify sets the level and reasons from the counts
+func classify(safety *scanner.PathSafety) {
+	var reasons []string
+	switch {
+	case safety.TrackedFiles > 0:
+		safety.Level = scanner.SafetyDanger
+		reasons = append(reasons, fmt.Sprintf("%d files tracked by git", safety.TrackedFiles))
+		if safety.ModifiedFiles > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d with uncommitted changes", safety.ModifiedFiles))
+		}
+	case safety.UntrackedFiles > 0:
+		safety.Level = scanner.SafetyWarning
+		reasons = append(reasons, fmt.Sprintf("%d untracked files not ignored by git", safety.UntrackedFiles))
+	default:
+		safety.Level = scanner.SafetySafe
+		if safety.Ignored {
+			reasons = append(reasons, "ignored by git")
+		} else {
+			reasons = append(reasons, "no tracked or untracked files")
+		}
+	}
+	if safety.RepoDirty {
+		reasons = append(reasons, "repository has uncommitted changes")
+	}
+	safety.Reasons = reasons
+}
+
+// repoRoot returns the work tree containing dir, or "" if there is none
+func (c *Checker) repoRoot(dir string) string {
+	c.mu.Lock()
+	root, ok := c.roots[dir]
+	c.mu.Unlock()
+	if ok {
+		return root
+	}
+
+	out, err := output(dir, "rev-parse", "--show-toplevel")
+	if err == nil {
+		root = filepath.FromSlash(string(bytes.TrimSpace(out)))
+	}
+
+	c.mu.Lock()
+	c.roots[dir] = root
+	c.mu.Unlock()
+	return root
+}
+
+// repoDirty reports whether the work tree has uncommitted changes to
+// tracked files
+func (c *Checker) repoDirty(root string) bool {
+	c.mu.Lock()
+	dirty, ok := c.dirty[root]
+	c.mu.Unlock()
+	if ok {
+		return dirty
+	}
+
+	out, err := output(root, "status", "--porcelain", "-z", "--untracked-files=no")
+	dirty = err == nil && len(out) > 0
+
+	c.mu.Lock()
+	c.dirty[root] = dirty
+	c.mu.Unlock()
+	return dirty
+}
+
+// countRecords counts the NUL-terminated records of -z output
+func countRecords(out []byte) int {
+	return bytes.Count(out, []byte{0})
+}
+
+// countStatusRecords counts the entries of "git status --porcelain -z",
+// where renames and copies carry the original path as an extra record
+func countStatusRecords(out []byte) int {
+	count := 0
+	records := bytes.Split(out, []byte{0})
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 3 {
+			continue
+		}
+		count++
+		if record[0] == 'R' || record[0] == 'C' {
+			i++
+		}
+	}
+	return count
+}
+
+// output runs git in dir and returns its standard output
+func output(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, errors.New(string(msg))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// run runs git in dir for its exit status
+func run(dir string, args ...string) error {
+	return exec.Command("git", append([]string{"-C", dir}, args...)...).Run()
+}
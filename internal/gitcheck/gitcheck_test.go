@@ -0,0 +1,95 @@
+package gitcheck
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"disk-peek/internal/scanner"
+)
+
+// newRepo creates a repository with committed sources, an ignored
+// node_modules and an untracked scratch directory
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	repo := t.TempDir()
+	files := map[string]string{
+		".gitignore":                 "node_modules/\n",
+		"src/main.go":                "package main\n",
+		"node_modules/left-pad/i.js": "module.exports = 1\n",
+		"scratch/notes.txt":          "todo\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", ".gitignore", "src"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return repo
+}
+
+func TestCheck(t *testing.T) {
+	repo := newRepo(t)
+	checker := NewChecker()
+
+	tests := []struct {
+		path    string
+		level   string
+		ignored bool
+	}{
+		{"node_modules", scanner.SafetySafe, true},
+		{"scratch", scanner.SafetyWarning, false},
+		{"src", scanner.SafetyDanger, false},
+		{".", scanner.SafetyDanger, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			safety := checker.Check(filepath.Join(repo, tt.path))
+			if safety.Level != tt.level || safety.Ignored != tt.ignored {
+				t.Errorf("Check = %+v, want level %s, ignored %v", safety, tt.level, tt.ignored)
+			}
+			if safety.RepoDirty {
+				t.Errorf("clean repository reported dirty")
+			}
+		})
+	}
+
+	t.Run("uncommitted changes", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(repo, "src", "main.go"), []byte("package main // edited\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		safety := NewChecker().Check(filepath.Join(repo, "src"))
+		if safety.Level != scanner.SafetyDanger || safety.ModifiedFiles != 1 || !safety.RepoDirty {
+			t.Errorf("Check = %+v, want a modified tracked file", safety)
+		}
+	})
+
+	t.Run("outside a repository", func(t *testing.T) {
+		safety := checker.Check(t.TempDir())
+		if safety.Level != scanner.SafetyUnknown || safety.Repo != "" {
+			t.Errorf("Check = %+v, want unknown", safety)
+		}
+	})
+}
@@ -0,0 +1,290 @@
+// Package journal records what Disk Peek deleted and where trashed items
+// went, so they can be put back
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"disk-peek/internal/trash"
+	"disk-peek/internal/xdg"
+)
+
+// maxOperations caps the journal; the oldest operations are dropped first
+const maxOperations = 500
+
+// Sources name the API that started an operation
+const (
+	SourceDeletePaths              = "DeletePaths"
+	SourceShredPaths               = "ShredPaths"
+	SourceCleanCategories          = "CleanCategories"
+	SourceCleanStaleItems          = "CleanStaleItems"
+	SourceDeleteNodeModules        = "DeleteNodeModules"
+	SourceDeleteArtifacts          = "DeleteArtifacts"
+	SourceDeleteDuplicateGroup     = "DeleteDuplicateGroup"
+	SourceDeleteDuplicateDecisions = "DeleteDuplicateDecisions"
+	SourceRestore                  = "Restore"
+	// SourcePolicy is followed by ":" and the ID of the cleanup policy
+	SourcePolicy = "Policy"
+)
+
+// Operation is one delete or clean action and everything it removed
+type Operation struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`
+	Timestamp time.Time `json:"timestamp"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Entry is a single removed file or directory
+type Entry struct {
+	Path      string `json:"path"`
+	TrashPath string `json:"trashPath,omitempty"`
+	InfoPath  string `json:"infoPath,omitempty"`
+	Size      int64  `json:"size"`
+	// Permanent entries were removed with os.RemoveAll and can't be restored
+	Permanent  bool       `json:"permanent"`
+	RestoredAt *time.Time `json:"restoredAt,omitempty"`
+}
+
+// Location returns where the entry was trashed
+func (e Entry) Location() trash.Location {
+	return trash.Location{TrashPath: e.TrashPath, InfoPath: e.InfoPath}
+}
+
+// Restorable reports whether the entry is still in a known trash location
+func (e Entry) Restorable() bool {
+	return !e.Permanent && e.RestoredAt == nil && e.TrashPath != ""
+}
+
+// NewOperation starts an operation; entries are added as paths are removed
+func NewOperation(source string) *Operation {
+	now := time.Now()
+	return &Operation{
+		ID:        strconv.FormatInt(now.UnixNano(), 36),
+		Source:    source,
+		Timestamp: now,
+		Entries:   []Entry{},
+	}
+}
+
+// Add appends an entry to the operation
+func (op *Operation) Add(entry Entry) {
+	op.Entries = append(op.Entries, entry)
+}
+
+// mu serializes load-modify-save cycles within the process; lock does the
+// same across processes
+var mu sync.Mutex
+
+// errCorrupt is returned by load for a journal that can't be parsed
+var errCorrupt = errors.New("corrupt journal")
+
+// getJournalPath returns the path to the journal ($XDG_DATA_HOME/disk-peek)
+func getJournalPath() (string, error) {
+	return xdg.DataFile("journal.json")
+}
+
+// load reads every recorded operation, oldest first
+func load() ([]Operation, error) {
+	path, err := getJournalPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Operation{}, nil
+		}
+		return nil, err
+	}
+
+	var operations []Operation
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", path, errCorrupt, err)
+	}
+	return operations, nil
+}
+
+// setAside renames a corrupt journal to journal.json.corrupt-<time>,
+// keeping it for inspection
+func setAside() error {
+	path, err := getJournalPath()
+	if err != nil {
+		return err
+	}
+	return os.Rename(path, path+".corrupt-"+time.Now().Format("20060102-150405"))
+}
+
+// save replaces the journal atomically
+func save(operations []Operation) error {
+	path, err := getJournalPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(operations, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// A unique name keeps concurrent writers from sharing a half-written file
+	tmp, err := os.CreateTemp(filepath.Dir(path), "journal.json.tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Record appends an operation to the journal
+// Operations without entries are not recorded.
+func Record(op *Operation) error {
+	if op == nil || len(op.Entries) == 0 {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	operations, err := load()
+	if errors.Is(err, errCorrupt) {
+		// A corrupt journal is set aside rather than blocking deletes or
+		// being overwritten
+		if err := setAside(); err != nil {
+			return err
+		}
+		operations = []Operation{}
+	} else if err != nil {
+		return err
+	}
+	operations = append(operations, *op)
+	if len(operations) > maxOperations {
+		operations = operations[len(operations)-maxOperations:]
+	}
+	return save(operations)
+}
+
+// List returns the recorded operations, newest first
+func List() []Operation {
+	mu.Lock()
+	defer mu.Unlock()
+
+	operations, err := load()
+	if err != nil {
+		return []Operation{}
+	}
+	for i, j := 0, len(operations)-1; i < j; i, j = i+1, j-1 {
+		operations[i], operations[j] = operations[j], operations[i]
+	}
+	return operations
+}
+
+// Get returns the operation with the given ID
+func Get(id string) (Operation, bool) {
+	for _, op := range List() {
+		if op.ID == id {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+// Latest returns the ID of the newest operation holding a restorable entry
+// for path, and that entry
+func Latest(path string) (string, Entry, bool) {
+	for _, op := range List() {
+		for _, entry := range op.Entries {
+			if entry.Path == path && entry.Restorable() {
+				return op.ID, entry, true
+			}
+		}
+	}
+	return "", Entry{}, false
+}
+
+// MarkRestored records that paths of operation id are back in place
+func MarkRestored(id string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	restored := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		restored[path] = true
+	}
+	return markRestored(func(op Operation, entry Entry) bool {
+		return op.ID == id && restored[entry.Path]
+	})
+}
+
+// MarkTrashRestored records that the entry trashed with the given
+// .trashinfo file is back in place, as when it is restored from the trash
+// listing rather than through its operation
+func MarkTrashRestored(infoPath string) error {
+	if infoPath == "" {
+		return nil
+	}
+	return markRestored(func(_ Operation, entry Entry) bool {
+		return entry.InfoPath == infoPath
+	})
+}
+
+// markRestored sets RestoredAt on the unrestored entries match accepts
+func markRestored(match func(op Operation, entry Entry) bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	operations, err := load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	changed := false
+	for i := range operations {
+		for j := range operations[i].Entries {
+			entry := &operations[i].Entries[j]
+			if entry.RestoredAt == nil && match(operations[i], *entry) {
+				entry.RestoredAt = &now
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return save(operations)
+}
@@ -0,0 +1,34 @@
+//go:build !windows
+
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lock takes an advisory lock on journal.json.lock, serializing
+// load-modify-save cycles with other Disk Peek processes such as the CLI
+func lock() (unlock func(), err error) {
+	path, err := getJournalPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, &os.PathError{Op: "flock", Path: f.Name(), Err: err}
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
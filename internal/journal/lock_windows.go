@@ -0,0 +1,8 @@
+//go:build windows
+
+package journal
+
+// lock is a no-op on Windows, where only the in-process mutex applies
+func lock() (unlock func(), err error) {
+	return func() {}, nil
+}
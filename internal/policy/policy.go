@@ -0,0 +1,274 @@
+// Package policy applies the automatic cleanup policies from settings
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"disk-peek/internal/cleaner"
+	"disk-peek/internal/journal"
+	"disk-peek/internal/scanner"
+	"disk-peek/internal/settings"
+	"disk-peek/internal/xdg"
+)
+
+// Reasons a policy run cleaned nothing
+const (
+	SkipDisabled       = "disabled"
+	SkipNotDue         = "not due"
+	SkipUnknownTarget  = "unknown target"
+	SkipNoMaxAge       = "node-modules needs maxAgeDays"
+	SkipCategoryOff    = "category disabled"
+	SkipBelowMinSize   = "below minimum size"
+	SkipNothingToClean = "nothing to clean"
+	SkipCancelled      = "cancelled"
+)
+
+// Run is the outcome of evaluating one policy
+type Run struct {
+	PolicyID string    `json:"policyId"`
+	Time     time.Time `json:"time"`
+	// Size is the measured size of what the policy selected
+	Size int64 `json:"size"`
+	// Skipped explains why nothing was cleaned; Result is nil then
+	Skipped string               `json:"skipped,omitempty"`
+	Result  *scanner.CleanResult `json:"result,omitempty"`
+}
+
+// Options controls Apply
+type Options struct {
+	// DryRun plans the cleanup without deleting or recording the run
+	DryRun bool
+	// Force runs policies that are not due yet
+	Force bool
+	// Now is the evaluation time, time.Now() when zero
+	Now time.Time
+}
+
+// running keeps scheduled and manual runs from overlapping
+var running sync.Mutex
+
+// Apply evaluates every policy and cleans what the due ones select
+// Each cleanup is journaled with the source "Policy:<id>".
+func Apply(ctx context.Context, policies []settings.Policy, options Options) []Run {
+	running.Lock()
+	defer running.Unlock()
+
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	state := loadState()
+
+	e := &evaluator{ctx: ctx, now: now}
+	runs := make([]Run, 0, len(policies))
+	for _, p := range policies {
+		run := Run{PolicyID: p.ID, Time: now}
+		switch {
+		case ctx.Err() != nil:
+			run.Skipped = SkipCancelled
+		case !p.Enabled:
+			run.Skipped = SkipDisabled
+		case !options.Force && !Due(p, state[p.ID], now):
+			run.Skipped = SkipNotDue
+		default:
+			e.apply(p, options.DryRun, &run)
+			// Skipped policies are evaluated again on the next check
+			if !options.DryRun && run.Result != nil {
+				state[p.ID] = now
+			}
+		}
+		runs = append(runs, run)
+	}
+
+	if !options.DryRun {
+		_ = saveState(state)
+	}
+	return runs
+}
+
+// Due reports whether a policy last evaluated at lastRun should run at now
+func Due(p settings.Policy, lastRun time.Time, now time.Time) bool {
+	if p.IntervalHours <= 0 || lastRun.IsZero() {
+		return true
+	}
+	return !now.Before(lastRun.Add(time.Duration(p.IntervalHours) * time.Hour))
+}
+
+// LastRuns returns when each policy was last evaluated, by policy ID
+func LastRuns() map[string]time.Time {
+	return loadState()
+}
+
+// evaluator measures policy targets, sharing scans between policies
+type evaluator struct {
+	ctx         context.Context
+	now         time.Time
+	nodeModules *scanner.NodeModulesResult
+}
+
+// apply fills in run for a policy that is due
+func (e *evaluator) apply(p settings.Policy, dryRun bool, run *Run) {
+	var paths []string
+	if p.Target == settings.PolicyTargetNodeModules {
+		// SetPolicies refuses these, but settings.json may be edited by hand
+		if p.MaxAgeDays <= 0 {
+			run.Skipped = SkipNoMaxAge
+			return
+		}
+		paths, run.Size = e.staleNodeModules(p.MaxAgeDays)
+	} else {
+		if !IsCategory(p.Target) {
+			run.Skipped = SkipUnknownTarget
+			return
+		}
+		if !settings.IsCategoryEnabled(p.Target) {
+			run.Skipped = SkipCategoryOff
+			return
+		}
+		var ok bool
+		if paths, run.Size, ok = e.category(p); !ok {
+			run.Skipped = SkipUnknownTarget
+			return
+		}
+	}
+
+	switch {
+	case e.ctx.Err() != nil:
+		run.Skipped = SkipCancelled
+		return
+	case run.Size < p.MinSize:
+		run.Skipped = SkipBelowMinSize
+		return
+	case len(paths) == 0:
+		run.Skipped = SkipNothingToClean
+		return
+	}
+
+	result := cleaner.DeletePaths(paths, cleaner.Options{
+		Permanent: p.Permanent,
+		Source:    journal.SourcePolicy + ":" + p.ID,
+		DryRun:    dryRun,
+		Ctx:       e.ctx,
+		// Nobody confirms scheduled runs, so never remove tracked work
+		SkipTracked: p.Target == settings.PolicyTargetNodeModules,
+	})
+	run.Result = &result
+}
+
+// category returns what a category policy cleans and its total size: the
+// whole category, or only its items unused for MaxAgeDays. It reports false
+// if the category can't be scanned, e.g. because it was removed from the
+// custom categories meanwhile.
+func (e *evaluator) category(p settings.Policy) ([]string, int64, bool) {
+	if p.MaxAgeDays > 0 {
+		var paths []string
+		var size int64
+		filter := scanner.AgeFilter{Before: e.now.AddDate(0, 0, -p.MaxAgeDays)}
+		for _, item := range cleaner.StaleItems([]string{p.Target}, filter) {
+			paths = append(paths, item.Path)
+			size += item.Size
+		}
+		return paths, size, true
+	}
+
+	devScanner := scanner.NewDevScanner(4)
+	devScanner.SetContext(e.ctx)
+	cat := devScanner.ScanCategory(p.Target)
+	if cat == nil {
+		return nil, 0, false
+	}
+	return cleaner.CategoryPaths(scanner.GetCategories(), []string{p.Target}), cat.Size, true
+}
+
+// IsCategory reports whether id names a dev category, built in or custom,
+// that a policy can target
+func IsCategory(id string) bool {
+	return scanner.GetCategoryByID(scanner.GetCategories(), id) != nil
+}
+
+// staleNodeModules returns the node_modules directories of projects untouched
+// for maxAgeDays, and their total size
+func (e *evaluator) staleNodeModules(maxAgeDays int) ([]string, int64) {
+	if e.nodeModules == nil {
+		result := scanner.FindNodeModules(scanner.ProjectSearch(settings.GetProjectSearch()), nil)
+		e.nodeModules = &result
+	}
+
+	cutoff := e.now.AddDate(0, 0, -maxAgeDays)
+	var paths []string
+	var size int64
+	for _, project := range e.nodeModules.Projects {
+		if projectActivity(project).After(cutoff) {
+			continue
+		}
+		paths = append(paths, project.Path)
+		size += project.Size
+	}
+	return paths, size
+}
+
+// projectActivity returns when a project was last worked on: the newest
+// mtime of the entries in its root, such as package.json, the lockfile or
+// src. node_modules itself is left out, since installing dependencies
+// doesn't make a project active; it is only used when the root holds
+// nothing else.
+func projectActivity(project scanner.NodeModulesProject) time.Time {
+	root := filepath.Dir(project.Path)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return project.ModTime
+	}
+
+	var newest time.Time
+	for _, entry := range entries {
+		if entry.Name() == filepath.Base(project.Path) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	if newest.IsZero() {
+		return project.ModTime
+	}
+	return newest
+}
+
+// getStatePath returns the file recording when policies last ran
+func getStatePath() (string, error) {
+	return xdg.DataFile("policies.json")
+}
+
+func loadState() map[string]time.Time {
+	state := make(map[string]time.Time)
+	path, err := getStatePath()
+	if err != nil {
+		return state
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	_ = json.Unmarshal(data, &state)
+	return state
+}
+
+func saveState(state map[string]time.Time) error {
+	path, err := getStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,146 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"disk-peek/internal/settings"
+)
+
+func TestDue(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	weekly := settings.Policy{IntervalHours: 168}
+
+	tests := []struct {
+		name    string
+		policy  settings.Policy
+		lastRun time.Time
+		want    bool
+	}{
+		{"never ran", weekly, time.Time{}, true},
+		{"ran yesterday", weekly, now.Add(-24 * time.Hour), false},
+		{"ran a week ago", weekly, now.Add(-168 * time.Hour), true},
+		{"no interval", settings.Policy{}, now.Add(-time.Minute), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Due(tt.policy, tt.lastRun, now); got != tt.want {
+				t.Errorf("Due = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyNodeModules(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	old := filepath.Join(home, "Projects", "old", "node_modules")
+	fresh := filepath.Join(home, "Projects", "fresh", "node_modules")
+	// Dependencies installed long ago, but the project was just edited
+	active := filepath.Join(home, "Projects", "active", "node_modules")
+	for _, dir := range []string{old, fresh, active} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index.js"), make([]byte, 4096), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "..", "package.json"), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	stale := now.AddDate(0, 0, -120)
+	for _, path := range []string{old, filepath.Join(old, "..", "package.json"), active} {
+		if err := os.Chtimes(path, stale, stale); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policies := []settings.Policy{
+		{ID: "stale", Enabled: true, Target: settings.PolicyTargetNodeModules, MaxAgeDays: 90, IntervalHours: 24, Permanent: true},
+		{ID: "huge", Enabled: true, Target: settings.PolicyTargetNodeModules, MaxAgeDays: 90, MinSize: 1 << 40},
+		{ID: "off", Target: settings.PolicyTargetNodeModules, MaxAgeDays: 90},
+		{ID: "all", Enabled: true, Target: settings.PolicyTargetNodeModules},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		runs := Apply(context.Background(), policies, Options{DryRun: true, Now: now})
+		if runs[0].Result == nil || len(runs[0].Result.Plan) != 1 || runs[0].Result.Plan[0].Path != old {
+			t.Fatalf("stale run = %+v, want a plan for %s", runs[0], old)
+		}
+		if runs[1].Skipped != SkipBelowMinSize || runs[2].Skipped != SkipDisabled || runs[3].Skipped != SkipNoMaxAge {
+			t.Errorf("skipped = %q, %q, %q; want %q, %q, %q", runs[1].Skipped, runs[2].Skipped, runs[3].Skipped,
+				SkipBelowMinSize, SkipDisabled, SkipNoMaxAge)
+		}
+		if _, err := os.Stat(old); err != nil {
+			t.Errorf("dry run removed %s", old)
+		}
+		if len(LastRuns()) != 0 {
+			t.Errorf("dry run recorded last runs: %v", LastRuns())
+		}
+	})
+
+	t.Run("run", func(t *testing.T) {
+		runs := Apply(context.Background(), policies[:2], Options{Now: now})
+		if runs[0].Result == nil || len(runs[0].Result.DeletedPaths) != 1 || runs[0].Result.OperationID == "" {
+			t.Fatalf("run = %+v, want one journaled deletion", runs[0])
+		}
+		// Only the policy that cleaned counts as run
+		if lastRuns := LastRuns(); !lastRuns["stale"].Equal(now) || !lastRuns["huge"].IsZero() {
+			t.Errorf("last runs = %v, want only stale recorded", lastRuns)
+		}
+		if _, err := os.Stat(old); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", old)
+		}
+		for _, dir := range []string{fresh, active} {
+			if _, err := os.Stat(dir); err != nil {
+				t.Errorf("%s was removed", dir)
+			}
+		}
+
+		// Within the interval the policy is not due
+		runs = Apply(context.Background(), policies[:1], Options{Now: now.Add(time.Hour)})
+		if runs[0].Skipped != SkipNotDue {
+			t.Errorf("second run skipped = %q, want %q", runs[0].Skipped, SkipNotDue)
+		}
+	})
+}
+
+func TestSetPoliciesNeedsMaxAgeForNodeModules(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	err := settings.SetPolicies([]settings.Policy{{ID: "all", Target: settings.PolicyTargetNodeModules}}, IsCategory)
+	if err == nil {
+		t.Error("SetPolicies accepted a node-modules policy without maxAgeDays")
+	}
+	err = settings.SetPolicies([]settings.Policy{{ID: "stale", Target: settings.PolicyTargetNodeModules, MaxAgeDays: 30}}, IsCategory)
+	if err != nil {
+		t.Errorf("SetPolicies: %v", err)
+	}
+}
+
+func TestSetPoliciesValidates(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	gomod := settings.Policy{ID: "gomod", Target: "go"}
+	invalid := map[string][]settings.Policy{
+		"empty id":       {{Target: "go"}},
+		"duplicate id":   {gomod, gomod},
+		"unknown target": {{ID: "typo", Target: "og"}},
+	}
+	for name, policies := range invalid {
+		if err := settings.SetPolicies(policies, IsCategory); err == nil {
+			t.Errorf("%s: SetPolicies accepted %+v", name, policies)
+		}
+	}
+	if err := settings.SetPolicies([]settings.Policy{gomod}, IsCategory); err != nil {
+		t.Errorf("SetPolicies: %v", err)
+	}
+}
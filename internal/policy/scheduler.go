@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"disk-peek/internal/settings"
+)
+
+// DefaultCheckInterval is how often the scheduler looks for due policies
+const DefaultCheckInterval = time.Hour
+
+// startupDelay keeps the first check from competing with app startup
+const startupDelay = time.Minute
+
+// Scheduler applies the policies from settings periodically
+type Scheduler struct {
+	interval time.Duration
+	onRun    func(runs []Run)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewScheduler creates a scheduler that checks for due policies every
+// interval; onRun (if any) receives the runs that cleaned something
+func NewScheduler(interval time.Duration, onRun func(runs []Run)) *Scheduler {
+	return &Scheduler{interval: interval, onRun: onRun}
+}
+
+// Start begins checking in the background
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.loop(ctx)
+}
+
+// Stop stops checking and cancels a run in progress
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		if s.cancel != nil {
+			s.cancel()
+			<-s.done
+		}
+	})
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	defer close(s.done)
+
+	wait := startupDelay
+	if s.interval < wait {
+		wait = s.interval
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			s.check(ctx)
+			timer.Reset(s.interval)
+		}
+	}
+}
+
+// check applies the enabled policies once
+func (s *Scheduler) check(ctx context.Context) {
+	var enabled []settings.Policy
+	for _, p := range settings.GetPolicies() {
+		if p.Enabled {
+			enabled = append(enabled, p)
+		}
+	}
+	if len(enabled) == 0 {
+		return
+	}
+
+	var cleaned []Run
+	for _, run := range Apply(ctx, enabled, Options{}) {
+		if run.Result != nil {
+			cleaned = append(cleaned, run)
+		}
+	}
+	if len(cleaned) > 0 && s.onRun != nil {
+		s.onRun(cleaned)
+	}
+}
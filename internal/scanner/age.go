@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"os"
+	"time"
+)
+
+// AgeFilter selects category items that haven't been used for a while
+// An item was last used when any file below it was last read or written.
+type AgeFilter struct {
+	// OlderThanDays matches items unused for at least this many days
+	OlderThanDays int `json:"olderThanDays,omitempty"`
+	// Before matches items unused since this time
+	Before time.Time `json:"before,omitempty"`
+	// IgnoreAccess judges items by modification time only, for filesystems
+	// mounted noatime or caches that indexers read
+	IgnoreAccess bool `json:"ignoreAccess,omitempty"`
+}
+
+// IsZero reports whether the filter matches every item
+func (f AgeFilter) IsZero() bool {
+	return f.OlderThanDays <= 0 && f.Before.IsZero()
+}
+
+// Cutoff returns the time an item must be unused since to match
+func (f AgeFilter) Cutoff(now time.Time) time.Time {
+	cutoff := f.Before
+	if f.OlderThanDays > 0 {
+		days := now.AddDate(0, 0, -f.OlderThanDays)
+		if cutoff.IsZero() || days.Before(cutoff) {
+			cutoff = days
+		}
+	}
+	return cutoff
+}
+
+// Matches reports whether node was last used before the cutoff
+func (f AgeFilter) Matches(node FileNode, now time.Time) bool {
+	if f.IsZero() {
+		return true
+	}
+	return !f.LastUsed(node).After(f.Cutoff(now))
+}
+
+// LastUsed returns the time the filter considers node last used
+func (f AgeFilter) LastUsed(node FileNode) time.Time {
+	last := node.LastModified
+	if last.IsZero() {
+		last = node.ModTime
+	}
+	if !f.IgnoreAccess && node.LastAccess.After(last) {
+		last = node.LastAccess
+	}
+	return last
+}
+
+// FilterItems returns the items that filter matches
+func FilterItems(items []FileNode, filter AgeFilter, now time.Time) []FileNode {
+	matched := []FileNode{}
+	for _, item := range items {
+		if filter.Matches(item, now) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
+// track records the access and modification times of a walked file
+func (r *WalkResult) track(info os.FileInfo) {
+	if atime := accessTime(info); atime.After(r.LastAccess) {
+		r.LastAccess = atime
+	}
+	if mtime := info.ModTime(); mtime.After(r.LastModified) {
+		r.LastModified = mtime
+	}
+}
@@ -0,0 +1,462 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ecosystems of the built-in artifact detectors
+const (
+	EcosystemNode      = "node"
+	EcosystemRust      = "rust"
+	EcosystemMaven     = "maven"
+	EcosystemGradle    = "gradle"
+	EcosystemPython    = "python"
+	EcosystemTerraform = "terraform"
+	EcosystemBazel     = "bazel"
+	EcosystemZig       = "zig"
+)
+
+// ArtifactDetector recognizes a kind of regenerable build output directory
+// inside a project
+type ArtifactDetector struct {
+	Ecosystem string
+	// Names are directory name patterns, as understood by filepath.Match
+	Names []string
+	// Markers are files in the project root (the artifact's parent), one of
+	// which must exist to confirm the match. Patterns such as "*.tf" are
+	// allowed. Without markers the name alone is enough.
+	Markers []string
+	// Contains are files inside the artifact, one of which must exist
+	Contains []string
+	// Symlinks accepts artifacts that are symlinks to a directory, like the
+	// bazel-* convenience links. The link target is reported and deleted.
+	Symlinks bool
+}
+
+var gradleMarkers = []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}
+
+// DefaultArtifactDetectors returns the built-in detectors. When several
+// match a directory the first one wins.
+func DefaultArtifactDetectors() []ArtifactDetector {
+	return []ArtifactDetector{
+		{Ecosystem: EcosystemNode, Names: []string{"node_modules"}},
+		{Ecosystem: EcosystemNode, Names: []string{".next", ".nuxt", "dist"}, Markers: []string{"package.json"}},
+		{Ecosystem: EcosystemRust, Names: []string{"target"}, Markers: []string{"Cargo.toml"}},
+		{Ecosystem: EcosystemMaven, Names: []string{"target"}, Markers: []string{"pom.xml"}},
+		{Ecosystem: EcosystemGradle, Names: []string{"build", ".gradle"}, Markers: gradleMarkers},
+		{Ecosystem: EcosystemPython, Names: []string{".venv", "venv"}, Contains: []string{"pyvenv.cfg"}},
+		{Ecosystem: EcosystemPython, Names: []string{"__pycache__"}},
+		{Ecosystem: EcosystemPython, Names: []string{".tox"}, Markers: []string{"tox.ini", "pyproject.toml", "setup.cfg", "setup.py"}},
+		{Ecosystem: EcosystemPython, Names: []string{"dist"}, Markers: []string{"pyproject.toml", "setup.py"}},
+		{Ecosystem: EcosystemTerraform, Names: []string{".terraform"}, Markers: []string{"*.tf"}},
+		{Ecosystem: EcosystemBazel, Names: []string{"bazel-*"}, Markers: []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"}, Symlinks: true},
+		{Ecosystem: EcosystemZig, Names: []string{"zig-cache", ".zig-cache", "zig-out"}, Markers: []string{"build.zig"}},
+	}
+}
+
+// ArtifactDetectorsFor returns the default detectors of the given ecosystems
+func ArtifactDetectorsFor(ecosystems []string) []ArtifactDetector {
+	wanted := make(map[string]bool, len(ecosystems))
+	for _, e := range ecosystems {
+		wanted[e] = true
+	}
+	var detectors []ArtifactDetector
+	for _, d := range DefaultArtifactDetectors() {
+		if wanted[d.Ecosystem] {
+			detectors = append(detectors, d)
+		}
+	}
+	return detectors
+}
+
+// ProjectArtifact is a build output directory found in a project
+type ProjectArtifact struct {
+	Path string `json:"path"`
+	// LinkPath is the symlink the artifact was found through, if any
+	LinkPath    string `json:"linkPath,omitempty"`
+	Name        string `json:"name"`
+	Ecosystem   string `json:"ecosystem"`
+	ProjectRoot string `json:"projectRoot"`
+	ProjectName string `json:"projectName"`
+	// Marker is the file that confirmed the artifact
+	Marker  string    `json:"marker,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// EcosystemArtifacts groups the artifacts of one ecosystem
+type EcosystemArtifacts struct {
+	Ecosystem string            `json:"ecosystem"`
+	Artifacts []ProjectArtifact `json:"artifacts"`
+	TotalSize int64             `json:"totalSize"`
+}
+
+// ArtifactsResult contains the results of scanning for project artifacts
+type ArtifactsResult struct {
+	Ecosystems   []EcosystemArtifacts `json:"ecosystems"`
+	TotalSize    int64                `json:"totalSize"`
+	TotalCount   int                  `json:"totalCount"`
+	ScanDuration time.Duration        `json:"scanDuration"`
+	Excluded     []Exclusion          `json:"excluded,omitempty"`
+}
+
+// Artifacts returns every artifact of the result, largest first
+func (r ArtifactsResult) Artifacts() []ProjectArtifact {
+	var all []ProjectArtifact
+	for _, group := range r.Ecosystems {
+		all = append(all, group.Artifacts...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Size > all[j].Size
+	})
+	return all
+}
+
+// ProjectSearch controls where project artifact scans look
+type ProjectSearch struct {
+	// Roots are the directories searched, DefaultProjectRoots when empty
+	// "~", $VARS and globs are expanded.
+	Roots []string `json:"roots"`
+	// MaxDepth is how many levels below a root artifacts are looked for,
+	// 0 for no limit
+	MaxDepth int `json:"maxDepth"`
+	// StopAtProject stops descending into a directory once an artifact is
+	// found in it, skipping nested packages of monorepos
+	StopAtProject bool `json:"stopAtProject"`
+}
+
+// DefaultProjectRoots returns the directories where projects are typically stored
+func DefaultProjectRoots() []string {
+	return []string{
+		"~",
+		"~/Documents",
+		"~/Projects",
+		"~/Developer",
+		"~/Code",
+		"~/Workspace",
+		"~/dev",
+		"~/repos",
+		"~/src",
+		"~/Sites",
+		"~/work",
+	}
+}
+
+// FindArtifacts scans the project roots for build artifacts recognized by
+// detectors, or by DefaultArtifactDetectors if nil
+func FindArtifacts(detectors []ArtifactDetector, search ProjectSearch, progressCallback func(current int, path string)) ArtifactsResult {
+	return FindArtifactsWithContext(context.Background(), detectors, search, progressCallback)
+}
+
+// FindArtifactsWithContext is FindArtifacts stopping once ctx is done,
+// with the artifacts found so far
+func FindArtifactsWithContext(ctx context.Context, detectors []ArtifactDetector, search ProjectSearch, progressCallback func(current int, path string)) ArtifactsResult {
+	startTime := time.Now()
+	if detectors == nil {
+		detectors = DefaultArtifactDetectors()
+	}
+	patterns := search.Roots
+	if len(patterns) == 0 {
+		patterns = DefaultProjectRoots()
+	}
+
+	w := &projectWalker{
+		ctx:       ctx,
+		detectors: detectors,
+		search:    search,
+		roots:     make(map[string]bool),
+		visited:   make(map[string]bool),
+		sem:       make(chan struct{}, 8),
+		progress:  progressCallback,
+	}
+	roots := ResolvePaths(patterns)
+	for _, root := range roots {
+		w.roots[root] = true
+	}
+
+	for _, root := range roots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		w.ex = CurrentExcluder().Rooted(root)
+		w.walk(root, 0)
+	}
+
+	w.wg.Wait()
+
+	artifacts := dropNestedArtifacts(w.artifacts)
+	return groupArtifacts(artifacts, time.Since(startTime), w.excluded.list())
+}
+
+// projectWalker searches project directories for artifacts and measures
+// the artifacts found in the background
+type projectWalker struct {
+	ctx       context.Context
+	detectors []ArtifactDetector
+	search    ProjectSearch
+	ex        *Excluder
+	// roots are skipped when met inside another root, they are walked on their own
+	roots map[string]bool
+	// visited guards against symlinks reaching the same artifact twice
+	visited  map[string]bool
+	excluded exclusionLog
+
+	sem       chan struct{}
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	artifacts []ProjectArtifact
+	progress  func(current int, path string)
+}
+
+// walk looks for artifacts in dir, depth levels below its root
+func (w *projectWalker) walk(dir string, depth int) {
+	if IsCancelled(w.ctx) {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	found := false
+	var subdirs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		isDir := entry.IsDir()
+		isLink := entry.Type()&os.ModeSymlink != 0
+		if !isDir && !isLink {
+			continue
+		}
+
+		// Skip excluded paths
+		if rule, ok := w.ex.Match(path, isDir); ok {
+			w.excluded.add(path, rule)
+			continue
+		}
+
+		if artifact, ok := detectArtifact(w.detectors, path, name, isLink); ok {
+			found = true
+			// Don't recurse into artifacts
+			w.measure(artifact)
+			continue
+		}
+
+		if isDir && !w.roots[path] && !skipProjectDir(name) {
+			subdirs = append(subdirs, path)
+		}
+	}
+
+	if found && w.search.StopAtProject {
+		return
+	}
+	if w.search.MaxDepth > 0 && depth+1 >= w.search.MaxDepth {
+		return
+	}
+	for _, subdir := range subdirs {
+		w.walk(subdir, depth+1)
+	}
+}
+
+// measure sizes an artifact in the background and records it
+func (w *projectWalker) measure(artifact ProjectArtifact) {
+	if w.visited[artifact.Path] {
+		return
+	}
+	w.visited[artifact.Path] = true
+
+	w.wg.Add(1)
+	w.sem <- struct{}{} // Acquire semaphore
+
+	go func() {
+		defer w.wg.Done()
+		defer func() { <-w.sem }() // Release semaphore
+
+		measureArtifact(&artifact)
+
+		w.mu.Lock()
+		w.artifacts = append(w.artifacts, artifact)
+		if w.progress != nil {
+			w.progress(len(w.artifacts), artifact.ProjectRoot)
+		}
+		w.mu.Unlock()
+	}()
+}
+
+// skipProjectDir reports whether a directory is unlikely to contain projects
+func skipProjectDir(name string) bool {
+	// Skip hidden directories
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+	switch name {
+	case "Library", "Applications", "Pictures", "Music", "Movies",
+		"Downloads", "Public", "Desktop",
+		"vendor", "Pods", "build", "dist", "target":
+		return true
+	}
+	return false
+}
+
+// detectArtifact returns the artifact at path if a detector confirms it
+func detectArtifact(detectors []ArtifactDetector, path, name string, isLink bool) (ProjectArtifact, bool) {
+	projectRoot := filepath.Dir(path)
+
+	for _, d := range detectors {
+		if !matchesAny(d.Names, name) || (isLink && !d.Symlinks) {
+			continue
+		}
+
+		artifactPath := path
+		if isLink {
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				continue
+			}
+			if targetInfo, err := os.Stat(target); err != nil || !targetInfo.IsDir() {
+				continue
+			}
+			artifactPath = target
+		}
+
+		marker, ok := findMarker(projectRoot, d.Markers)
+		if !ok {
+			continue
+		}
+		if len(d.Contains) > 0 {
+			if _, ok := findMarker(artifactPath, d.Contains); !ok {
+				continue
+			}
+		}
+
+		artifact := ProjectArtifact{
+			Path:        artifactPath,
+			Name:        name,
+			Ecosystem:   d.Ecosystem,
+			ProjectRoot: projectRoot,
+			ProjectName: projectName(projectRoot),
+			Marker:      marker,
+		}
+		if isLink {
+			artifact.LinkPath = path
+		}
+		return artifact, true
+	}
+	return ProjectArtifact{}, false
+}
+
+// matchesAny reports whether name matches one of the patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findMarker returns the first of the marker patterns present in dir
+// An empty marker list always matches.
+func findMarker(dir string, markers []string) (string, bool) {
+	if len(markers) == 0 {
+		return "", true
+	}
+	for _, marker := range markers {
+		if strings.ContainsAny(marker, "*?[") {
+			if matches, _ := filepath.Glob(filepath.Join(dir, marker)); len(matches) > 0 {
+				return filepath.Base(matches[0]), true
+			}
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return marker, true
+		}
+	}
+	return "", false
+}
+
+// projectName returns the package.json name of a project, or its directory name
+func projectName(projectRoot string) string {
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "package.json")); err == nil {
+		var pkgJSON struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &pkgJSON) == nil && pkgJSON.Name != "" {
+			return pkgJSON.Name
+		}
+	}
+	return filepath.Base(projectRoot)
+}
+
+// measureArtifact fills in the size and modification time of an artifact
+func measureArtifact(artifact *ProjectArtifact) {
+	// Using 4 workers for speed
+	artifact.Size = WalkDirectoryFast(artifact.Path, 4).Size
+
+	if info, err := os.Stat(artifact.Path); err == nil {
+		artifact.ModTime = info.ModTime()
+	}
+}
+
+// dropNestedArtifacts removes artifacts inside other artifacts, which
+// happens when several symlinks point into the same output tree
+func dropNestedArtifacts(artifacts []ProjectArtifact) []ProjectArtifact {
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Path < artifacts[j].Path
+	})
+
+	kept := artifacts[:0]
+	for _, artifact := range artifacts {
+		if n := len(kept); n > 0 && isWithin(artifact.Path, kept[n-1].Path) {
+			continue
+		}
+		kept = append(kept, artifact)
+	}
+	return kept
+}
+
+// isWithin reports whether path is inside dir
+func isWithin(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// groupArtifacts builds the result from the artifacts found, grouped by
+// ecosystem with the largest groups and artifacts first
+func groupArtifacts(artifacts []ProjectArtifact, duration time.Duration, excluded []Exclusion) ArtifactsResult {
+	result := ArtifactsResult{
+		Ecosystems:   []EcosystemArtifacts{},
+		TotalCount:   len(artifacts),
+		ScanDuration: duration,
+		Excluded:     excluded,
+	}
+
+	index := make(map[string]int)
+	for _, artifact := range artifacts {
+		i, ok := index[artifact.Ecosystem]
+		if !ok {
+			i = len(result.Ecosystems)
+			index[artifact.Ecosystem] = i
+			result.Ecosystems = append(result.Ecosystems, EcosystemArtifacts{Ecosystem: artifact.Ecosystem})
+		}
+		result.Ecosystems[i].Artifacts = append(result.Ecosystems[i].Artifacts, artifact)
+		result.Ecosystems[i].TotalSize += artifact.Size
+		result.TotalSize += artifact.Size
+	}
+
+	for _, group := range result.Ecosystems {
+		sort.Slice(group.Artifacts, func(i, j int) bool {
+			return group.Artifacts[i].Size > group.Artifacts[j].Size
+		})
+	}
+	sort.Slice(result.Ecosystems, func(i, j int) bool {
+		return result.Ecosystems[i].TotalSize > result.Ecosystems[j].TotalSize
+	})
+	return result
+}
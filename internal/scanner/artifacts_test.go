@@ -0,0 +1,155 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+func TestFindArtifacts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	projects := filepath.Join(home, "Projects")
+
+	write := func(path string, size int) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Confirmed by a marker next to the artifact
+	write(filepath.Join(projects, "crate", "Cargo.toml"), 10)
+	write(filepath.Join(projects, "crate", "target", "debug", "crate"), 4096)
+	write(filepath.Join(projects, "app", "build.gradle.kts"), 10)
+	write(filepath.Join(projects, "app", "build", "libs", "app.jar"), 4096)
+	write(filepath.Join(projects, "app", ".gradle", "cache.bin"), 4096)
+	write(filepath.Join(projects, "infra", "main.tf"), 10)
+	write(filepath.Join(projects, "infra", ".terraform", "providers", "aws"), 4096)
+	// Confirmed by a file inside the artifact
+	write(filepath.Join(projects, "tool", ".venv", "pyvenv.cfg"), 10)
+	write(filepath.Join(projects, "tool", ".venv", "lib", "site.py"), 4096)
+	// Confirmed by name alone
+	write(filepath.Join(projects, "web", "node_modules", "react", "index.js"), 4096)
+	write(filepath.Join(projects, "web", "packages", "ui", "node_modules", "vue", "index.js"), 4096)
+
+	// Not confirmed: no marker, or no pyvenv.cfg
+	write(filepath.Join(projects, "cproject", "build", "main.o"), 4096)
+	write(filepath.Join(projects, "notes", "target", "todo.txt"), 4096)
+	write(filepath.Join(projects, "tool", "venv", "notes.txt"), 4096)
+
+	want := []string{
+		filepath.Join(projects, "app", ".gradle"),
+		filepath.Join(projects, "app", "build"),
+		filepath.Join(projects, "crate", "target"),
+		filepath.Join(projects, "infra", ".terraform"),
+		filepath.Join(projects, "tool", ".venv"),
+		filepath.Join(projects, "web", "node_modules"),
+		filepath.Join(projects, "web", "packages", "ui", "node_modules"),
+	}
+
+	if runtime.GOOS != "windows" {
+		// Bazel convenience links point into the output base; the links
+		// into the same tree collapse into the outermost target
+		write(filepath.Join(projects, "mono", "MODULE.bazel"), 10)
+		execroot := filepath.Join(home, ".cache", "bazel", "execroot", "mono")
+		write(filepath.Join(execroot, "bazel-out", "k8-fastbuild", "bin", "tool"), 4096)
+		links := map[string]string{
+			"bazel-mono": execroot,
+			"bazel-out":  filepath.Join(execroot, "bazel-out"),
+			"bazel-bin":  filepath.Join(execroot, "bazel-out", "k8-fastbuild", "bin"),
+		}
+		for name, target := range links {
+			if err := os.Symlink(target, filepath.Join(projects, "mono", name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want = append(want, execroot)
+	}
+
+	result := FindArtifacts(nil, ProjectSearch{}, nil)
+
+	var got []string
+	ecosystems := make(map[string]int)
+	for _, artifact := range result.Artifacts() {
+		got = append(got, artifact.Path)
+		ecosystems[artifact.Ecosystem]++
+		if artifact.Size < 4096 {
+			t.Errorf("%s size = %d, want at least 4096", artifact.Path, artifact.Size)
+		}
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+
+	if len(got) != len(want) {
+		t.Fatalf("found %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("artifact %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	if result.TotalCount != len(want) {
+		t.Errorf("TotalCount = %d, want %d", result.TotalCount, len(want))
+	}
+	if ecosystems[EcosystemGradle] != 2 || ecosystems[EcosystemRust] != 1 {
+		t.Errorf("ecosystems = %v, want 2 gradle and 1 rust", ecosystems)
+	}
+	for _, group := range result.Ecosystems {
+		for _, artifact := range group.Artifacts {
+			if artifact.Ecosystem != group.Ecosystem {
+				t.Errorf("%s grouped under %s", artifact.Path, group.Ecosystem)
+			}
+		}
+	}
+
+	t.Run("node_modules only", func(t *testing.T) {
+		nodeModules := FindNodeModules(ProjectSearch{}, nil)
+		if nodeModules.TotalCount != 2 {
+			t.Errorf("FindNodeModules = %+v, want the two web node_modules", nodeModules.Projects)
+		}
+	})
+
+	search := func(t *testing.T, search ProjectSearch) []string {
+		t.Helper()
+		var paths []string
+		for _, artifact := range FindArtifacts(nil, search, nil).Artifacts() {
+			rel, _ := filepath.Rel(projects, artifact.Path)
+			paths = append(paths, rel)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	t.Run("roots", func(t *testing.T) {
+		t.Setenv("PROJECTS", projects)
+		got := search(t, ProjectSearch{Roots: []string{"$PROJECTS/crate", "~/Projects/in*"}})
+		if len(got) != 2 || got[0] != filepath.Join("crate", "target") || got[1] != filepath.Join("infra", ".terraform") {
+			t.Errorf("found %v, want crate/target and infra/.terraform", got)
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		got := search(t, ProjectSearch{Roots: []string{projects}, MaxDepth: 1})
+		if len(got) != 0 {
+			t.Errorf("depth 1 found %v, want nothing", got)
+		}
+		got = search(t, ProjectSearch{Roots: []string{filepath.Join(projects, "web")}, MaxDepth: 1})
+		if len(got) != 1 || got[0] != filepath.Join("web", "node_modules") {
+			t.Errorf("depth 1 in web found %v, want web/node_modules", got)
+		}
+	})
+
+	t.Run("stop at project", func(t *testing.T) {
+		got := search(t, ProjectSearch{Roots: []string{filepath.Join(projects, "web")}, StopAtProject: true})
+		if len(got) != 1 || got[0] != filepath.Join("web", "node_modules") {
+			t.Errorf("found %v, want only web/node_modules", got)
+		}
+	})
+}
@@ -0,0 +1,16 @@
+package scanner
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// accessTime returns the last access time of a file, or its mtime if the
+// platform doesn't expose one
+func accessTime(info os.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Atim.Unix())
+	}
+	return info.ModTime()
+}
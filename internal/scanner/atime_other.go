@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package scanner
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime returns the last access time of a file, or its mtime if the
+// platform doesn't expose one
+func accessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+
+	"disk-peek/internal/xdg"
 )
 
 // Platform constants
@@ -14,7 +16,28 @@ const (
 )
 
 // GetCategories returns all dev cache categories with their paths for the current platform
+// User-defined categories from the categories file are merged in; if that
+// file is invalid it is ignored and only the built-in categories are returned
 func GetCategories() []Category {
+	categories := getBuiltinCategories()
+
+	path, err := CustomCategoriesPath()
+	if err != nil {
+		return categories
+	}
+	custom, err := LoadCustomCategories(path)
+	if err != nil {
+		return categories
+	}
+	merged, err := MergeCategories(categories, custom)
+	if err != nil {
+		return categories
+	}
+	return merged
+}
+
+// getBuiltinCategories returns the predefined categories for the current platform
+func getBuiltinCategories() []Category {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return []Category{}
@@ -81,6 +104,10 @@ func getCrossPlatformCategories(home string) []Category {
 			Icon:        "package",
 			Color:       "#00add8",
 			Paths:       goModCache(home),
+			// One item per module@version, e.g. golang.org/x/sys@v0.30.0, so
+			// versions no longer used can be cleaned by age. The @v
+			// directories of the download cache are not modules.
+			ItemPattern: "?*@v?*",
 		},
 		{
 			ID:          "gradle",
@@ -98,6 +125,14 @@ func getCrossPlatformCategories(home string) []Category {
 			Color:       "#c71a36",
 			Paths:       []string{filepath.Join(home, ".m2", "repository")},
 		},
+		{
+			ID:          "jetbrains",
+			Name:        "JetBrains",
+			Description: "JetBrains IDE caches, one per product version",
+			Icon:        "code",
+			Color:       "#fe2857",
+			Paths:       getJetBrainsCachePaths(home),
+		},
 		{
 			ID:          "android",
 			Name:        "Android",
@@ -177,7 +212,7 @@ func getYarnCachePaths(home string) []string {
 	case PlatformMacOS:
 		return []string{filepath.Join(home, "Library", "Caches", "Yarn")}
 	case PlatformLinux:
-		return []string{filepath.Join(home, ".cache", "yarn")}
+		return []string{filepath.Join(xdg.CacheHome(), "yarn")}
 	case PlatformWindows:
 		localAppData := os.Getenv("LOCALAPPDATA")
 		if localAppData != "" {
@@ -198,7 +233,7 @@ func getPnpmCachePaths(home string) []string {
 			filepath.Join(home, ".local", "share", "pnpm"),
 		}
 	case PlatformLinux:
-		return []string{filepath.Join(home, ".local", "share", "pnpm")}
+		return []string{filepath.Join(xdg.DataHome(), "pnpm")}
 	case PlatformWindows:
 		localAppData := os.Getenv("LOCALAPPDATA")
 		if localAppData != "" {
@@ -210,6 +245,18 @@ func getPnpmCachePaths(home string) []string {
 	}
 }
 
+// getJetBrainsCachePaths returns the per-version JetBrains cache directories
+func getJetBrainsCachePaths(home string) []string {
+	switch runtime.GOOS {
+	case PlatformMacOS:
+		return []string{filepath.Join(home, "Library", "Caches", "JetBrains", "*")}
+	case PlatformWindows:
+		return []string{filepath.Join("${LOCALAPPDATA:-"+filepath.Join(home, "AppData", "Local")+"}", "JetBrains", "*")}
+	default:
+		return []string{filepath.Join(xdg.CacheHome(), "JetBrains", "*")}
+	}
+}
+
 // getMacOSCategories returns macOS-specific categories
 func getMacOSCategories(home string) []Category {
 	return []Category{
@@ -331,7 +378,11 @@ func getMacOSCategories(home string) []Category {
 }
 
 // getLinuxCategories returns Linux-specific categories
+// Cache and data locations honor $XDG_CACHE_HOME and $XDG_DATA_HOME
 func getLinuxCategories(home string) []Category {
+	cacheHome := xdg.CacheHome()
+	dataHome := xdg.DataHome()
+
 	return []Category{
 		{
 			ID:          "docker",
@@ -366,7 +417,7 @@ func getLinuxCategories(home string) []Category {
 			Description: "Application caches",
 			Icon:        "hard-drive",
 			Color:       "#6b7280",
-			Paths:       []string{filepath.Join(home, ".cache")},
+			Paths:       []string{cacheHome},
 		},
 		{
 			ID:          "system-logs",
@@ -374,7 +425,7 @@ func getLinuxCategories(home string) []Category {
 			Description: "Application logs",
 			Icon:        "file-text",
 			Color:       "#9ca3af",
-			Paths:       []string{filepath.Join(home, ".local", "share", "logs")},
+			Paths:       []string{filepath.Join(dataHome, "logs")},
 		},
 		{
 			ID:          "thumbnails",
@@ -382,7 +433,7 @@ func getLinuxCategories(home string) []Category {
 			Description: "Cached image thumbnails",
 			Icon:        "image",
 			Color:       "#a855f7",
-			Paths:       []string{filepath.Join(home, ".cache", "thumbnails")},
+			Paths:       []string{filepath.Join(cacheHome, "thumbnails")},
 		},
 		{
 			ID:          "trash",
@@ -390,7 +441,7 @@ func getLinuxCategories(home string) []Category {
 			Description: "Files in trash",
 			Icon:        "trash-2",
 			Color:       "#ef4444",
-			Paths:       []string{filepath.Join(home, ".local", "share", "Trash")},
+			Paths:       []string{filepath.Join(dataHome, "Trash")},
 		},
 	}
 }
@@ -0,0 +1,193 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"disk-peek/internal/xdg"
+)
+
+const (
+	customCategoriesFile = "categories.json"
+	defaultCustomIcon    = "folder"
+	defaultCustomColor   = "#64748b"
+)
+
+// CustomCategory is a user-defined category as declared in the categories file
+//
+// Example categories.json:
+//
+//	{
+//	  "categories": [
+//	    {"id": "internal", "name": "Internal Tools", "icon": "wrench"},
+//	    {"id": "bazel", "name": "Bazel", "parent": "internal",
+//	     "paths": ["~/.cache/bazel"]},
+//	    {"id": "models", "name": "ML Models", "paths": ["$HF_HOME/hub", "~/models/*"]}
+//	  ]
+//	}
+type CustomCategory struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	Color       string   `json:"color,omitempty"`
+	Paths       []string `json:"paths,omitempty"`
+	// Parent is the ID of a built-in or custom category to nest under
+	Parent string `json:"parent,omitempty"`
+}
+
+// customCategoriesDocument is the on-disk layout of the categories file
+type customCategoriesDocument struct {
+	Categories []CustomCategory `json:"categories"`
+}
+
+// CustomCategoriesPath returns the location of the user's categories file
+func CustomCategoriesPath() (string, error) {
+	return xdg.ConfigFile(customCategoriesFile)
+}
+
+// LoadCustomCategories reads user-defined categories from a JSON file
+// A missing file is not an error and yields no categories
+func LoadCustomCategories(path string) ([]CustomCategory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var doc customCategoriesDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc.Categories, nil
+}
+
+// MergeCategories merges user-defined categories into the built-in tree
+// Custom categories are appended at the top level or nested under their
+// parent. It fails if an ID collides with an existing category, a parent
+// does not exist, or a parent would lose its own paths by gaining children.
+func MergeCategories(builtin []Category, custom []CustomCategory) ([]Category, error) {
+	if len(custom) == 0 {
+		return builtin, nil
+	}
+
+	seen := make(map[string]bool)
+	var collectIDs func(cats []Category)
+	collectIDs = func(cats []Category) {
+		for _, cat := range cats {
+			seen[cat.ID] = true
+			collectIDs(cat.Children)
+		}
+	}
+	collectIDs(builtin)
+
+	// Validate every entry before touching the tree
+	childCount := make(map[string]int)
+	for i, c := range custom {
+		switch {
+		case c.ID == "":
+			return nil, fmt.Errorf("custom category #%d: missing id", i+1)
+		case c.Name == "":
+			return nil, fmt.Errorf("custom category %q: missing name", c.ID)
+		case seen[c.ID]:
+			return nil, fmt.Errorf("custom category %q: id already in use", c.ID)
+		case c.Parent == c.ID:
+			return nil, fmt.Errorf("custom category %q: cannot be its own parent", c.ID)
+		}
+		seen[c.ID] = true
+		if c.Parent != "" {
+			childCount[c.Parent]++
+		}
+	}
+	for _, c := range custom {
+		if len(c.Paths) == 0 && childCount[c.ID] == 0 {
+			return nil, fmt.Errorf("custom category %q: needs paths or child categories", c.ID)
+		}
+		if len(c.Paths) > 0 && childCount[c.ID] > 0 {
+			return nil, fmt.Errorf("custom category %q: cannot have both paths and child categories", c.ID)
+		}
+	}
+
+	merged := cloneCategories(builtin)
+
+	// Attach categories whose parent is already in the tree until none are
+	// left. Anything remaining after a pass without progress has a missing
+	// parent or is part of a cycle.
+	pending := custom
+	for len(pending) > 0 {
+		var next []CustomCategory
+		for _, c := range pending {
+			cat := c.toCategory()
+			if c.Parent == "" {
+				merged = append(merged, cat)
+				continue
+			}
+			parent := GetCategoryByID(merged, c.Parent)
+			if parent == nil {
+				next = append(next, c)
+				continue
+			}
+			if len(parent.Paths) > 0 {
+				return nil, fmt.Errorf("custom category %q: parent %q has its own paths", c.ID, c.Parent)
+			}
+			parent.Children = append(parent.Children, cat)
+		}
+		if len(next) == len(pending) {
+			return nil, fmt.Errorf("custom category %q: unknown parent %q", next[0].ID, next[0].Parent)
+		}
+		pending = next
+	}
+
+	return merged, nil
+}
+
+// toCategory converts a custom category into a scannable Category
+func (c CustomCategory) toCategory() Category {
+	cat := Category{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		Icon:        c.Icon,
+		Color:       c.Color,
+	}
+	if cat.Icon == "" {
+		cat.Icon = defaultCustomIcon
+	}
+	if cat.Color == "" {
+		cat.Color = defaultCustomColor
+	}
+	// Paths are kept as patterns and resolved at scan time
+	cat.Paths = append([]string(nil), c.Paths...)
+	return cat
+}
+
+// cloneCategories deep-copies a category tree so it can be modified
+func cloneCategories(categories []Category) []Category {
+	if categories == nil {
+		return nil
+	}
+	cloned := make([]Category, len(categories))
+	for i, cat := range categories {
+		cloned[i] = cat
+		cloned[i].Children = cloneCategories(cat.Children)
+	}
+	return cloned
+}
+
+// ValidateCustomCategories loads the user's categories file and checks it
+// against the built-in categories without applying it
+func ValidateCustomCategories() error {
+	path, err := CustomCategoriesPath()
+	if err != nil {
+		return err
+	}
+	custom, err := LoadCustomCategories(path)
+	if err != nil {
+		return err
+	}
+	_, err = MergeCategories(getBuiltinCategories(), custom)
+	return err
+}
@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCustomCategories(t *testing.T) {
+	t.Run("missing file yields no categories", func(t *testing.T) {
+		custom, err := LoadCustomCategories(filepath.Join(t.TempDir(), "categories.json"))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(custom) != 0 {
+			t.Errorf("expected no categories, got %d", len(custom))
+		}
+	})
+
+	t.Run("parses categories", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "categories.json")
+		data := `{"categories": [{"id": "bazel", "name": "Bazel", "paths": ["~/.cache/bazel"]}]}`
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		custom, err := LoadCustomCategories(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(custom) != 1 || custom[0].ID != "bazel" {
+			t.Errorf("unexpected categories: %+v", custom)
+		}
+	})
+
+	t.Run("invalid JSON is an error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "categories.json")
+		if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCustomCategories(path); err == nil {
+			t.Error("expected error for invalid JSON")
+		}
+	})
+}
+
+func TestMergeCategories(t *testing.T) {
+	builtin := []Category{
+		{ID: "node", Name: "Node.js", Children: []Category{
+			{ID: "npm-cache", Name: "npm Cache", Paths: []string{"/npm"}},
+		}},
+		{ID: "go", Name: "Go", Paths: []string{"/go"}},
+	}
+
+	t.Run("appends top-level and nested categories", func(t *testing.T) {
+		merged, err := MergeCategories(builtin, []CustomCategory{
+			{ID: "bun-cache", Name: "Bun Cache", Parent: "node", Paths: []string{"/bun"}},
+			{ID: "bazel", Name: "Bazel", Paths: []string{"/bazel"}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(merged) != 3 {
+			t.Fatalf("expected 3 top-level categories, got %d", len(merged))
+		}
+
+		bun := GetCategoryByID(merged, "bun-cache")
+		if bun == nil {
+			t.Fatal("bun-cache not merged")
+		}
+		if bun.Icon != defaultCustomIcon || bun.Color != defaultCustomColor {
+			t.Errorf("defaults not applied: icon=%s color=%s", bun.Icon, bun.Color)
+		}
+		if len(GetCategoryByID(merged, "node").Children) != 2 {
+			t.Error("bun-cache should be nested under node")
+		}
+		if len(builtin[0].Children) != 1 {
+			t.Error("built-in tree should not be modified")
+		}
+	})
+
+	t.Run("custom parents may be declared after their children", func(t *testing.T) {
+		merged, err := MergeCategories(builtin, []CustomCategory{
+			{ID: "models", Name: "Models", Parent: "internal", Paths: []string{"/models"}},
+			{ID: "internal", Name: "Internal"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		internal := GetCategoryByID(merged, "internal")
+		if internal == nil || len(internal.Children) != 1 {
+			t.Errorf("expected internal with one child, got %+v", internal)
+		}
+	})
+
+	errorCases := []struct {
+		name   string
+		custom []CustomCategory
+	}{
+		{"missing id", []CustomCategory{{Name: "X", Paths: []string{"/x"}}}},
+		{"missing name", []CustomCategory{{ID: "x", Paths: []string{"/x"}}}},
+		{"collides with built-in", []CustomCategory{{ID: "npm-cache", Name: "X", Paths: []string{"/x"}}}},
+		{"collides with custom", []CustomCategory{
+			{ID: "x", Name: "X", Paths: []string{"/x"}},
+			{ID: "x", Name: "Y", Paths: []string{"/y"}},
+		}},
+		{"unknown parent", []CustomCategory{{ID: "x", Name: "X", Parent: "nope", Paths: []string{"/x"}}}},
+		{"parent has paths", []CustomCategory{{ID: "x", Name: "X", Parent: "go", Paths: []string{"/x"}}}},
+		{"no paths or children", []CustomCategory{{ID: "x", Name: "X"}}},
+		{"cycle", []CustomCategory{
+			{ID: "a", Name: "A", Parent: "b"},
+			{ID: "b", Name: "B", Parent: "a"},
+		}},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := MergeCategories(builtin, tc.custom); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
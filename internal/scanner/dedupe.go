@@ -0,0 +1,283 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Errors that stop a duplicate from being replaced by a link
+var (
+	ErrContentChanged     = errors.New("content differs from the kept file")
+	ErrCrossDevice        = errors.New("not on the same filesystem as the kept file")
+	ErrReflinkUnsupported = errors.New("filesystem does not support reflinks")
+)
+
+// DedupeDuplicates replaces the duplicates of each group with links to the
+// file at keepIndex, so every path stays where it is while the data is
+// stored once. action is ActionReflink, which gives each path its own
+// copy-on-write clone (btrfs, XFS), or ActionHardlink, which makes the
+// paths the same file. Each duplicate is compared byte for byte with what
+// replaces it right before the swap.
+// If dryRun is true, the duplicates are only checked and nothing changes;
+// whether reflinks work is tried once per filesystem on a temporary clone.
+func DedupeDuplicates(groups []DuplicateGroup, keepIndex int, action string, dryRun bool) CleanResult {
+	return DedupeDuplicatesWithContext(context.Background(), groups, keepIndex, action, dryRun)
+}
+
+// DedupeDuplicatesWithContext is DedupeDuplicates stopping between files
+// once ctx is done; the result covers the files handled until then
+func DedupeDuplicatesWithContext(ctx context.Context, groups []DuplicateGroup, keepIndex int, action string, dryRun bool) CleanResult {
+	result := CleanResult{
+		FreedBytes:     0,
+		DeletedPaths:   []string{},
+		Errors:         []string{},
+		DetailedErrors: []CleanError{},
+		DryRun:         dryRun,
+		DedupedPaths:   []string{},
+	}
+	if dryRun {
+		result.Plan = []PlannedDeletion{}
+	}
+	probes := make(reflinkProbes)
+
+	for _, group := range groups {
+		if len(group.Files) == 0 {
+			continue
+		}
+		keepIdx := keptIndex(group, keepIndex)
+		keep := group.Files[keepIdx].Path
+
+		for i, file := range group.Files {
+			if IsCancelled(ctx) {
+				return result
+			}
+			if i == keepIdx {
+				continue
+			}
+
+			freed, err := dedupeFile(keep, file.Path, action, dryRun, probes)
+			var cleanErr *CleanError
+			if err != nil {
+				cleanErr = &CleanError{
+					Path:    file.Path,
+					Message: err.Error(),
+					Code:    dedupeErrorCode(err),
+				}
+				result.Errors = append(result.Errors, err.Error())
+				result.DetailedErrors = append(result.DetailedErrors, *cleanErr)
+			} else {
+				result.FreedBytes += freed
+				result.DedupedPaths = append(result.DedupedPaths, file.Path)
+			}
+
+			if dryRun {
+				result.Plan = append(result.Plan, PlannedDeletion{
+					Path:   file.Path,
+					Size:   freed,
+					Action: action,
+					Error:  cleanErr,
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// dedupeFile replaces dup with a link to keep and returns the bytes this
+// frees. Nothing is freed when dup has other hardlinks holding its data.
+// Dry runs of reflinks try a clone once per filesystem, remembered in probes.
+func dedupeFile(keep, dup, action string, dryRun bool, probes reflinkProbes) (int64, error) {
+	if action != ActionReflink && action != ActionHardlink {
+		return 0, fmt.Errorf("unknown dedupe action %q", action)
+	}
+
+	keepInfo, err := os.Stat(keep)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Lstat(dup)
+	if err != nil {
+		return 0, err
+	}
+	if !info.Mode().IsRegular() {
+		return 0, fmt.Errorf("%s is not a regular file", dup)
+	}
+	if os.SameFile(keepInfo, info) {
+		return 0, nil // Already a hardlink of the kept file
+	}
+
+	keepStat, ok1 := keepInfo.Sys().(*syscall.Stat_t)
+	stat, ok2 := info.Sys().(*syscall.Stat_t)
+	if !ok1 || !ok2 {
+		return 0, fmt.Errorf("%s: file identity is not available", dup)
+	}
+	if keepStat.Dev != stat.Dev {
+		return 0, ErrCrossDevice
+	}
+	var freed int64
+	if stat.Nlink <= 1 {
+		freed = info.Size()
+	}
+
+	if dryRun {
+		if action == ActionReflink {
+			if !reflinkAvailable {
+				return 0, ErrReflinkUnsupported
+			}
+			if err := probes.check(uint64(stat.Dev), keep, dup); err != nil {
+				return 0, err
+			}
+		}
+		same, err := sameContent(keep, dup)
+		if err != nil {
+			return 0, err
+		}
+		if !same {
+			return 0, ErrContentChanged
+		}
+		return freed, nil
+	}
+
+	if err := replaceWithLink(keep, dup, info, action); err != nil {
+		return 0, err
+	}
+	return freed, nil
+}
+
+// reflinkProbes remembers, by device, whether a filesystem can clone files
+type reflinkProbes map[uint64]error
+
+// check clones keep to a temporary file next to dup, unless the filesystem
+// dev was already tried, and reports ErrReflinkUnsupported if it can't.
+// Only the outcome of the clone is remembered; failing to create the file
+// is a problem of dup's directory, not of the filesystem.
+func (p reflinkProbes) check(dev uint64, keep, dup string) error {
+	if err, ok := p[dev]; ok {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dup), "."+filepath.Base(dup)+".probe-*")
+	if err != nil {
+		return err
+	}
+	err = reflink(keep, tmp)
+	tmp.Close()
+	os.Remove(tmp.Name())
+
+	if err == nil || errors.Is(err, ErrReflinkUnsupported) {
+		p[dev] = err
+	}
+	return err
+}
+
+// replaceWithLink links or clones keep to a temporary file next to dup,
+// checks it still matches dup and renames it over dup, so dup's path never
+// goes missing
+func replaceWithLink(keep, dup string, info os.FileInfo, action string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dup), "."+filepath.Base(dup)+".dedupe-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if action == ActionHardlink {
+		tmp.Close()
+		if err = os.Remove(tmpPath); err == nil {
+			err = os.Link(keep, tmpPath)
+		}
+	} else {
+		err = reflink(keep, tmp)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = copyMetadata(tmpPath, info)
+		}
+	}
+
+	if err == nil {
+		var same bool
+		if same, err = sameContent(tmpPath, dup); err == nil && !same {
+			err = ErrContentChanged
+		}
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, dup)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+	return err
+}
+
+// copyMetadata gives a reflinked clone the mode, owner and times of the
+// file it replaces. Changing the owner needs privileges and is best effort.
+func copyMetadata(path string, info os.FileInfo) error {
+	if err := os.Chmod(path, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		_ = os.Lchown(path, int(stat.Uid), int(stat.Gid))
+	}
+	return os.Chtimes(path, time.Time{}, info.ModTime())
+}
+
+// sameContent compares two files byte for byte
+func sameContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	bufA := make([]byte, 64*1024)
+	bufB := make([]byte, 64*1024)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		endA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		endB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if errA != nil && !endA {
+			return false, errA
+		}
+		if errB != nil && !endB {
+			return false, errB
+		}
+		if endA || endB {
+			return endA && endB, nil
+		}
+	}
+}
+
+// dedupeErrorCode maps a dedupe error to a CleanError code
+func dedupeErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrContentChanged):
+		return "CONTENT_CHANGED"
+	case errors.Is(err, ErrCrossDevice):
+		return "CROSS_DEVICE"
+	case errors.Is(err, ErrReflinkUnsupported):
+		return "UNSUPPORTED"
+	case os.IsPermission(err):
+		return "PERMISSION_DENIED"
+	case os.IsNotExist(err):
+		return "NOT_FOUND"
+	}
+	return "DEDUPE_FAILED"
+}
@@ -0,0 +1,31 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflinkAvailable reports whether the platform can clone files
+const reflinkAvailable = true
+
+// reflink makes dst a copy-on-write clone of src with the FICLONE ioctl
+func reflink(src string, dst *os.File) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = unix.IoctlFileClone(int(dst.Fd()), int(f.Fd()))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, unix.EOPNOTSUPP), errors.Is(err, unix.ENOTTY),
+		errors.Is(err, unix.EINVAL), errors.Is(err, unix.EXDEV):
+		// Filesystems without reflinks reject the ioctl in one of these ways
+		return ErrReflinkUnsupported
+	}
+	return err
+}
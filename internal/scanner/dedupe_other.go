@@ -0,0 +1,13 @@
+//go:build !linux
+
+package scanner
+
+import "os"
+
+// reflinkAvailable reports whether the platform can clone files
+const reflinkAvailable = false
+
+// reflink is only implemented on Linux
+func reflink(src string, dst *os.File) error {
+	return ErrReflinkUnsupported
+}
@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// dedupeGroup writes identical files and returns them as a duplicate group
+func dedupeGroup(t *testing.T, data []byte, names ...string) DuplicateGroup {
+	t.Helper()
+	root := t.TempDir()
+	group := DuplicateGroup{Size: int64(len(data))}
+	for _, name := range names {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		group.Files = append(group.Files, DuplicateFile{Path: path, Name: name, Size: int64(len(data))})
+	}
+	return group
+}
+
+func TestDedupeDuplicatesHardlink(t *testing.T) {
+	data := bytes.Repeat([]byte("disk-peek "), 10000)
+	group := dedupeGroup(t, data, "a.bin", "b.bin", "c.bin")
+	groups := []DuplicateGroup{group}
+
+	plan := DedupeDuplicates(groups, 0, ActionHardlink, true)
+	if len(plan.Plan) != 2 || plan.FreedBytes != 2*int64(len(data)) {
+		t.Fatalf("dry run = %+v, want 2 planned links freeing %d", plan, 2*len(data))
+	}
+
+	result := DedupeDuplicates(groups, 0, ActionHardlink, false)
+	if len(result.DetailedErrors) > 0 {
+		t.Fatalf("errors: %+v", result.DetailedErrors)
+	}
+	if len(result.DedupedPaths) != 2 || result.FreedBytes != 2*int64(len(data)) {
+		t.Errorf("result = %+v, want 2 deduped paths freeing %d", result, 2*len(data))
+	}
+
+	keep, err := os.Stat(group.Files[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range group.Files[1:] {
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			t.Fatalf("%s is gone: %v", file.Name, err)
+		}
+		if !os.SameFile(keep, info) {
+			t.Errorf("%s is not a hardlink of the kept file", file.Name)
+		}
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(group.Files[0].Path)); len(entries) != 3 {
+		t.Errorf("directory holds %d entries, want no leftover temporary files", len(entries))
+	}
+
+	// Linked files are skipped and free nothing more
+	again := DedupeDuplicates(groups, 0, ActionHardlink, false)
+	if again.FreedBytes != 0 || len(again.DetailedErrors) > 0 {
+		t.Errorf("second run = %+v, want nothing freed", again)
+	}
+}
+
+func TestDedupeDuplicatesContentChanged(t *testing.T) {
+	data := bytes.Repeat([]byte{7}, 4096)
+	group := dedupeGroup(t, data, "a.bin", "b.bin")
+
+	changed := append([]byte(nil), data...)
+	changed[100] = 8
+	if err := os.WriteFile(group.Files[1].Path, changed, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := DedupeDuplicates([]DuplicateGroup{group}, 0, ActionHardlink, false)
+	if len(result.DetailedErrors) != 1 || result.DetailedErrors[0].Code != "CONTENT_CHANGED" {
+		t.Fatalf("errors = %+v, want CONTENT_CHANGED", result.DetailedErrors)
+	}
+	got, err := os.ReadFile(group.Files[1].Path)
+	if err != nil || !bytes.Equal(got, changed) {
+		t.Errorf("changed file was replaced")
+	}
+}
+
+func TestDedupeDuplicatesReflink(t *testing.T) {
+	data := bytes.Repeat([]byte("clone me "), 8000)
+	group := dedupeGroup(t, data, "a.bin", "b.bin")
+	if err := os.Chmod(group.Files[1].Path, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result := DedupeDuplicates([]DuplicateGroup{group}, 0, ActionReflink, false)
+	if len(result.DetailedErrors) == 1 && result.DetailedErrors[0].Code == "UNSUPPORTED" {
+		t.Skip("the temporary directory does not support reflinks")
+	}
+	if len(result.DetailedErrors) > 0 {
+		t.Fatalf("errors: %+v", result.DetailedErrors)
+	}
+	if result.FreedBytes != int64(len(data)) {
+		t.Errorf("freed %d, want %d", result.FreedBytes, len(data))
+	}
+
+	keep, _ := os.Stat(group.Files[0].Path)
+	info, err := os.Stat(group.Files[1].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.SameFile(keep, info) {
+		t.Errorf("reflink produced a hardlink")
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want the replaced file's 0600", info.Mode().Perm())
+	}
+	if got, _ := os.ReadFile(group.Files[1].Path); !bytes.Equal(got, data) {
+		t.Errorf("clone content differs")
+	}
+}
+
+func TestDedupeDuplicatesReflinkDryRun(t *testing.T) {
+	data := bytes.Repeat([]byte("clone me "), 8000)
+	group := dedupeGroup(t, data, "a.bin", "b.bin", "c.bin")
+
+	// The dry run tries a clone, so it agrees with the real run
+	plan := DedupeDuplicates([]DuplicateGroup{group}, 0, ActionReflink, true)
+	result := DedupeDuplicates([]DuplicateGroup{group}, 0, ActionReflink, false)
+	if len(plan.Plan) != 2 {
+		t.Fatalf("plan = %+v, want 2 planned files", plan.Plan)
+	}
+	for i, planned := range plan.Plan {
+		var wantCode string
+		for _, cleanErr := range result.DetailedErrors {
+			if cleanErr.Path == planned.Path {
+				wantCode = cleanErr.Code
+			}
+		}
+		var code string
+		if planned.Error != nil {
+			code = planned.Error.Code
+		}
+		if code != wantCode {
+			t.Errorf("planned %d with error %q, the real run got %q", i, code, wantCode)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(group.Files[0].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("directory holds %d entries, want the probe removed", len(entries))
+	}
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+
+	"disk-peek/internal/trash"
+)
+
+// Deleter is the deletion backend of DeleteNodeModules, DeleteDuplicates
+// and DeleteDecided: it decides whether paths are trashed, removed,
+// journaled or only planned. See cleaner.Journaled for the one used by the
+// app and the CLI.
+type Deleter interface {
+	// Delete removes path, which holds size bytes
+	Delete(path string, size int64) error
+	// Action is what Delete does to a path, ActionTrash or ActionRemove
+	Action() string
+	// DryRun reports whether Delete leaves paths alone
+	DryRun() bool
+}
+
+// TrashDeleter moves paths to the system trash
+func TrashDeleter() Deleter { return trashDeleter{} }
+
+// PermanentDeleter removes paths with os.RemoveAll
+func PermanentDeleter() Deleter { return permanentDeleter{} }
+
+// DeleterFor returns PermanentDeleter or TrashDeleter
+func DeleterFor(permanent bool) Deleter {
+	if permanent {
+		return PermanentDeleter()
+	}
+	return TrashDeleter()
+}
+
+// Checker is implemented by backends that can tell up front why Delete
+// would fail for a path, e.g. for lack of permissions or of a trash
+type Checker interface {
+	Check(path string) error
+}
+
+// DryRunDeleter plans what d would do: paths are checked to exist and, when
+// d is a Checker, checked by d
+func DryRunDeleter(d Deleter) Deleter { return dryRunDeleter{d} }
+
+type trashDeleter struct{}
+
+func (trashDeleter) Delete(path string, size int64) error { return trash.MoveToTrash(path) }
+func (trashDeleter) Action() string                       { return ActionTrash }
+func (trashDeleter) DryRun() bool                         { return false }
+
+type permanentDeleter struct{}
+
+func (permanentDeleter) Delete(path string, size int64) error { return os.RemoveAll(path) }
+func (permanentDeleter) Action() string                       { return ActionRemove }
+func (permanentDeleter) DryRun() bool                         { return false }
+
+type dryRunDeleter struct{ Deleter }
+
+func (d dryRunDeleter) Delete(path string, size int64) error {
+	if _, err := os.Lstat(path); err != nil {
+		return err
+	}
+	if checker, ok := d.Deleter.(Checker); ok {
+		return checker.Check(path)
+	}
+	return nil
+}
+func (dryRunDeleter) DryRun() bool { return true }
+
+// newDeleteResult returns an empty result for deleting with d
+func newDeleteResult(d Deleter) CleanResult {
+	result := CleanResult{
+		FreedBytes:     0,
+		DeletedPaths:   []string{},
+		Errors:         []string{},
+		DetailedErrors: []CleanError{},
+		DryRun:         d.DryRun(),
+	}
+	if d.DryRun() {
+		result.Plan = []PlannedDeletion{}
+	}
+	return result
+}
+
+// deleteInto deletes path with d and records the outcome in result
+func deleteInto(result *CleanResult, d Deleter, path string, size int64) {
+	err := d.Delete(path, size)
+
+	var cleanErr *CleanError
+	if err != nil {
+		code := "DELETE_FAILED"
+		if os.IsPermission(err) {
+			code = "PERMISSION_DENIED"
+		} else if os.IsNotExist(err) {
+			code = "NOT_FOUND"
+		} else if errors.Is(err, trash.ErrCrossDevice) {
+			code = "NO_TRASH"
+		}
+		cleanErr = &CleanError{Path: path, Message: err.Error(), Code: code}
+		result.Errors = append(result.Errors, cleanErr.Message)
+		result.DetailedErrors = append(result.DetailedErrors, *cleanErr)
+	} else {
+		result.FreedBytes += size
+		result.DeletedPaths = append(result.DeletedPaths, path)
+	}
+
+	if d.DryRun() {
+		result.Plan = append(result.Plan, PlannedDeletion{
+			Path:   path,
+			Size:   size,
+			Action: d.Action(),
+			Error:  cleanErr,
+		})
+	}
+}
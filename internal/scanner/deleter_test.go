@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordingDeleter remembers what it was asked to delete
+type recordingDeleter struct {
+	deleted map[string]int64
+	// refused, if set, is reported by Check for every path
+	refused error
+}
+
+func (r *recordingDeleter) Delete(path string, size int64) error {
+	r.deleted[path] = size
+	return nil
+}
+func (r *recordingDeleter) Action() string          { return ActionTrash }
+func (r *recordingDeleter) DryRun() bool            { return false }
+func (r *recordingDeleter) Check(path string) error { return r.refused }
+
+// removingDeleter removes paths for good
+type removingDeleter struct{}
+
+func (removingDeleter) Delete(path string, size int64) error { return os.RemoveAll(path) }
+func (removingDeleter) Action() string                       { return ActionRemove }
+func (removingDeleter) DryRun() bool                         { return false }
+
+func TestDeleteNodeModulesBackends(t *testing.T) {
+	root := t.TempDir()
+	var paths []string
+	for _, project := range []string{"app", "site"} {
+		path := filepath.Join(root, project, "node_modules")
+		if err := os.MkdirAll(filepath.Join(path, "dep"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(path, "dep", "index.js"), make([]byte, 1000), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	missing := filepath.Join(root, "gone", "node_modules")
+
+	t.Run("custom backend", func(t *testing.T) {
+		recorder := &recordingDeleter{deleted: make(map[string]int64)}
+		result := DeleteNodeModules(paths, recorder, nil)
+		if len(recorder.deleted) != 2 || len(result.DeletedPaths) != 2 {
+			t.Fatalf("deleted %v, want both paths", recorder.deleted)
+		}
+		var sum int64
+		for _, path := range paths {
+			if recorder.deleted[path] <= 0 {
+				t.Errorf("%s deleted without its size", path)
+			}
+			sum += recorder.deleted[path]
+		}
+		if result.FreedBytes != sum {
+			t.Errorf("freed %d, want the sizes passed to the backend, %d", result.FreedBytes, sum)
+		}
+		for _, path := range paths {
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("the backend alone decides, but %s is gone", path)
+			}
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		result := DeleteNodeModules(append(paths, missing), DryRunDeleter(TrashDeleter()), nil)
+		if !result.DryRun || len(result.Plan) != 3 {
+			t.Fatalf("result = %+v, want a dry run planning 3 paths", result)
+		}
+		for _, planned := range result.Plan[:2] {
+			if planned.Action != ActionTrash || planned.Error != nil {
+				t.Errorf("planned %+v, want a trash without error", planned)
+			}
+		}
+		if planned := result.Plan[2]; planned.Error == nil || planned.Error.Code != "NOT_FOUND" {
+			t.Errorf("missing path planned as %+v, want NOT_FOUND", planned)
+		}
+		for _, path := range paths {
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("dry run touched %s: %v", path, err)
+			}
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		result := DeleteNodeModules(paths, PermanentDeleter(), nil)
+		if len(result.DeletedPaths) != 2 || result.DryRun {
+			t.Fatalf("result = %+v, want 2 deleted paths", result)
+		}
+		for _, path := range paths {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("%s still exists", path)
+			}
+		}
+	})
+}
+
+func TestDeleteDuplicatesBackends(t *testing.T) {
+	root := t.TempDir()
+	group := DuplicateGroup{Hash: "h"}
+	for _, name := range []string{"keep.bin", "copy1.bin", "copy2.bin"} {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, make([]byte, 1000), 0644); err != nil {
+			t.Fatal(err)
+		}
+		group.Files = append(group.Files, DuplicateFile{Path: path, Name: name, Size: 1000})
+	}
+	groups := []DuplicateGroup{group}
+	copies := []string{group.Files[1].Path, group.Files[2].Path}
+
+	t.Run("custom backend", func(t *testing.T) {
+		recorder := &recordingDeleter{deleted: make(map[string]int64)}
+		result := DeleteDuplicates(groups, 0, recorder)
+		if len(recorder.deleted) != 2 || len(result.DeletedPaths) != 2 || result.FreedBytes != 2000 {
+			t.Fatalf("deleted %v, result %+v; want both copies", recorder.deleted, result)
+		}
+		for _, path := range copies {
+			if recorder.deleted[path] != 1000 {
+				t.Errorf("%s deleted with size %d, want 1000", path, recorder.deleted[path])
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("the backend alone decides, but %s is gone", path)
+			}
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		recorder := &recordingDeleter{deleted: make(map[string]int64)}
+		result := DeleteDuplicates(groups, 0, DryRunDeleter(recorder))
+		if !result.DryRun || len(result.Plan) != 2 || len(recorder.deleted) != 0 {
+			t.Fatalf("result = %+v, want a dry run planning 2 paths", result)
+		}
+		for _, planned := range result.Plan {
+			if planned.Action != ActionTrash || planned.Error != nil {
+				t.Errorf("planned %+v, want a trash without error", planned)
+			}
+		}
+
+		// Problems the backend finds up front are planned as errors
+		recorder.refused = os.ErrPermission
+		result = DeleteDuplicates(groups, 0, DryRunDeleter(recorder))
+		if len(result.DeletedPaths) != 0 || len(result.Plan) != 2 || result.Plan[0].Error == nil ||
+			result.Plan[0].Error.Code != "PERMISSION_DENIED" {
+			t.Errorf("result = %+v, want PERMISSION_DENIED for every copy", result)
+		}
+	})
+
+	t.Run("dry run of a missing copy", func(t *testing.T) {
+		missing := group
+		missing.Files = append([]DuplicateFile{}, group.Files...)
+		missing.Files[2].Path = filepath.Join(root, "gone.bin")
+		result := DeleteDuplicates([]DuplicateGroup{missing}, 0, DryRunDeleter(removingDeleter{}))
+		if planned := result.Plan[1]; planned.Error == nil || planned.Error.Code != "NOT_FOUND" {
+			t.Errorf("missing path planned as %+v, want NOT_FOUND", planned)
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		result := DeleteDuplicates(groups, 0, removingDeleter{})
+		if len(result.DeletedPaths) != 2 || result.DryRun {
+			t.Fatalf("result = %+v, want 2 deleted paths", result)
+		}
+		for _, path := range copies {
+			if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("%s still exists", path)
+			}
+		}
+		if _, err := os.Stat(group.Files[0].Path); err != nil {
+			t.Errorf("kept file is gone: %v", err)
+		}
+	})
+}
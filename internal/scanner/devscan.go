@@ -63,7 +63,7 @@ func (s *DevScanner) Scan() ScanResult {
 			cat := &cats[i]
 
 			// Scan paths for leaf categories
-			for _, path := range cat.Paths {
+			for _, path := range ResolvePaths(cat.Paths) {
 				allPaths = append(allPaths, path)
 				pathToCategoryMap[path] = cat
 			}
@@ -96,7 +96,9 @@ func (s *DevScanner) Scan() ScanResult {
 	}
 
 	// Map results back to categories
+	var excluded exclusionLog
 	for i, result := range results {
+		excluded.merge(result.Excluded)
 		if cat, ok := pathToCategoryMap[allPaths[i]]; ok {
 			cat.Size += result.Size
 			cat.ItemCount += result.FileCount + result.DirCount
@@ -126,6 +128,7 @@ func (s *DevScanner) Scan() ScanResult {
 		Categories:   categories,
 		TotalSize:    totalSize,
 		ScanDuration: time.Since(start),
+		Excluded:     excluded.list(),
 	}
 }
 
@@ -143,7 +146,7 @@ func (s *DevScanner) ScanCategory(categoryID string) *Category {
 
 	var collect func(c *Category)
 	collect = func(c *Category) {
-		for _, path := range c.Paths {
+		for _, path := range ResolvePaths(c.Paths) {
 			paths = append(paths, path)
 			pathToCat[path] = c
 		}
@@ -183,6 +186,9 @@ func (s *DevScanner) ScanCategory(categoryID string) *Category {
 }
 
 // GetCategoryItems returns detailed items within a category path
+// Plain paths contribute their immediate children, or the directories
+// matching the category's ItemPattern; every match of a glob pattern (e.g.
+// one directory per toolchain version) is its own item
 func (s *DevScanner) GetCategoryItems(categoryID string) ([]FileNode, error) {
 	categories := GetCategories()
 	cat := GetCategoryByID(categories, categoryID)
@@ -190,8 +196,80 @@ func (s *DevScanner) GetCategoryItems(categoryID string) ([]FileNode, error) {
 		return nil, nil
 	}
 
-	// Get items from the first path (most categories have one path)
-	return GetDirectoryItems(cat.Paths[0])
+	var items []FileNode
+	var firstErr error
+	for _, pattern := range cat.Paths {
+		for _, path := range ExpandPath(pattern) {
+			if IsGlobPattern(pattern) {
+				if node, err := getPathItem(path); err == nil {
+					items = append(items, node)
+				}
+				continue
+			}
+
+			var children []FileNode
+			var err error
+			if cat.ItemPattern != "" {
+				children, err = findItems(path, cat.ItemPattern)
+			} else {
+				children, err = GetDirectoryItems(path)
+			}
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			items = append(items, children...)
+		}
+	}
+
+	// Only report an error if nothing could be listed
+	if len(items) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return items, nil
+}
+
+// GetStaleCategoryItems returns the items of a category that filter matches
+// Categories with children are searched through their children.
+func (s *DevScanner) GetStaleCategoryItems(categoryID string, filter AgeFilter) ([]FileNode, error) {
+	cat := GetCategoryByID(GetCategories(), categoryID)
+	if cat == nil {
+		return nil, nil
+	}
+
+	var leaves []string
+	var collect func(c *Category)
+	collect = func(c *Category) {
+		if len(c.Children) == 0 {
+			leaves = append(leaves, c.ID)
+			return
+		}
+		for i := range c.Children {
+			collect(&c.Children[i])
+		}
+	}
+	collect(cat)
+
+	now := time.Now()
+	stale := []FileNode{}
+	var firstErr error
+	for _, id := range leaves {
+		items, err := s.GetCategoryItems(id)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		stale = append(stale, FilterItems(items, filter, now)...)
+	}
+
+	if len(stale) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return stale, nil
 }
 
 // QuickScan performs a fast scan that just checks if paths exist and gets basic info
@@ -219,7 +297,7 @@ func (s *DevScanner) QuickScan() ScanResult {
 					defer wg.Done()
 
 					var size int64
-					for _, path := range c.Paths {
+					for _, path := range ResolvePaths(c.Paths) {
 						result := WalkDirectory(path)
 						size += result.Size
 					}
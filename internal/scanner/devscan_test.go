@@ -4,7 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewDevScanner(t *testing.T) {
@@ -245,3 +248,157 @@ func TestParentSizeCalculation(t *testing.T) {
 		}
 	}
 }
+
+func TestDevScannerGetCategoryItemsGlob(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	// Two versioned cache directories matched by one glob pattern
+	for _, version := range []string{"v1", "v2"} {
+		dir := filepath.Join(home, "tool-cache", version)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "blob"), make([]byte, 4096), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configDir := filepath.Join(home, ".config", "disk-peek")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	categoriesFile := `{"categories": [{"id": "tool-cache", "name": "Tool Cache", "paths": ["~/tool-cache/*"]}]}`
+	if err := os.WriteFile(filepath.Join(configDir, "categories.json"), []byte(categoriesFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := NewDevScanner(2).GetCategoryItems("tool-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected one item per glob match, got %d", len(items))
+	}
+	for _, item := range items {
+		if !item.IsDir || item.Size == 0 {
+			t.Errorf("unexpected item %+v", item)
+		}
+	}
+}
+
+func TestDevScannerGetStaleCategoryItems(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	// An old module version, and one with a single recently read file
+	now := time.Now()
+	old := now.AddDate(0, 0, -90)
+	for _, version := range []string{"v1", "v2"} {
+		dir := filepath.Join(home, "mod-cache", version)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"a.go", "b.go"} {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, make([]byte, 4096), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recent := now.AddDate(0, 0, -1)
+	if err := os.Chtimes(filepath.Join(home, "mod-cache", "v2", "b.go"), recent, old); err != nil {
+		t.Fatal(err)
+	}
+
+	configDir := filepath.Join(home, ".config", "disk-peek")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	categoriesFile := `{"categories": [{"id": "mod-cache", "name": "Module Cache", "paths": ["~/mod-cache/*"]}]}`
+	if err := os.WriteFile(filepath.Join(configDir, "categories.json"), []byte(categoriesFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter AgeFilter
+		want   []string
+	}{
+		{"unused for 60 days", AgeFilter{OlderThanDays: 60}, []string{"v1"}},
+		{"modified before 60 days ago", AgeFilter{OlderThanDays: 60, IgnoreAccess: true}, []string{"v1", "v2"}},
+		{"unused since a date", AgeFilter{Before: now.AddDate(0, 0, -120)}, nil},
+		{"no filter", AgeFilter{}, []string{"v1", "v2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := NewDevScanner(2).GetStaleCategoryItems("mod-cache", tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			sort.Strings(names)
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("items = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestDevScannerGoModuleCacheItems(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GOPATH", "")
+
+	// Module versions sit at different depths below pkg/mod
+	modCache := filepath.Join(home, "go", "pkg", "mod")
+	now := time.Now()
+	old := now.AddDate(0, 0, -90)
+	modules := map[string]time.Time{
+		"github.com/old/lib@v1.0.0":          old,
+		"github.com/old/lib@v1.1.0":          now,
+		"golang.org/x/sys@v0.30.0":           old,
+		"gopkg.in/yaml.v3@v3.0.1":            now,
+		"cache/download/golang.org/x/sys/@v": old,
+	}
+	for module, used := range modules {
+		dir := filepath.Join(modCache, filepath.FromSlash(module))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, "go.mod")
+		if err := os.WriteFile(file, make([]byte, 4096), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{file, dir} {
+			if err := os.Chtimes(path, used, used); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	items, err := NewDevScanner(2).GetStaleCategoryItems("go", AgeFilter{OlderThanDays: 60})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	sort.Strings(names)
+	if want := "lib@v1.0.0,sys@v0.30.0"; strings.Join(names, ",") != want {
+		t.Errorf("stale items = %v, want %s", names, want)
+	}
+}
@@ -0,0 +1,340 @@
+package scanner
+
+import (
+	"context"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"lukechampine.com/blake3"
+)
+
+// dirNode is a directory seen by FindDuplicateDirs
+type dirNode struct {
+	path    string
+	modTime time.Time
+	entries []dirEntry
+	// incomplete is set when something below the directory was excluded or
+	// could not be read, so its copies may differ in what was not compared
+	incomplete bool
+
+	size  int64 // Total size of the tree
+	files int   // Number of files in the tree
+	shape string
+	hash  string
+}
+
+// dirEntry is a child of a dirNode
+type dirEntry struct {
+	name   string
+	path   string
+	size   int64
+	dir    *dirNode // Set for subdirectories
+	target string   // Set for symlinks
+	link   bool
+}
+
+// FindDuplicateDirs finds directories whose whole trees are identical: the
+// same names, file contents and symlink targets. Each directory is hashed
+// from the hashes of its children, Merkle style, and duplicates nested in
+// other duplicates are only reported through their highest duplicate
+// ancestors. Directories with excluded or unreadable entries never match.
+// MinSize applies to a directory's total size and hidden files count like
+// any other; MaxSize and IncludePatterns are not used.
+func FindDuplicateDirs(rootPath string, options DuplicatesOptions, progressCallback func(phase string, current int, total int)) DuplicatesResult {
+	return findDuplicateDirs(context.Background(), rootPath, options, progressCallback)
+}
+
+// findDuplicateDirs is FindDuplicateDirs stopping once ctx is done
+// Directories not fully read or hashed by then never match.
+func findDuplicateDirs(ctx context.Context, rootPath string, options DuplicatesOptions, progressCallback func(phase string, current int, total int)) DuplicatesResult {
+	startTime := time.Now()
+
+	if rootPath == "" {
+		rootPath, _ = os.UserHomeDir()
+	}
+	rootPath = filepath.Clean(rootPath)
+
+	// Phase 1: Read the tree
+	if progressCallback != nil {
+		progressCallback("scanning", 0, 0)
+	}
+
+	nodes := make(map[string]*dirNode)
+	var order []*dirNode // Parents before their children
+	var scanned int
+	ex := CurrentExcluder().WithPatterns(options.ExcludePatterns).Rooted(rootPath)
+	var excluded exclusionLog
+
+	markParent := func(path string) {
+		if parent := nodes[filepath.Dir(path)]; parent != nil {
+			parent.incomplete = true
+		}
+	}
+
+	_ = filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
+		if IsCancelled(ctx) {
+			// The directories being read are left incomplete
+			markParent(path)
+			return filepath.SkipAll
+		}
+		if err != nil {
+			// A directory that can't be listed is reported a second time
+			if node := nodes[path]; node != nil {
+				node.incomplete = true
+			} else {
+				markParent(path)
+			}
+			return nil
+		}
+
+		if rule, ok := ex.Match(path, d.IsDir()); ok {
+			excluded.add(path, rule)
+			markParent(path)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			markParent(path)
+			return nil
+		}
+		parent := nodes[filepath.Dir(path)]
+		if path == rootPath {
+			parent = nil
+		}
+		entry := dirEntry{name: d.Name(), path: path}
+
+		switch {
+		case d.IsDir():
+			node := &dirNode{path: path, modTime: info.ModTime()}
+			nodes[path] = node
+			order = append(order, node)
+			entry.dir = node
+		case d.Type()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				markParent(path)
+				return nil
+			}
+			entry.link, entry.target = true, target
+		case d.Type().IsRegular():
+			entry.size = info.Size()
+			scanned++
+			if progressCallback != nil && scanned%1000 == 0 {
+				progressCallback("scanning", scanned, 0)
+			}
+		default:
+			// Sockets, devices and pipes can't be compared
+			markParent(path)
+			return nil
+		}
+
+		if parent != nil {
+			parent.entries = append(parent.entries, entry)
+		}
+		return nil
+	})
+
+	// Phase 2: Size up every directory and give it a shape hash of names,
+	// kinds and sizes. Only directories sharing a shape can be duplicates.
+	shapes := make(map[string]int)
+	for i := len(order) - 1; i >= 0; i-- {
+		node := order[i]
+		sort.Slice(node.entries, func(a, b int) bool {
+			return node.entries[a].name < node.entries[b].name
+		})
+
+		shape := xxhash.New()
+		for _, entry := range node.entries {
+			switch {
+			case entry.dir != nil:
+				node.size += entry.dir.size
+				node.files += entry.dir.files
+				node.incomplete = node.incomplete || entry.dir.incomplete
+				_, _ = shape.WriteString("d\x00" + entry.name + "\x00" + entry.dir.shape + "\n")
+			case entry.link:
+				_, _ = shape.WriteString("l\x00" + entry.name + "\x00" + entry.target + "\n")
+			default:
+				node.size += entry.size
+				node.files++
+				_, _ = shape.WriteString("f\x00" + entry.name + "\x00" + strconv.FormatInt(entry.size, 10) + "\n")
+			}
+		}
+		node.shape = strconv.FormatUint(shape.Sum64(), 16)
+		if candidateDir(node, options) {
+			shapes[node.shape]++
+		}
+	}
+
+	var candidates []*dirNode
+	for _, node := range order {
+		if candidateDir(node, options) && shapes[node.shape] > 1 {
+			candidates = append(candidates, node)
+		}
+	}
+
+	// Phase 3: Hash the files of the candidates. Files whose content is
+	// unique can't be part of a duplicate tree, so they are dropped like
+	// they are for FindDuplicates and leave their directories unhashed.
+	seen := make(map[string]bool)
+	var paths []string
+	for _, node := range candidates {
+		collectFiles(node, seen, &paths)
+	}
+	cachedBefore := 0
+	if options.Hashes != nil {
+		cachedBefore, _ = options.Hashes.Stats()
+	}
+	fileHashes := make(map[string]string, len(paths))
+	for _, group := range splitByHash(ctx, [][]string{paths}, options.Hashes.cached(hashFile, true), "hashing", options.Workers, progressCallback) {
+		for _, path := range group.paths {
+			fileHashes[path] = group.hash
+		}
+	}
+
+	// Phase 4: Hash the candidate trees and group them
+	byHash := make(map[string][]*dirNode)
+	for _, node := range candidates {
+		if hash := treeHash(node, fileHashes); hash != "" {
+			byHash[hash] = append(byHash[hash], node)
+		}
+	}
+
+	duplicate := make(map[string]bool)
+	for _, nodes := range byHash {
+		if len(nodes) > 1 {
+			for _, node := range nodes {
+				duplicate[node.path] = true
+			}
+		}
+	}
+
+	var groups []DuplicateGroup
+	var totalWasted int64
+	var totalFiles int
+
+	for hash, nodes := range byHash {
+		if len(nodes) < 2 {
+			continue
+		}
+
+		// Collapse nested matches: a group whose every directory sits in a
+		// duplicate directory is already covered by its parents' group
+		nested := true
+		for _, node := range nodes {
+			if !duplicate[filepath.Dir(node.path)] {
+				nested = false
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+
+		files := make([]DuplicateFile, 0, len(nodes))
+		for _, node := range nodes {
+			files = append(files, DuplicateFile{
+				Path:    node.path,
+				Name:    filepath.Base(node.path),
+				Size:    node.size,
+				ModTime: node.modTime,
+				Hash:    hash,
+			})
+		}
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].ModTime.Before(files[j].ModTime)
+		})
+
+		size := nodes[0].size
+		wastedSize := size * int64(len(nodes)-1)
+		groups = append(groups, DuplicateGroup{
+			Hash:       hash,
+			Size:       size,
+			Files:      files,
+			WastedSize: wastedSize,
+			Directory:  true,
+		})
+
+		totalWasted += wastedSize
+		totalFiles += len(files)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].WastedSize > groups[j].WastedSize
+	})
+	if options.MaxGroups > 0 && len(groups) > options.MaxGroups {
+		groups = groups[:options.MaxGroups]
+	}
+
+	result := DuplicatesResult{
+		Groups:       groups,
+		TotalWasted:  totalWasted,
+		TotalFiles:   totalFiles,
+		TotalGroups:  len(groups),
+		ScanDuration: time.Since(startTime),
+		Excluded:     excluded.list(),
+	}
+	if options.Hashes != nil {
+		cachedAfter, _ := options.Hashes.Stats()
+		result.CachedHashes = cachedAfter - cachedBefore
+	}
+	return result
+}
+
+// candidateDir reports whether a directory may be reported as a duplicate
+func candidateDir(node *dirNode, options DuplicatesOptions) bool {
+	return !node.incomplete && node.files > 0 && node.size >= options.MinSize
+}
+
+// collectFiles appends the files of a tree not seen before to paths
+func collectFiles(node *dirNode, seen map[string]bool, paths *[]string) {
+	if seen[node.path] {
+		return
+	}
+	seen[node.path] = true
+	for _, entry := range node.entries {
+		switch {
+		case entry.dir != nil:
+			collectFiles(entry.dir, seen, paths)
+		case !entry.link:
+			*paths = append(*paths, entry.path)
+		}
+	}
+}
+
+// treeHash computes the BLAKE3 hash of a directory from the names and
+// hashes of its children, or "" if a file in it has no hash
+func treeHash(node *dirNode, fileHashes map[string]string) string {
+	if node.hash != "" {
+		return node.hash
+	}
+
+	hash := blake3.New(32, nil)
+	for _, entry := range node.entries {
+		var kind, sum string
+		switch {
+		case entry.dir != nil:
+			kind, sum = "d", treeHash(entry.dir, fileHashes)
+		case entry.link:
+			kind, sum = "l", entry.target
+		default:
+			kind, sum = "f", fileHashes[entry.path]
+		}
+		if sum == "" && kind != "l" {
+			return ""
+		}
+		_, _ = hash.Write([]byte(kind + "\x00" + entry.name + "\x00" + sum + "\n"))
+	}
+
+	node.hash = hex.EncodeToString(hash.Sum(nil))
+	return node.hash
+}
@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestFindDuplicateDirs(t *testing.T) {
+	root := t.TempDir()
+
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two copies of an SDK: only the top directories are reported, not
+	// their identical lib/ and docs/ subdirectories
+	for _, sdk := range []string{"sdk", "vendor/sdk-copy"} {
+		write(sdk+"/lib/core.so", "core library")
+		write(sdk+"/lib/.hidden", "dot file")
+		write(sdk+"/docs/readme.md", "read me")
+	}
+	// Same photos, but one import also holds an extra file: only the
+	// identical raw/ directories inside match
+	write("Downloads/photos/raw/img1.cr2", "raw image one")
+	write("Downloads/photos/raw/img2.cr2", "raw image two")
+	write("Downloads/photos (1)/raw/img1.cr2", "raw image one")
+	write("Downloads/photos (1)/raw/img2.cr2", "raw image two")
+	write("Downloads/photos (1)/notes.txt", "extra")
+	// Same names and sizes, different content
+	write("a/data.bin", "aaaa")
+	write("b/data.bin", "bbbb")
+
+	options := DefaultDuplicatesOptions()
+	options.MinSize = 0
+	result := FindDuplicateDirs(root, options, nil)
+
+	var got [][]string
+	for _, group := range result.Groups {
+		if !group.Directory {
+			t.Errorf("group %s is not marked as a directory group", group.Hash)
+		}
+		var paths []string
+		for _, file := range group.Files {
+			rel, _ := filepath.Rel(root, file.Path)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		sort.Strings(paths)
+		got = append(got, paths)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i][0] < got[j][0] })
+
+	want := [][]string{
+		{"Downloads/photos (1)/raw", "Downloads/photos/raw"},
+		{"sdk", "vendor/sdk-copy"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("groups = %v, want %v", got, want)
+	}
+	for i := range want {
+		if len(got[i]) != 2 || got[i][0] != want[i][0] || got[i][1] != want[i][1] {
+			t.Errorf("group %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	sdkSize := int64(len("core library") + len("dot file") + len("read me"))
+	rawSize := int64(len("raw image one") + len("raw image two"))
+	if result.TotalWasted != sdkSize+rawSize {
+		t.Errorf("wasted = %d, want %d", result.TotalWasted, sdkSize+rawSize)
+	}
+}
+
+func TestFindDuplicateDirsExcluded(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"one", "two"} {
+		if err := os.MkdirAll(filepath.Join(root, dir, "node_modules"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "index.js"), []byte("same"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The copies differ only in an excluded directory
+	if err := os.WriteFile(filepath.Join(root, "one", "node_modules", "dep.js"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	options := DefaultDuplicatesOptions()
+	options.MinSize = 0
+	if result := FindDuplicateDirs(root, options, nil); result.TotalGroups != 0 {
+		t.Errorf("groups = %+v, want none for partly excluded trees", result.Groups)
+	}
+}
@@ -1,15 +1,18 @@
 package scanner
 
 import (
-	"crypto/md5"
+	"context"
 	"encoding/hex"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"lukechampine.com/blake3"
 )
 
 // DuplicateFile represents a file that has duplicates
@@ -27,6 +30,9 @@ type DuplicateGroup struct {
 	Size       int64           `json:"size"`
 	Files      []DuplicateFile `json:"files"`
 	WastedSize int64           `json:"wastedSize"` // Size * (Count - 1)
+	// Directory is set for groups of identical directory trees found by
+	// FindDuplicateDirs, whose Files are the directories
+	Directory bool `json:"directory,omitempty"`
 }
 
 // DuplicatesResult contains the results of duplicate file scanning
@@ -36,6 +42,9 @@ type DuplicatesResult struct {
 	TotalFiles   int              `json:"totalFiles"`
 	TotalGroups  int              `json:"totalGroups"`
 	ScanDuration time.Duration    `json:"scanDuration"`
+	Excluded     []Exclusion      `json:"excluded,omitempty"`
+	// CachedHashes counts the hashes taken from DuplicatesOptions.Hashes
+	CachedHashes int `json:"cachedHashes,omitempty"`
 }
 
 // DuplicatesOptions configures the duplicate scan
@@ -44,7 +53,8 @@ type DuplicatesOptions struct {
 	MinSize int64
 	// MaxSize is the maximum file size to consider (0 = no limit)
 	MaxSize int64
-	// ExcludePatterns are patterns to exclude
+	// ExcludePatterns are gitignore-style patterns to exclude, applied on
+	// top of the persistent exclusions set with SetExcluder
 	ExcludePatterns []string
 	// IncludePatterns limits to specific patterns (empty = all)
 	IncludePatterns []string
@@ -52,6 +62,12 @@ type DuplicatesOptions struct {
 	MaxGroups int
 	// Workers for parallel hashing
 	Workers int
+	// Hashes (if set) remembers hashes between scans, so only new and
+	// modified files are read
+	Hashes *HashIndex
+	// Directories compares whole directory trees instead of single files,
+	// see FindDuplicateDirs
+	Directories bool
 }
 
 // DefaultDuplicatesOptions returns sensible defaults
@@ -63,7 +79,7 @@ func DefaultDuplicatesOptions() DuplicatesOptions {
 			".git",
 			"node_modules",
 			".Trash",
-			"Library/Caches",
+			"**/Library/Caches",
 		},
 		MaxGroups: 100,
 		Workers:   4,
@@ -72,6 +88,15 @@ func DefaultDuplicatesOptions() DuplicatesOptions {
 
 // FindDuplicates scans for duplicate files based on content hash
 func FindDuplicates(rootPath string, options DuplicatesOptions, progressCallback func(phase string, current int, total int)) DuplicatesResult {
+	return FindDuplicatesWithContext(context.Background(), rootPath, options, progressCallback)
+}
+
+// FindDuplicatesWithContext is FindDuplicates stopping once ctx is done
+// Files not hashed by then are left out, so the groups are incomplete.
+func FindDuplicatesWithContext(ctx context.Context, rootPath string, options DuplicatesOptions, progressCallback func(phase string, current int, total int)) DuplicatesResult {
+	if options.Directories {
+		return findDuplicateDirs(ctx, rootPath, options, progressCallback)
+	}
 	startTime := time.Now()
 
 	if rootPath == "" {
@@ -85,8 +110,13 @@ func FindDuplicates(rootPath string, options DuplicatesOptions, progressCallback
 
 	sizeGroups := make(map[int64][]string)
 	var scanned int
+	ex := CurrentExcluder().WithPatterns(options.ExcludePatterns).Rooted(rootPath)
+	var excluded exclusionLog
 
 	_ = filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if IsCancelled(ctx) {
+			return filepath.SkipAll
+		}
 		if err != nil {
 			return nil
 		}
@@ -102,14 +132,17 @@ func FindDuplicates(rootPath string, options DuplicatesOptions, progressCallback
 			return nil
 		}
 
+		// Skip excluded paths
+		if rule, ok := ex.Match(path, info.IsDir()); ok {
+			excluded.add(path, rule)
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// Skip directories
 		if info.IsDir() {
-			// Check exclusion patterns for directories
-			for _, pattern := range options.ExcludePatterns {
-				if strings.Contains(path, pattern) {
-					return filepath.SkipDir
-				}
-			}
 			return nil
 		}
 
@@ -119,13 +152,6 @@ func FindDuplicates(rootPath string, options DuplicatesOptions, progressCallback
 			return nil
 		}
 
-		// Skip excluded patterns
-		for _, pattern := range options.ExcludePatterns {
-			if strings.Contains(path, pattern) {
-				return nil
-			}
-		}
-
 		size := info.Size()
 
 		// Check size constraints
@@ -147,66 +173,52 @@ func FindDuplicates(rootPath string, options DuplicatesOptions, progressCallback
 	})
 
 	// Filter to only size groups with potential duplicates
-	var potentialDuplicates [][]string
+	var sameSize [][]string
 	for _, paths := range sizeGroups {
 		if len(paths) > 1 {
-			potentialDuplicates = append(potentialDuplicates, paths)
+			sameSize = append(sameSize, paths)
 		}
 	}
 
-	// Phase 2: Hash files with same size
-	if progressCallback != nil {
-		progressCallback("hashing", 0, len(potentialDuplicates))
+	// Phase 2: Hash the first and last 64 KB of larger files; files that
+	// differ there can't be duplicates, so most are never read in full
+	var small, large [][]string
+	for _, paths := range sameSize {
+		if fileSize(paths[0]) > 2*partialHashSize {
+			large = append(large, paths)
+		} else {
+			small = append(small, paths)
+		}
+	}
+	candidates := small
+	cachedBefore := 0
+	if options.Hashes != nil {
+		cachedBefore, _ = options.Hashes.Stats()
+	}
+	for _, group := range splitByHash(ctx, large, options.Hashes.cached(partialHash, false), "partial hashing", options.Workers, progressCallback) {
+		candidates = append(candidates, group.paths)
 	}
 
+	// Phase 3: Fully hash the remaining candidates
 	hashGroups := make(map[string][]DuplicateFile)
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, options.Workers)
-
-	groupsProcessed := 0
-	for _, paths := range potentialDuplicates {
-		for _, path := range paths {
-			wg.Add(1)
-			sem <- struct{}{}
-
-			go func(filePath string) {
-				defer wg.Done()
-				defer func() { <-sem }()
-
-				hash, err := hashFile(filePath)
-				if err != nil {
-					return
-				}
-
-				info, err := os.Stat(filePath)
-				if err != nil {
-					return
-				}
-
-				file := DuplicateFile{
-					Path:    filePath,
-					Name:    filepath.Base(filePath),
-					Size:    info.Size(),
-					ModTime: info.ModTime(),
-					Hash:    hash,
-				}
-
-				mu.Lock()
-				hashGroups[hash] = append(hashGroups[hash], file)
-				mu.Unlock()
-			}(path)
-		}
-
-		groupsProcessed++
-		if progressCallback != nil {
-			progressCallback("hashing", groupsProcessed, len(potentialDuplicates))
+	for _, group := range splitByHash(ctx, candidates, options.Hashes.cached(hashFile, true), "hashing", options.Workers, progressCallback) {
+		hash := group.hash
+		for _, path := range group.paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			hashGroups[hash] = append(hashGroups[hash], DuplicateFile{
+				Path:    path,
+				Name:    filepath.Base(path),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+				Hash:    hash,
+			})
 		}
 	}
 
-	wg.Wait()
-
-	// Phase 3: Build duplicate groups
+	// Phase 4: Build duplicate groups
 	var groups []DuplicateGroup
 	var totalWasted int64
 	var totalFiles int
@@ -245,16 +257,137 @@ func FindDuplicates(rootPath string, options DuplicatesOptions, progressCallback
 		groups = groups[:options.MaxGroups]
 	}
 
-	return DuplicatesResult{
+	result := DuplicatesResult{
 		Groups:       groups,
 		TotalWasted:  totalWasted,
 		TotalFiles:   totalFiles,
 		TotalGroups:  len(groups),
 		ScanDuration: time.Since(startTime),
+		Excluded:     excluded.list(),
+	}
+	if options.Hashes != nil {
+		cachedAfter, _ := options.Hashes.Stats()
+		result.CachedHashes = cachedAfter - cachedBefore
 	}
+	return result
+}
+
+// partialHashSize is how much of each end of a file partialHash reads
+const partialHashSize = 64 * 1024
+
+// hashGroup is a set of files sharing a hash
+type hashGroup struct {
+	hash  string
+	paths []string
 }
 
-// hashFile calculates the MD5 hash of a file
+// splitByHash hashes the files of each group and splits every group by
+// hash, dropping files that match no other file of their group
+// Progress is reported under phase, counting files. Files are no longer
+// hashed once ctx is done.
+func splitByHash(ctx context.Context, groups [][]string, hash func(string) (string, error), phase string, workers int, progressCallback func(phase string, current int, total int)) []hashGroup {
+	total := 0
+	for _, paths := range groups {
+		total += len(paths)
+	}
+	if progressCallback != nil {
+		progressCallback(phase, 0, total)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	hashes := make([]map[string][]string, len(groups))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	done := 0
+
+	for i, paths := range groups {
+		hashes[i] = make(map[string][]string)
+		for _, path := range paths {
+			if IsCancelled(ctx) {
+				break
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+
+			go func(i int, filePath string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				sum, err := hash(filePath)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err == nil {
+					hashes[i][sum] = append(hashes[i][sum], filePath)
+				}
+				done++
+				if progressCallback != nil {
+					progressCallback(phase, done, total)
+				}
+			}(i, path)
+		}
+	}
+
+	wg.Wait()
+
+	var split []hashGroup
+	for _, byHash := range hashes {
+		for sum, paths := range byHash {
+			if len(paths) > 1 {
+				split = append(split, hashGroup{hash: sum, paths: paths})
+			}
+		}
+	}
+	return split
+}
+
+// fileSize returns the size of a file, 0 if it can't be read
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// partialHash hashes the first and last partialHashSize bytes of a file
+// with xxHash. Equal partial hashes only make files candidates; hashFile
+// decides.
+func partialHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	hash := xxhash.New()
+	buf := make([]byte, partialHashSize)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	_, _ = hash.Write(buf[:n])
+
+	if tail := info.Size() - partialHashSize; tail > int64(n) {
+		n, err = file.ReadAt(buf, tail)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		_, _ = hash.Write(buf[:n])
+	}
+
+	return strconv.FormatUint(hash.Sum64(), 16), nil
+}
+
+// hashFile calculates the BLAKE3 hash of a file
 // Always hashes the full file to avoid false positives that could lead to data loss
 func hashFile(path string) (string, error) {
 	file, err := os.Open(path)
@@ -263,7 +396,7 @@ func hashFile(path string) (string, error) {
 	}
 	defer file.Close()
 
-	hash := md5.New()
+	hash := blake3.New(32, nil)
 	if _, err := io.Copy(hash, file); err != nil {
 		return "", err
 	}
@@ -271,52 +404,46 @@ func hashFile(path string) (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
-// DeleteDuplicates deletes duplicate files, keeping the specified index in each group
-// If keepIndex is -1, keeps the first (oldest) file
-func DeleteDuplicates(groups []DuplicateGroup, keepIndex int, permanent bool, trashFunc func(string) error) CleanResult {
-	result := CleanResult{
-		FreedBytes:     0,
-		DeletedPaths:   []string{},
-		Errors:         []string{},
-		DetailedErrors: []CleanError{},
-	}
-
+// DuplicatesToDelete returns the files DeleteDuplicates removes: every file
+// of each group except the one at keepIndex
+// If keepIndex is out of range, keeps the first (oldest) file
+func DuplicatesToDelete(groups []DuplicateGroup, keepIndex int) []DuplicateFile {
+	var files []DuplicateFile
 	for _, group := range groups {
-		// Determine which file to keep
-		keepIdx := keepIndex
-		if keepIdx < 0 || keepIdx >= len(group.Files) {
-			keepIdx = 0 // Default to keeping the first (oldest) file
-		}
-
+		keepIdx := keptIndex(group, keepIndex)
 		for i, file := range group.Files {
 			// Skip the file we want to keep
-			if i == keepIdx {
-				continue
-			}
-
-			var err error
-			if permanent {
-				err = os.Remove(file.Path)
-			} else if trashFunc != nil {
-				err = trashFunc(file.Path)
-			} else {
-				err = os.Remove(file.Path)
+			if i != keepIdx {
+				files = append(files, file)
 			}
+		}
+	}
+	return files
+}
 
-			if err != nil {
-				result.Errors = append(result.Errors, err.Error())
-				result.DetailedErrors = append(result.DetailedErrors, CleanError{
-					Path:    file.Path,
-					Message: err.Error(),
-					Code:    "DELETE_FAILED",
-				})
-				continue
-			}
+// keptIndex returns the index of the file to keep in a group
+func keptIndex(group DuplicateGroup, keepIndex int) int {
+	if keepIndex < 0 || keepIndex >= len(group.Files) {
+		return 0 // Default to keeping the first (oldest) file
+	}
+	return keepIndex
+}
 
-			result.FreedBytes += file.Size
-			result.DeletedPaths = append(result.DeletedPaths, file.Path)
+// DeleteDuplicates deletes duplicate files with the given backend, keeping
+// the specified index in each group
+// If keepIndex is -1, keeps the first (oldest) file
+func DeleteDuplicates(groups []DuplicateGroup, keepIndex int, deleter Deleter) CleanResult {
+	decisions := make([]DuplicateDecision, 0, len(groups))
+	for _, group := range groups {
+		if len(group.Files) == 0 {
+			continue
 		}
+		decisions = append(decisions, DuplicateDecision{
+			Hash:      group.Hash,
+			Directory: group.Directory,
+			Keep:      []DuplicateFile{group.Files[keptIndex(group, keepIndex)]},
+			Delete:    DuplicatesToDelete([]DuplicateGroup{group}, keepIndex),
+		})
 	}
-
-	return result
+	return DeleteDecided(decisions, deleter)
 }
@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	root := t.TempDir()
+
+	write := func(name string, data []byte) string {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	pattern := func(size int, seed byte) []byte {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i) ^ seed
+		}
+		return data
+	}
+
+	// Large files: two copies, and a file that only differs in the middle,
+	// which the partial hash can't tell apart
+	video := pattern(300*1024, 1)
+	a := write("video.mp4", video)
+	b := write("video copy.mp4", video)
+	middle := append([]byte(nil), video...)
+	middle[len(middle)/2] ^= 0xff
+	write("video edited.mp4", middle)
+	// Same size, different start: dropped by the partial hash
+	write("other.mp4", pattern(300*1024, 2))
+
+	// Small files skip the partial hash
+	c := write("notes.txt", pattern(4096, 3))
+	d := write("notes (1).txt", pattern(4096, 3))
+	write("todo.txt", pattern(4096, 4))
+
+	var partial, full int
+	options := DefaultDuplicatesOptions()
+	result := FindDuplicates(root, options, func(phase string, current int, total int) {
+		switch phase {
+		case "partial hashing":
+			partial = total
+		case "hashing":
+			full = total
+		}
+	})
+
+	if partial != 4 {
+		t.Errorf("partial hashing total = %d, want the 4 large files", partial)
+	}
+	if full != 6 {
+		t.Errorf("hashing total = %d, want 3 large and 3 small candidates", full)
+	}
+
+	if result.TotalGroups != 2 {
+		t.Fatalf("groups = %+v, want 2", result.Groups)
+	}
+	var got [][]string
+	for _, group := range result.Groups {
+		var paths []string
+		for _, file := range group.Files {
+			paths = append(paths, file.Path)
+			if file.Hash != group.Hash || len(file.Hash) != 64 {
+				t.Errorf("%s hash = %q, want the group's BLAKE3 hash %q", file.Path, file.Hash, group.Hash)
+			}
+		}
+		sort.Strings(paths)
+		got = append(got, paths)
+	}
+	want := [][]string{{b, a}, {d, c}}
+	for i := range want {
+		sort.Strings(want[i])
+		if len(got[i]) != 2 || got[i][0] != want[i][0] || got[i][1] != want[i][1] {
+			t.Errorf("group %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if result.TotalWasted != 300*1024+4096 {
+		t.Errorf("wasted = %d, want %d", result.TotalWasted, 300*1024+4096)
+	}
+}
+
+func TestFindDuplicatesCancelled(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.bin", "b.bin"} {
+		if err := os.WriteFile(filepath.Join(root, name), make([]byte, 4096), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, dirs := range []bool{false, true} {
+		options := DefaultDuplicatesOptions()
+		options.Directories = dirs
+		if result := FindDuplicatesWithContext(ctx, root, options, nil); len(result.Groups) != 0 {
+			t.Errorf("directories %v: groups = %+v after cancellation, want none", dirs, result.Groups)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package scanner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// maxReportedExclusions caps how many excluded paths a single scan reports
+// A broad pattern like ".git" can match thousands of directories
+const maxReportedExclusions = 1000
+
+// AnyMount is a mount rule that stops walkers from crossing into any
+// filesystem other than the one the scan started on
+const AnyMount = "*"
+
+// Exclusion reports a path that was skipped and the rule that matched it
+type Exclusion struct {
+	Path string `json:"path"`
+	Rule string `json:"rule"`
+}
+
+// Excluder decides which paths walkers must skip
+// It combines gitignore-style patterns, absolute path prefixes and mount
+// rules. A nil Excluder excludes nothing.
+type Excluder struct {
+	patterns []ignorePattern
+	paths    []string
+	mounts   map[uint64]string
+	anyMount bool
+
+	// Set by Rooted for a specific walk
+	root    string
+	rootDev uint64
+	hasRoot bool
+}
+
+// ignorePattern is a compiled gitignore-style pattern
+type ignorePattern struct {
+	source   string
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+var activeExcluder atomic.Pointer[Excluder]
+
+// SetExcluder sets the exclusions consulted by every walker in this package
+// Pass nil to disable exclusions
+func SetExcluder(e *Excluder) {
+	activeExcluder.Store(e)
+}
+
+// CurrentExcluder returns the exclusions set with SetExcluder
+func CurrentExcluder() *Excluder {
+	return activeExcluder.Load()
+}
+
+// NewExcluder compiles exclusion rules
+//
+// patterns are gitignore-style: "node_modules" matches a name at any depth,
+// "build/" only matches directories, patterns containing a slash such as
+// "Library/Caches" are relative to the scan root, "**" matches any number
+// of directories and a leading "!" re-includes a previously excluded path.
+//
+// paths are absolute path prefixes (~ and environment variables are
+// expanded). mounts are mount points walkers never cross into; "*" means
+// never leave the filesystem the scan started on.
+func NewExcluder(patterns, paths, mounts []string) *Excluder {
+	e := &Excluder{mounts: make(map[uint64]string)}
+
+	for _, line := range patterns {
+		if p, ok := compileIgnorePattern(line); ok {
+			e.patterns = append(e.patterns, p)
+		}
+	}
+	for _, p := range paths {
+		e.paths = append(e.paths, ResolvePaths([]string{p})...)
+	}
+	for _, m := range mounts {
+		if m == AnyMount {
+			e.anyMount = true
+			continue
+		}
+		for _, mountPath := range ExpandPath(m) {
+			if dev, ok := deviceOf(mountPath); ok {
+				e.mounts[dev] = mountPath
+			}
+		}
+	}
+
+	return e
+}
+
+// WithPatterns returns a copy of the excluder with extra patterns appended
+// It is used to combine per-scan options with the persistent rules.
+func (e *Excluder) WithPatterns(patterns []string) *Excluder {
+	if len(patterns) == 0 {
+		return e
+	}
+	var out Excluder
+	if e != nil {
+		out = *e
+		out.patterns = append([]ignorePattern(nil), e.patterns...)
+	}
+	for _, line := range patterns {
+		if p, ok := compileIgnorePattern(line); ok {
+			out.patterns = append(out.patterns, p)
+		}
+	}
+	return &out
+}
+
+// Rooted returns a copy of the excluder for a walk starting at root
+// Anchored patterns are matched relative to root, and mount rules compare
+// against root's filesystem.
+func (e *Excluder) Rooted(root string) *Excluder {
+	if e == nil {
+		return nil
+	}
+	out := *e
+	out.root = filepath.Clean(root)
+	out.rootDev, out.hasRoot = deviceOf(root)
+	return &out
+}
+
+// Match reports whether path is excluded and by which rule
+// For directories with mount rules configured, the directory is stat'ed to
+// find its filesystem. The walk root itself is never excluded by patterns.
+func (e *Excluder) Match(path string, isDir bool) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+
+	for _, prefix := range e.paths {
+		if path == prefix || strings.HasPrefix(path, prefix+string(filepath.Separator)) {
+			return prefix, true
+		}
+	}
+
+	if rule, ok := e.matchPatterns(path, isDir); ok {
+		return rule, true
+	}
+
+	if isDir && (e.anyMount || len(e.mounts) > 0) && e.hasRoot {
+		if dev, ok := deviceOf(path); ok && dev != e.rootDev {
+			if e.anyMount {
+				return AnyMount, true
+			}
+			if mount, ok := e.mounts[dev]; ok {
+				return mount, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// matchPatterns applies gitignore semantics: the last matching pattern wins
+func (e *Excluder) matchPatterns(path string, isDir bool) (string, bool) {
+	if len(e.patterns) == 0 {
+		return "", false
+	}
+
+	rel := path
+	if e.root != "" {
+		r, err := filepath.Rel(e.root, path)
+		if err != nil || r == "." || strings.HasPrefix(r, "..") {
+			return "", false
+		}
+		rel = r
+	}
+	rel = filepath.ToSlash(rel)
+	name := filepath.Base(path)
+
+	matched, rule := false, ""
+	for _, p := range e.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		subject := name
+		if p.anchored {
+			subject = rel
+		}
+		if p.re.MatchString(subject) {
+			matched, rule = !p.negate, p.source
+		}
+	}
+	return rule, matched
+}
+
+// compileIgnorePattern converts a gitignore-style line into a regexp
+func compileIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	p := ignorePattern{source: line}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	p.re = re
+	return p, true
+}
+
+// signature identifies the rules of a rooted excluder
+// It is stored with cached directory listings so changing the rules
+// invalidates them. The root only counts when a rule depends on it.
+func (e *Excluder) signature() uint64 {
+	if e == nil {
+		return 0
+	}
+	h := fnv.New64a()
+	for _, p := range e.patterns {
+		fmt.Fprintf(h, "p%s\x00", p.source)
+		if p.anchored {
+			fmt.Fprintf(h, "r%s\x00", e.root)
+		}
+	}
+	if e.anyMount || len(e.mounts) > 0 {
+		fmt.Fprintf(h, "a%v\x00r%d\x00", e.anyMount, e.rootDev)
+	}
+	for _, p := range e.paths {
+		fmt.Fprintf(h, "d%s\x00", p)
+	}
+	devs := make([]uint64, 0, len(e.mounts))
+	for dev := range e.mounts {
+		devs = append(devs, dev)
+	}
+	sort.Slice(devs, func(i, j int) bool { return devs[i] < devs[j] })
+	for _, dev := range devs {
+		fmt.Fprintf(h, "m%d\x00", dev)
+	}
+	return h.Sum64()
+}
+
+// deviceOf returns the device ID of the filesystem containing path
+func deviceOf(path string) (uint64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
+
+// exclusionLog collects exclusions reported during a concurrent walk
+type exclusionLog struct {
+	mu    sync.Mutex
+	items []Exclusion
+}
+
+// add records an exclusion, dropping it once the report cap is reached
+func (l *exclusionLog) add(path, rule string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if len(l.items) < maxReportedExclusions {
+		l.items = append(l.items, Exclusion{Path: path, Rule: rule})
+	}
+	l.mu.Unlock()
+}
+
+// merge records exclusions reported by a nested walk
+func (l *exclusionLog) merge(items []Exclusion) {
+	for _, item := range items {
+		l.add(item.Path, item.Rule)
+	}
+}
+
+// list returns the recorded exclusions
+func (l *exclusionLog) list() []Exclusion {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.items
+}
@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExcluderMatchPatterns(t *testing.T) {
+	root := "/scan"
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		excluded bool
+	}{
+		{"name at any depth", []string{"node_modules"}, "/scan/a/b/node_modules", true, true},
+		{"name does not match prefix", []string{".git"}, "/scan/a/.github", true, false},
+		{"wildcard", []string{"*.log"}, "/scan/a/debug.log", false, true},
+		{"question mark", []string{"file?.txt"}, "/scan/file1.txt", false, true},
+		{"character class", []string{"[ab].bin"}, "/scan/x/b.bin", false, true},
+		{"negated class", []string{"[!ab].bin"}, "/scan/x/b.bin", false, false},
+		{"dir only matches dir", []string{"build/"}, "/scan/app/build", true, true},
+		{"dir only skips file", []string{"build/"}, "/scan/app/build", false, false},
+		{"anchored relative to root", []string{"Library/Caches"}, "/scan/Library/Caches", true, true},
+		{"anchored not nested", []string{"Library/Caches"}, "/scan/x/Library/Caches", true, false},
+		{"leading slash anchors", []string{"/tmp"}, "/scan/a/tmp", true, false},
+		{"double star prefix", []string{"**/Library/Caches"}, "/scan/x/Library/Caches", true, true},
+		{"double star middle", []string{"a/**/z"}, "/scan/a/b/c/z", true, true},
+		{"double star suffix", []string{"a/**"}, "/scan/a/b", false, true},
+		{"negation re-includes", []string{"*.log", "!keep.log"}, "/scan/keep.log", false, false},
+		{"last match wins", []string{"!keep.log", "*.log"}, "/scan/keep.log", false, true},
+		{"comments and blanks ignored", []string{"# *.log", ""}, "/scan/a.log", false, false},
+		{"root never matched", []string{"scan"}, "/scan", true, false},
+		{"outside root", []string{"*"}, "/other/file", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := NewExcluder(tt.patterns, nil, nil).Rooted(root)
+			_, ok := ex.Match(tt.path, tt.isDir)
+			if ok != tt.excluded {
+				t.Errorf("Match(%q) with %v = %v, want %v", tt.path, tt.patterns, ok, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestExcluderMatchPaths(t *testing.T) {
+	t.Setenv("HOME", "/home/tester")
+	ex := NewExcluder(nil, []string{"~/Archive", "/mnt/backup"}, nil)
+
+	tests := []struct {
+		path     string
+		excluded bool
+		rule     string
+	}{
+		{"/home/tester/Archive", true, "/home/tester/Archive"},
+		{"/home/tester/Archive/2020/photo.jpg", true, "/home/tester/Archive"},
+		{"/home/tester/Archived", false, ""},
+		{"/mnt/backup/disk.img", true, "/mnt/backup"},
+		{"/mnt", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rule, ok := ex.Match(tt.path, false)
+			if ok != tt.excluded || rule != tt.rule {
+				t.Errorf("Match(%q) = (%q, %v), want (%q, %v)", tt.path, rule, ok, tt.rule, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestExcluderNil(t *testing.T) {
+	var ex *Excluder
+	if _, ok := ex.Rooted("/").Match("/anything", true); ok {
+		t.Error("nil excluder should not exclude anything")
+	}
+
+	withPatterns := ex.WithPatterns([]string{"*.tmp"})
+	if _, ok := withPatterns.Match("/a/b.tmp", false); !ok {
+		t.Error("WithPatterns on nil excluder should apply the patterns")
+	}
+}
+
+func TestWalkDirectoryExclusions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]int{
+		"keep.txt":                 100,
+		"node_modules/pkg/a.js":    1000,
+		"src/app.log":              2000,
+		"src/main.go":              300,
+		"private/secret/data.bin":  4000,
+		"private/secret/more.bin":  4000,
+		"src/vendor/lib/inner.txt": 500,
+	}
+	for name, size := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	SetExcluder(NewExcluder(
+		[]string{"node_modules", "*.log", "src/vendor/"},
+		[]string{filepath.Join(tmpDir, "private")},
+		nil,
+	))
+	t.Cleanup(func() { SetExcluder(nil) })
+
+	want := map[string]string{
+		filepath.Join(tmpDir, "node_modules"):   "node_modules",
+		filepath.Join(tmpDir, "src", "app.log"): "*.log",
+		filepath.Join(tmpDir, "src", "vendor"):  "src/vendor/",
+		filepath.Join(tmpDir, "private"):        filepath.Join(tmpDir, "private"),
+	}
+
+	check := func(t *testing.T, result WalkResult) {
+		if result.FileCount != 2 {
+			t.Errorf("FileCount = %d, want 2", result.FileCount)
+		}
+		if len(result.Excluded) != len(want) {
+			t.Errorf("Excluded = %v, want %d entries", result.Excluded, len(want))
+		}
+		for _, e := range result.Excluded {
+			if rule, ok := want[e.Path]; !ok || rule != e.Rule {
+				t.Errorf("unexpected exclusion %+v", e)
+			}
+		}
+	}
+
+	t.Run("WalkDirectory", func(t *testing.T) {
+		check(t, WalkDirectory(tmpDir))
+	})
+	t.Run("WalkDirectoryFast", func(t *testing.T) {
+		check(t, WalkDirectoryFast(tmpDir, 4))
+	})
+}
+
+func TestNormalScanExclusions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, name := range []string{"keep/a.txt", "skip/b.txt", "keep/build/c.txt"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, 1000), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	SetExcluder(NewExcluder([]string{"/skip", "build/"}, nil, nil))
+	t.Cleanup(func() { SetExcluder(nil) })
+
+	result := NewNormalScanner(2).ScanPath(tmpDir)
+
+	if len(result.Root.Children) != 1 || result.Root.Children[0].Name != "keep" {
+		t.Fatalf("children = %v, want only keep", result.Root.Children)
+	}
+	if len(result.Excluded) != 2 {
+		t.Errorf("Excluded = %v, want skip and keep/build", result.Excluded)
+	}
+}
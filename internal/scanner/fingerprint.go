@@ -0,0 +1,276 @@
+package scanner
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// fingerprintIndexVersion is bumped whenever dirFingerprint changes shape
+const fingerprintIndexVersion = 2
+
+// maxListingAge is how long a listing is reused before the directory is read
+// again even though its fingerprint is unchanged
+var maxListingAge = 24 * time.Hour
+
+// FingerprintIndex remembers what each directory contained during the last
+// scan, keyed by a fingerprint of the directory itself (device, inode,
+// mtime and link count). A directory whose fingerprint is unchanged has had
+// no entries added, removed or renamed, so its listing is reused instead of
+// being read and stat'ed again. Subdirectories are still visited, because a
+// change deep in the tree does not touch the mtime of its ancestors.
+//
+// Files modified in place keep their directory's mtime, so a reused listing
+// misses their new size. To bound how stale a size can get, listings older
+// than maxListingAge are read again; until then a file growing in place is
+// only picked up once its directory changes or a full rescan is done.
+type FingerprintIndex struct {
+	mu      sync.Mutex
+	dirs    map[string]dirFingerprint
+	visited map[string]bool
+
+	reused int64
+	walked int64
+}
+
+// dirFingerprint is the remembered state of a single directory
+type dirFingerprint struct {
+	// Fingerprint of the directory itself
+	Dev     uint64
+	Inode   uint64
+	ModTime int64
+	Nlink   uint64
+	Rules   uint64
+
+	// ReadAt is when the listing was read, in Unix nanoseconds
+	ReadAt int64
+
+	// Listing of the directory
+	FileSize  int64 // Disk usage of files with a single link
+	FileCount int
+	Linked    []linkedFile // Hardlinked files, deduplicated per walk
+	Subdirs   []string
+	Excluded  []Exclusion
+}
+
+// linkedFile is a file with more than one hardlink
+type linkedFile struct {
+	Inode uint64
+	Size  int64
+}
+
+// fingerprintIndexFile is the on-disk layout of the index
+type fingerprintIndexFile struct {
+	Version int
+	Dirs    map[string]dirFingerprint
+}
+
+// NewFingerprintIndex returns an empty index, which makes the next scan a
+// full one
+func NewFingerprintIndex() *FingerprintIndex {
+	return &FingerprintIndex{
+		dirs:    make(map[string]dirFingerprint),
+		visited: make(map[string]bool),
+	}
+}
+
+// LoadFingerprintIndex reads an index saved with Save
+// A missing file yields an empty index
+func LoadFingerprintIndex(path string) (*FingerprintIndex, error) {
+	idx := NewFingerprintIndex()
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return idx, err
+	}
+	defer f.Close()
+
+	var file fingerprintIndexFile
+	if err := gob.NewDecoder(f).Decode(&file); err != nil {
+		return idx, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Version != fingerprintIndexVersion {
+		return idx, nil
+	}
+	if file.Dirs != nil {
+		idx.dirs = file.Dirs
+	}
+	return idx, nil
+}
+
+// Save writes the index to path, replacing any previous file atomically
+func (idx *FingerprintIndex) Save(path string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fingerprints-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	file := fingerprintIndexFile{Version: fingerprintIndexVersion, Dirs: idx.dirs}
+	if err := gob.NewEncoder(tmp).Encode(&file); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Len returns the number of directories in the index
+func (idx *FingerprintIndex) Len() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return len(idx.dirs)
+}
+
+// Stats returns how many directory listings were reused from the index and
+// how many had to be read since the index was created or loaded
+func (idx *FingerprintIndex) Stats() (reused, walked int) {
+	return int(atomic.LoadInt64(&idx.reused)), int(atomic.LoadInt64(&idx.walked))
+}
+
+// Prune drops directories under root that were not visited since the last
+// Prune, i.e. directories that no longer exist or are now excluded
+func (idx *FingerprintIndex) Prune(root string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	prefix := strings.TrimSuffix(root, string(filepath.Separator)) + string(filepath.Separator)
+	for path := range idx.dirs {
+		if (path == root || strings.HasPrefix(path, prefix)) && !idx.visited[path] {
+			delete(idx.dirs, path)
+		}
+	}
+	idx.visited = make(map[string]bool)
+}
+
+// Invalidate forgets the listings of dirs, so they are read from disk the
+// next time even if their fingerprint is unchanged. Use it for directories
+// known to have changed, such as those named by a watcher event.
+func (idx *FingerprintIndex) Invalidate(dirs ...string) {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, dir := range dirs {
+		delete(idx.dirs, filepath.Clean(dir))
+	}
+}
+
+// listing returns the listing of dirPath, from the index if the directory's
+// fingerprint is unchanged and from disk otherwise. A nil index always reads
+// from disk.
+func (idx *FingerprintIndex) listing(dirPath string, info os.FileInfo, ex *Excluder) (dirFingerprint, bool) {
+	if idx == nil {
+		return readDirListing(dirPath, ex)
+	}
+
+	fp := fingerprintOf(info, ex)
+
+	idx.mu.Lock()
+	cached, ok := idx.dirs[dirPath]
+	idx.visited[dirPath] = true
+	idx.mu.Unlock()
+
+	if ok && cached.Dev == fp.Dev && cached.Inode == fp.Inode && cached.ModTime == fp.ModTime &&
+		cached.Nlink == fp.Nlink && cached.Rules == fp.Rules &&
+		time.Since(time.Unix(0, cached.ReadAt)) < maxListingAge {
+		atomic.AddInt64(&idx.reused, 1)
+		return cached, true
+	}
+
+	readAt := time.Now().UnixNano()
+	listing, ok := readDirListing(dirPath, ex)
+	if !ok {
+		return listing, false
+	}
+	atomic.AddInt64(&idx.walked, 1)
+
+	listing.Dev, listing.Inode, listing.ModTime, listing.Nlink, listing.Rules =
+		fp.Dev, fp.Inode, fp.ModTime, fp.Nlink, fp.Rules
+	listing.ReadAt = readAt
+
+	idx.mu.Lock()
+	idx.dirs[dirPath] = listing
+	idx.mu.Unlock()
+
+	return listing, true
+}
+
+// fingerprintOf builds the fingerprint fields for a directory
+func fingerprintOf(info os.FileInfo, ex *Excluder) dirFingerprint {
+	fp := dirFingerprint{
+		ModTime: info.ModTime().UnixNano(),
+		Rules:   ex.signature(),
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		fp.Dev = uint64(stat.Dev)
+		fp.Inode = stat.Ino
+		fp.Nlink = uint64(stat.Nlink)
+	}
+	return fp
+}
+
+// readDirListing reads a directory and sums up the files directly inside it
+// Symlinks and excluded entries are skipped.
+func readDirListing(dirPath string, ex *Excluder) (dirFingerprint, bool) {
+	var listing dirFingerprint
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return listing, false
+	}
+
+	for _, entry := range entries {
+		// Skip symlinks
+		if entry.Type()&os.ModeSymlink != 0 {
+			continue
+		}
+
+		fullPath := filepath.Join(dirPath, entry.Name())
+
+		if rule, ok := ex.Match(fullPath, entry.IsDir()); ok {
+			listing.Excluded = append(listing.Excluded, Exclusion{Path: fullPath, Rule: rule})
+			continue
+		}
+
+		if entry.IsDir() {
+			listing.Subdirs = append(listing.Subdirs, entry.Name())
+			continue
+		}
+
+		entryInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		if stat, ok := entryInfo.Sys().(*syscall.Stat_t); ok {
+			// Use actual disk blocks for sparse file support
+			size := stat.Blocks * 512
+			if stat.Nlink > 1 {
+				listing.Linked = append(listing.Linked, linkedFile{Inode: stat.Ino, Size: size})
+				continue
+			}
+			listing.FileSize += size
+		} else {
+			listing.FileSize += entryInfo.Size()
+		}
+		listing.FileCount++
+	}
+
+	return listing, true
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, root string, files map[string]int) {
+	t.Helper()
+	for name, size := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIncrementalScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, map[string]int{
+		"a/one.bin":       4096,
+		"a/deep/two.bin":  8192,
+		"b/three.bin":     4096,
+		"b/gone/four.bin": 4096,
+	})
+
+	full := NewNormalScanner(2).ScanPath(tmpDir)
+
+	idx := NewFingerprintIndex()
+	s := NewNormalScanner(2)
+	s.SetFingerprintIndex(idx)
+
+	first := s.ScanPath(tmpDir)
+	if first.TotalSize != full.TotalSize {
+		t.Fatalf("indexed scan size = %d, full scan size = %d", first.TotalSize, full.TotalSize)
+	}
+	if reused, walked := idx.Stats(); reused != 0 || walked != 4 {
+		t.Fatalf("first scan: reused %d, walked %d, want 0 and 4", reused, walked)
+	}
+
+	t.Run("unchanged tree is not read again", func(t *testing.T) {
+		second := s.ScanPath(tmpDir)
+		if second.TotalSize != first.TotalSize {
+			t.Errorf("second scan size = %d, want %d", second.TotalSize, first.TotalSize)
+		}
+		if reused, walked := idx.Stats(); reused != 4 || walked != 4 {
+			t.Errorf("second scan: reused %d, walked %d, want 4 and 4", reused, walked)
+		}
+	})
+
+	t.Run("changed directories are read again", func(t *testing.T) {
+		writeTestFiles(t, tmpDir, map[string]int{"a/deep/new.bin": 16384})
+		if err := os.RemoveAll(filepath.Join(tmpDir, "b", "gone")); err != nil {
+			t.Fatal(err)
+		}
+
+		third := s.ScanPath(tmpDir)
+		want := NewNormalScanner(2).ScanPath(tmpDir).TotalSize
+		if third.TotalSize != want {
+			t.Errorf("third scan size = %d, want %d", third.TotalSize, want)
+		}
+		// a/deep and b changed, a did not
+		if reused, walked := idx.Stats(); reused != 5 || walked != 6 {
+			t.Errorf("third scan: reused %d, walked %d, want 5 and 6", reused, walked)
+		}
+		if idx.Len() != 3 {
+			t.Errorf("index has %d directories, want 3 after pruning b/gone", idx.Len())
+		}
+	})
+
+	t.Run("changed exclusions invalidate listings", func(t *testing.T) {
+		SetExcluder(NewExcluder([]string{"*.bin"}, nil, nil))
+		t.Cleanup(func() { SetExcluder(nil) })
+
+		result := s.ScanPath(tmpDir)
+		if result.TotalSize != 0 {
+			t.Errorf("size with *.bin excluded = %d, want 0", result.TotalSize)
+		}
+	})
+}
+
+func TestStaleListingsAreReadAgain(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, map[string]int{"log/app.log": 4096})
+
+	idx := NewFingerprintIndex()
+	s := NewNormalScanner(2)
+	s.SetFingerprintIndex(idx)
+	first := s.ScanPath(tmpDir).TotalSize
+
+	// Growing a file in place leaves its directory's fingerprint unchanged
+	f, err := os.OpenFile(filepath.Join(tmpDir, "log", "app.log"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(make([]byte, 65536))
+	f.Close()
+
+	if got := s.ScanPath(tmpDir).TotalSize; got != first {
+		t.Fatalf("fresh listing size = %d, want the reused %d", got, first)
+	}
+
+	old := maxListingAge
+	maxListingAge = 0
+	t.Cleanup(func() { maxListingAge = old })
+
+	want := NewNormalScanner(2).ScanPath(tmpDir).TotalSize
+	if got := s.ScanPath(tmpDir).TotalSize; got != want || got == first {
+		t.Errorf("stale listing size = %d, want %d", got, want)
+	}
+}
+
+func TestFingerprintIndexSaveLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, map[string]int{"a/b/c.bin": 4096})
+
+	idx := NewFingerprintIndex()
+	s := NewNormalScanner(2)
+	s.SetFingerprintIndex(idx)
+	want := s.ScanPath(tmpDir).TotalSize
+
+	indexPath := filepath.Join(t.TempDir(), "fingerprints.gob")
+	if err := idx.Save(indexPath); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadFingerprintIndex(indexPath)
+	if err != nil {
+		t.Fatalf("LoadFingerprintIndex: %v", err)
+	}
+	if loaded.Len() != idx.Len() {
+		t.Fatalf("loaded %d directories, want %d", loaded.Len(), idx.Len())
+	}
+
+	s.SetFingerprintIndex(loaded)
+	if got := s.ScanPath(tmpDir).TotalSize; got != want {
+		t.Errorf("size from loaded index = %d, want %d", got, want)
+	}
+	if _, walked := loaded.Stats(); walked != 0 {
+		t.Errorf("loaded index read %d directories, want 0", walked)
+	}
+
+	missing, err := LoadFingerprintIndex(filepath.Join(t.TempDir(), "missing.gob"))
+	if err != nil || missing.Len() != 0 {
+		t.Errorf("missing file = (%d entries, %v), want empty index", missing.Len(), err)
+	}
+}
@@ -0,0 +1,215 @@
+package scanner
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// hashIndexVersion is bumped whenever the hashes or hashEntry change
+const hashIndexVersion = 1
+
+// DefaultHashIndexLimit is the number of files a HashIndex remembers
+const DefaultHashIndexLimit = 200000
+
+// HashIndex remembers the content hashes computed by FindDuplicates, keyed
+// by file identity (device and inode). An entry is only used while the
+// file's size and mtime still match, so a repeat scan hashes just the new
+// and modified files. Hardlinks share an entry, renamed files keep theirs.
+type HashIndex struct {
+	mu    sync.Mutex
+	files map[fileID]hashEntry
+	limit int
+
+	hits   int64
+	hashed int64
+}
+
+// fileID identifies a file independently of its path
+type fileID struct {
+	Dev   uint64
+	Inode uint64
+}
+
+// hashEntry is what the index knows about one file
+type hashEntry struct {
+	Size    int64
+	ModTime int64
+	Partial string
+	Full    string
+	// Used is when the entry was last looked up (unix seconds), the least
+	// recently used entries are dropped first
+	Used int64
+}
+
+// hashIndexFile is the on-disk layout of the index
+type hashIndexFile struct {
+	Version int
+	Files   map[fileID]hashEntry
+}
+
+// NewHashIndex returns an empty index holding up to DefaultHashIndexLimit files
+func NewHashIndex() *HashIndex {
+	return &HashIndex{
+		files: make(map[fileID]hashEntry),
+		limit: DefaultHashIndexLimit,
+	}
+}
+
+// LoadHashIndex reads an index saved with Save
+// A missing or outdated file yields an empty index
+func LoadHashIndex(path string) (*HashIndex, error) {
+	idx := NewHashIndex()
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return idx, err
+	}
+	defer f.Close()
+
+	var file hashIndexFile
+	if err := gob.NewDecoder(f).Decode(&file); err != nil {
+		return idx, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Version != hashIndexVersion {
+		return idx, nil
+	}
+	if file.Files != nil {
+		idx.files = file.Files
+	}
+	return idx, nil
+}
+
+// SetLimit changes how many files the index keeps when saved
+func (idx *HashIndex) SetLimit(limit int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.limit = limit
+}
+
+// Save writes the index to path, replacing any previous file atomically
+// Entries beyond the limit are dropped, least recently used first.
+func (idx *HashIndex) Save(path string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.evict()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".hashes-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	file := hashIndexFile{Version: hashIndexVersion, Files: idx.files}
+	if err := gob.NewEncoder(tmp).Encode(&file); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// evict drops the least recently used entries beyond the limit
+func (idx *HashIndex) evict() {
+	excess := len(idx.files) - idx.limit
+	if idx.limit <= 0 || excess <= 0 {
+		return
+	}
+
+	ids := make([]fileID, 0, len(idx.files))
+	for id := range idx.files {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return idx.files[ids[i]].Used < idx.files[ids[j]].Used
+	})
+	for _, id := range ids[:excess] {
+		delete(idx.files, id)
+	}
+}
+
+// Len returns the number of files in the index
+func (idx *HashIndex) Len() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return len(idx.files)
+}
+
+// Stats returns how many hashes were reused from the index and how many had
+// to be computed since the index was created or loaded
+func (idx *HashIndex) Stats() (reused, hashed int) {
+	return int(atomic.LoadInt64(&idx.hits)), int(atomic.LoadInt64(&idx.hashed))
+}
+
+// cached wraps a hash function (partialHash or hashFile) so it consults the
+// index first and records what it computes. A nil index always hashes.
+func (idx *HashIndex) cached(hash func(string) (string, error), full bool) func(string) (string, error) {
+	if idx == nil {
+		return hash
+	}
+
+	return func(path string) (string, error) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return hash(path)
+		}
+		id := fileID{Dev: uint64(stat.Dev), Inode: stat.Ino}
+		size, modTime := info.Size(), info.ModTime().UnixNano()
+
+		idx.mu.Lock()
+		entry, ok := idx.files[id]
+		if ok && (entry.Size != size || entry.ModTime != modTime) {
+			// The file changed, or the inode was reused by another file
+			entry, ok = hashEntry{}, false
+		}
+		sum := entry.Partial
+		if full {
+			sum = entry.Full
+		}
+		if ok && sum != "" {
+			entry.Used = time.Now().Unix()
+			idx.files[id] = entry
+			idx.mu.Unlock()
+			atomic.AddInt64(&idx.hits, 1)
+			return sum, nil
+		}
+		idx.mu.Unlock()
+
+		sum, err = hash(path)
+		if err != nil {
+			return "", err
+		}
+		atomic.AddInt64(&idx.hashed, 1)
+
+		idx.mu.Lock()
+		defer idx.mu.Unlock()
+		// Another entry may have been stored for the file meanwhile
+		if current, ok := idx.files[id]; ok && current.Size == size && current.ModTime == modTime {
+			entry = current
+		}
+		entry.Size, entry.ModTime, entry.Used = size, modTime, time.Now().Unix()
+		if full {
+			entry.Full = sum
+		} else {
+			entry.Partial = sum
+		}
+		idx.files[id] = entry
+		return sum, nil
+	}
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHashIndexReusesHashes(t *testing.T) {
+	root := t.TempDir()
+	data := make([]byte, 200*1024)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	var paths []string
+	for _, name := range []string{"a.iso", "b.iso", "c.iso"} {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	idx := NewHashIndex()
+	options := DefaultDuplicatesOptions()
+	options.Hashes = idx
+
+	first := FindDuplicates(root, options, nil)
+	if first.TotalGroups != 1 || first.CachedHashes != 0 {
+		t.Fatalf("first scan = %d groups, %d cached; want 1 group, nothing cached", first.TotalGroups, first.CachedHashes)
+	}
+	_, hashed := idx.Stats()
+	if hashed != 6 {
+		t.Errorf("hashed %d times, want a partial and a full hash per file", hashed)
+	}
+
+	// Saved and loaded, the index answers every lookup
+	indexPath := filepath.Join(t.TempDir(), "hashes.gob")
+	if err := idx.Save(indexPath); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadHashIndex(indexPath)
+	if err != nil {
+		t.Fatalf("LoadHashIndex: %v", err)
+	}
+	options.Hashes = loaded
+
+	second := FindDuplicates(root, options, nil)
+	if second.TotalGroups != 1 || second.CachedHashes != 6 {
+		t.Errorf("second scan = %d groups, %d cached; want 1 group, 6 cached", second.TotalGroups, second.CachedHashes)
+	}
+	if second.Groups[0].Hash != first.Groups[0].Hash {
+		t.Errorf("cached hash %s differs from %s", second.Groups[0].Hash, first.Groups[0].Hash)
+	}
+
+	// A modified file is hashed again and drops out of the group
+	changed := append([]byte(nil), data...)
+	changed[0] ^= 0xff
+	if err := os.WriteFile(paths[2], changed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(paths[2], later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	third := FindDuplicates(root, options, nil)
+	if third.TotalGroups != 1 || len(third.Groups[0].Files) != 2 {
+		t.Fatalf("third scan = %+v, want a group of the two unchanged files", third.Groups)
+	}
+	if _, hashed := loaded.Stats(); hashed != 1 {
+		t.Errorf("hashed %d files after the change, want only the modified one", hashed)
+	}
+}
+
+func TestHashIndexLimit(t *testing.T) {
+	idx := NewHashIndex()
+	idx.SetLimit(2)
+	for i := 0; i < 5; i++ {
+		idx.files[fileID{Inode: uint64(i)}] = hashEntry{Full: "x", Used: int64(i)}
+	}
+
+	indexPath := filepath.Join(t.TempDir(), "hashes.gob")
+	if err := idx.Save(indexPath); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadHashIndex(indexPath)
+	if err != nil {
+		t.Fatalf("LoadHashIndex: %v", err)
+	}
+	if loaded.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", loaded.Len())
+	}
+	for _, inode := range []uint64{3, 4} {
+		if _, ok := loaded.files[fileID{Inode: inode}]; !ok {
+			t.Errorf("recently used inode %d was evicted", inode)
+		}
+	}
+}
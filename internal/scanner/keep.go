@@ -0,0 +1,232 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Strategies for picking the file to keep among a group's candidates
+const (
+	KeepOldest       = "oldest"
+	KeepNewest       = "newest"
+	KeepShortestPath = "shortest-path"
+)
+
+// KeepRules choose the files to keep in duplicate groups, so hundreds of
+// groups can be cleaned in one batch. Roots accept ~, $VAR and globs.
+type KeepRules struct {
+	// Strategy picks one file among the candidates: KeepOldest (the
+	// default), KeepNewest or KeepShortestPath
+	Strategy string `json:"strategy"`
+	// PreferredRoots narrow the candidates to the files under the first
+	// root that has any, e.g. ~/Pictures before ~/Downloads
+	PreferredRoots []string `json:"preferredRoots,omitempty"`
+	// ProtectedRoots hold files that are never deleted
+	ProtectedRoots []string `json:"protectedRoots,omitempty"`
+}
+
+// DuplicateDecision is what KeepRules decided for one group, to be reviewed
+// before it is passed to DeleteDecided
+type DuplicateDecision struct {
+	Hash      string          `json:"hash"`
+	Directory bool            `json:"directory,omitempty"`
+	Keep      []DuplicateFile `json:"keep"`
+	Delete    []DuplicateFile `json:"delete"`
+	// Reason explains why the first file of Keep was chosen
+	Reason    string `json:"reason"`
+	FreedSize int64  `json:"freedSize"`
+}
+
+// DecideDuplicates applies the rules to every group. Files under a
+// protected root are all kept; the chosen file comes from them when a group
+// has any, otherwise from the files under the first matching preferred
+// root, otherwise from the whole group, and the strategy breaks the tie.
+func DecideDuplicates(groups []DuplicateGroup, rules KeepRules) ([]DuplicateDecision, error) {
+	less, err := keepOrder(rules.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	protected := ResolvePaths(rules.ProtectedRoots)
+	preferred := make([][]string, 0, len(rules.PreferredRoots))
+	for _, root := range rules.PreferredRoots {
+		preferred = append(preferred, ExpandPath(root))
+	}
+
+	decisions := make([]DuplicateDecision, 0, len(groups))
+	for _, group := range groups {
+		if len(group.Files) == 0 {
+			continue
+		}
+		decision := DuplicateDecision{Hash: group.Hash, Directory: group.Directory}
+
+		var safe []DuplicateFile
+		for _, file := range group.Files {
+			if underAny(file.Path, protected) {
+				safe = append(safe, file)
+			}
+		}
+
+		candidates, reason := group.Files, "kept the "+rules.strategy()+" file"
+		if len(safe) > 0 {
+			candidates, reason = safe, "under a protected root"
+		} else {
+			for i, roots := range preferred {
+				var under []DuplicateFile
+				for _, file := range group.Files {
+					if underAny(file.Path, roots) {
+						under = append(under, file)
+					}
+				}
+				if len(under) > 0 {
+					candidates, reason = under, "under preferred root "+rules.PreferredRoots[i]
+					break
+				}
+			}
+		}
+
+		candidates = append([]DuplicateFile(nil), candidates...)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return less(candidates[i], candidates[j])
+		})
+		kept := candidates[0]
+		decision.Keep = []DuplicateFile{kept}
+		decision.Reason = reason
+
+		for _, file := range group.Files {
+			switch {
+			case file.Path == kept.Path:
+			case underAny(file.Path, protected):
+				decision.Keep = append(decision.Keep, file)
+			default:
+				decision.Delete = append(decision.Delete, file)
+				decision.FreedSize += file.Size
+			}
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, nil
+}
+
+// strategy returns the strategy in effect
+func (rules KeepRules) strategy() string {
+	if rules.Strategy == "" {
+		return KeepOldest
+	}
+	return rules.Strategy
+}
+
+// keepOrder returns a function ordering files by preference to keep
+// Ties are broken by path, so decisions don't depend on the group order.
+func keepOrder(strategy string) (func(a, b DuplicateFile) bool, error) {
+	switch strategy {
+	case "", KeepOldest:
+		return func(a, b DuplicateFile) bool {
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.Before(b.ModTime)
+			}
+			return a.Path < b.Path
+		}, nil
+	case KeepNewest:
+		return func(a, b DuplicateFile) bool {
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.After(b.ModTime)
+			}
+			return a.Path < b.Path
+		}, nil
+	case KeepShortestPath:
+		return func(a, b DuplicateFile) bool {
+			if len(a.Path) != len(b.Path) {
+				return len(a.Path) < len(b.Path)
+			}
+			return a.Path < b.Path
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown keep strategy %q", strategy)
+}
+
+// underAny reports whether path is one of roots or inside one of them
+func underAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if path == root || isWithin(path, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteDecided deletes the files each decision marks for deletion with
+// the given backend. Decisions whose kept files are missing are refused
+// rather than deleting every copy, and files listed as kept are never
+// deleted. Paths of file decisions that have become directories since the
+// scan are refused too, so only directory groups ever delete a tree.
+func DeleteDecided(decisions []DuplicateDecision, deleter Deleter) CleanResult {
+	return DeleteDecidedWithContext(context.Background(), decisions, deleter)
+}
+
+// DeleteDecidedWithContext is DeleteDecided stopping between files once
+// ctx is done; the result covers the files handled until then
+func DeleteDecidedWithContext(ctx context.Context, decisions []DuplicateDecision, deleter Deleter) CleanResult {
+	result := newDeleteResult(deleter)
+
+	for _, decision := range decisions {
+		if IsCancelled(ctx) {
+			break
+		}
+		kept := make(map[string]bool, len(decision.Keep))
+		present := 0
+		for _, file := range decision.Keep {
+			kept[file.Path] = true
+			if _, err := os.Lstat(file.Path); err == nil {
+				present++
+			}
+		}
+		if present == 0 {
+			for _, file := range decision.Delete {
+				refuseInto(&result, deleter, file, CleanError{
+					Path:    file.Path,
+					Message: "no copy of " + file.Name + " would be kept",
+					Code:    "NOTHING_KEPT",
+				})
+			}
+			continue
+		}
+
+		for _, file := range decision.Delete {
+			if IsCancelled(ctx) {
+				break
+			}
+			if kept[file.Path] {
+				continue
+			}
+			if !decision.Directory {
+				if info, err := os.Lstat(file.Path); err == nil && info.IsDir() {
+					refuseInto(&result, deleter, file, CleanError{
+						Path:    file.Path,
+						Message: file.Path + " is a directory now",
+						Code:    "NOT_A_FILE",
+					})
+					continue
+				}
+			}
+			deleteInto(&result, deleter, file.Path, file.Size)
+		}
+	}
+
+	return result
+}
+
+// refuseInto records in result that file is not deleted because of cleanErr
+func refuseInto(result *CleanResult, deleter Deleter, file DuplicateFile, cleanErr CleanError) {
+	result.Errors = append(result.Errors, cleanErr.Message)
+	result.DetailedErrors = append(result.DetailedErrors, cleanErr)
+	if deleter.DryRun() {
+		result.Plan = append(result.Plan, PlannedDeletion{
+			Path:   file.Path,
+			Size:   file.Size,
+			Action: deleter.Action(),
+			Error:  &cleanErr,
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDecideDuplicates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	day := func(n int) time.Time { return time.Date(2024, 1, n, 0, 0, 0, 0, time.UTC) }
+	file := func(rel string, modTime time.Time) DuplicateFile {
+		return DuplicateFile{Path: filepath.Join(home, rel), Name: filepath.Base(rel), Size: 100, ModTime: modTime}
+	}
+	photos := DuplicateGroup{Hash: "photos", Files: []DuplicateFile{
+		file("Downloads/import/IMG_1.jpg", day(1)),
+		file("Pictures/2024/IMG_1.jpg", day(3)),
+		file("Desktop/IMG_1.jpg", day(2)),
+	}}
+	archived := DuplicateGroup{Hash: "archived", Files: []DuplicateFile{
+		file("Downloads/report.pdf", day(1)),
+		file("Archive/2023/report.pdf", day(2)),
+		file("Archive/2024/report.pdf", day(3)),
+	}}
+	groups := []DuplicateGroup{photos, archived}
+
+	tests := []struct {
+		name  string
+		rules KeepRules
+		keep  map[string][]string // group -> kept paths, chosen one first
+	}{
+		{
+			name:  "oldest by default",
+			rules: KeepRules{},
+			keep: map[string][]string{
+				"photos":   {"Downloads/import/IMG_1.jpg"},
+				"archived": {"Downloads/report.pdf"},
+			},
+		},
+		{
+			name:  "newest",
+			rules: KeepRules{Strategy: KeepNewest},
+			keep: map[string][]string{
+				"photos":   {"Pictures/2024/IMG_1.jpg"},
+				"archived": {"Archive/2024/report.pdf"},
+			},
+		},
+		{
+			name:  "shortest path",
+			rules: KeepRules{Strategy: KeepShortestPath},
+			keep: map[string][]string{
+				"photos":   {"Desktop/IMG_1.jpg"},
+				"archived": {"Downloads/report.pdf"},
+			},
+		},
+		{
+			name:  "preferred roots in order",
+			rules: KeepRules{PreferredRoots: []string{"~/Music", "~/Pictures", "~/Desktop"}},
+			keep: map[string][]string{
+				"photos":   {"Pictures/2024/IMG_1.jpg"},
+				"archived": {"Downloads/report.pdf"},
+			},
+		},
+		{
+			name:  "protected roots are never deleted",
+			rules: KeepRules{Strategy: KeepNewest, PreferredRoots: []string{"~/Downloads"}, ProtectedRoots: []string{"~/Archive"}},
+			keep: map[string][]string{
+				"photos":   {"Downloads/import/IMG_1.jpg"},
+				"archived": {"Archive/2024/report.pdf", "Archive/2023/report.pdf"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions, err := DecideDuplicates(groups, tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(decisions) != len(groups) {
+				t.Fatalf("got %d decisions, want %d", len(decisions), len(groups))
+			}
+			for _, decision := range decisions {
+				want := tt.keep[decision.Hash]
+				if len(decision.Keep) != len(want) {
+					t.Fatalf("%s: kept %+v, want %v", decision.Hash, decision.Keep, want)
+				}
+				for i, rel := range want {
+					if decision.Keep[i].Path != filepath.Join(home, rel) {
+						t.Errorf("%s: kept[%d] = %s, want %s", decision.Hash, i, decision.Keep[i].Path, rel)
+					}
+				}
+				if len(decision.Keep)+len(decision.Delete) != 3 {
+					t.Errorf("%s: keep %d + delete %d files, want 3", decision.Hash, len(decision.Keep), len(decision.Delete))
+				}
+				if decision.FreedSize != int64(len(decision.Delete))*100 {
+					t.Errorf("%s: freed %d for %d deletions", decision.Hash, decision.FreedSize, len(decision.Delete))
+				}
+			}
+		})
+	}
+
+	if _, err := DecideDuplicates(groups, KeepRules{Strategy: "largest"}); err == nil {
+		t.Error("unknown strategy accepted")
+	}
+}
+
+func TestDeleteDecided(t *testing.T) {
+	root := t.TempDir()
+	paths := make([]string, 3)
+	for i, name := range []string{"keep.txt", "copy1.txt", "copy2.txt"} {
+		paths[i] = filepath.Join(root, name)
+		if err := os.WriteFile(paths[i], []byte("same"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := func(paths ...string) []DuplicateFile {
+		var files []DuplicateFile
+		for _, path := range paths {
+			files = append(files, DuplicateFile{Path: path, Name: filepath.Base(path), Size: 4})
+		}
+		return files
+	}
+
+	// A decision whose kept copy is gone deletes nothing
+	missing := filepath.Join(root, "gone.txt")
+	result := DeleteDecided([]DuplicateDecision{{Keep: files(missing), Delete: files(paths[1])}}, removingDeleter{})
+	if len(result.DetailedErrors) != 1 || result.DetailedErrors[0].Code != "NOTHING_KEPT" {
+		t.Errorf("errors = %+v, want NOTHING_KEPT", result.DetailedErrors)
+	}
+	if _, err := os.Stat(paths[1]); err != nil {
+		t.Errorf("copy deleted although nothing is kept: %v", err)
+	}
+
+	// Kept files listed for deletion by mistake survive
+	result = DeleteDecided([]DuplicateDecision{{Keep: files(paths[0]), Delete: files(paths[0], paths[1], paths[2])}}, removingDeleter{})
+	if len(result.DeletedPaths) != 2 || result.FreedBytes != 8 {
+		t.Errorf("result = %+v, want the 2 copies deleted", result)
+	}
+	if _, err := os.Stat(paths[0]); err != nil {
+		t.Errorf("kept file deleted: %v", err)
+	}
+	// A file replaced by a directory since the scan is left alone
+	replaced := filepath.Join(root, "copy3.txt")
+	if err := os.MkdirAll(filepath.Join(replaced, "work"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	result = DeleteDecided([]DuplicateDecision{{Keep: files(paths[0]), Delete: files(replaced)}}, removingDeleter{})
+	if len(result.DetailedErrors) != 1 || result.DetailedErrors[0].Code != "NOT_A_FILE" {
+		t.Errorf("errors = %+v, want NOT_A_FILE", result.DetailedErrors)
+	}
+	if _, err := os.Stat(filepath.Join(replaced, "work")); err != nil {
+		t.Errorf("directory deleted as a duplicate file: %v", err)
+	}
+
+	// Nothing is deleted once the context is cancelled
+	copy4 := filepath.Join(root, "copy4.txt")
+	if err := os.WriteFile(copy4, []byte("same"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result = DeleteDecidedWithContext(ctx, []DuplicateDecision{{Keep: files(paths[0]), Delete: files(copy4)}}, removingDeleter{})
+	if len(result.DeletedPaths) != 0 {
+		t.Errorf("deleted %v after cancellation", result.DeletedPaths)
+	}
+	if _, err := os.Stat(copy4); err != nil {
+		t.Errorf("copy deleted after cancellation: %v", err)
+	}
+}
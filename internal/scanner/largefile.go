@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"sort"
@@ -26,6 +27,7 @@ type LargeFilesResult struct {
 	TotalCount   int           `json:"totalCount"`
 	ScanDuration time.Duration `json:"scanDuration"`
 	Threshold    int64         `json:"threshold"`
+	Excluded     []Exclusion   `json:"excluded,omitempty"`
 }
 
 // LargeFilesOptions configures the large file scan
@@ -36,10 +38,14 @@ type LargeFilesOptions struct {
 	MaxResults int
 	// IncludeDirectories includes directories in results
 	IncludeDirectories bool
-	// ExcludePatterns are glob patterns to exclude
+	// ExcludePatterns are gitignore-style patterns to exclude, applied on
+	// top of the persistent exclusions set with SetExcluder
 	ExcludePatterns []string
 	// FileTypes filters by extension (e.g., ".dmg", ".zip")
 	FileTypes []string
+	// OnFile is called for every match as soon as it is found, before
+	// results are sorted and limited to MaxResults
+	OnFile func(LargeFile)
 }
 
 // DefaultLargeFilesOptions returns sensible defaults
@@ -50,11 +56,11 @@ func DefaultLargeFilesOptions() LargeFilesOptions {
 		IncludeDirectories: false,
 		ExcludePatterns: []string{
 			".Trash",
-			"Library/Caches",
+			"**/Library/Caches",
 			"node_modules",
 			".git",
-			"Library/Group Containers",
-			"Library/Containers/com.docker.docker",
+			"**/Library/Group Containers",
+			"**/Library/Containers/com.docker.docker",
 			".orbstack",
 			".docker",
 			".lima",
@@ -65,6 +71,12 @@ func DefaultLargeFilesOptions() LargeFilesOptions {
 
 // FindLargeFiles scans for files larger than the specified threshold
 func FindLargeFiles(rootPath string, options LargeFilesOptions, progressCallback func(scanned int, currentPath string)) LargeFilesResult {
+	return FindLargeFilesWithContext(context.Background(), rootPath, options, progressCallback)
+}
+
+// FindLargeFilesWithContext is FindLargeFiles stopping once ctx is done,
+// with the files found so far
+func FindLargeFilesWithContext(ctx context.Context, rootPath string, options LargeFilesOptions, progressCallback func(scanned int, currentPath string)) LargeFilesResult {
 	startTime := time.Now()
 
 	if rootPath == "" {
@@ -74,9 +86,14 @@ func FindLargeFiles(rootPath string, options LargeFilesOptions, progressCallback
 	files := make([]LargeFile, 0)
 	var mu sync.Mutex
 	var scanned int
+	ex := CurrentExcluder().WithPatterns(options.ExcludePatterns).Rooted(rootPath)
+	var excluded exclusionLog
 
 	// Walk the directory tree
 	_ = filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if IsCancelled(ctx) {
+			return filepath.SkipAll
+		}
 		if err != nil {
 			return nil
 		}
@@ -92,14 +109,13 @@ func FindLargeFiles(rootPath string, options LargeFilesOptions, progressCallback
 			return nil
 		}
 
-		// Skip excluded patterns
-		for _, pattern := range options.ExcludePatterns {
-			if strings.Contains(path, pattern) {
-				if linfo.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
+		// Skip excluded paths
+		if rule, ok := ex.Match(path, linfo.IsDir()); ok {
+			excluded.add(path, rule)
+			if linfo.IsDir() {
+				return filepath.SkipDir
 			}
+			return nil
 		}
 
 		// Skip hidden files/directories (except root)
@@ -122,17 +138,21 @@ func FindLargeFiles(rootPath string, options LargeFilesOptions, progressCallback
 				return nil
 			}
 			// For directories, calculate total size
-			dirSize := calculateDirSize(path)
+			dirSize := calculateDirSize(path, ex)
 			if dirSize >= options.MinSize {
-				mu.Lock()
-				files = append(files, LargeFile{
+				file := LargeFile{
 					Path:    path,
 					Name:    name,
 					Size:    dirSize,
 					ModTime: linfo.ModTime(),
 					IsDir:   true,
-				})
+				}
+				mu.Lock()
+				files = append(files, file)
 				mu.Unlock()
+				if options.OnFile != nil {
+					options.OnFile(file)
+				}
 			}
 			return nil
 		}
@@ -166,15 +186,19 @@ func FindLargeFiles(rootPath string, options LargeFilesOptions, progressCallback
 			}
 		}
 
-		mu.Lock()
-		files = append(files, LargeFile{
+		file := LargeFile{
 			Path:    path,
 			Name:    name,
 			Size:    fileSize,
 			ModTime: linfo.ModTime(),
 			IsDir:   false,
-		})
+		}
+		mu.Lock()
+		files = append(files, file)
 		mu.Unlock()
+		if options.OnFile != nil {
+			options.OnFile(file)
+		}
 
 		return nil
 	})
@@ -201,12 +225,14 @@ func FindLargeFiles(rootPath string, options LargeFilesOptions, progressCallback
 		TotalCount:   len(files),
 		ScanDuration: time.Since(startTime),
 		Threshold:    options.MinSize,
+		Excluded:     excluded.list(),
 	}
 }
 
 // calculateDirSize calculates the total size of a directory
-// It skips symlinks and uses actual disk blocks for sparse files
-func calculateDirSize(path string) int64 {
+// It skips symlinks and excluded paths and uses actual disk blocks for
+// sparse files
+func calculateDirSize(path string, ex *Excluder) int64 {
 	var size int64
 	_ = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -221,6 +247,12 @@ func calculateDirSize(path string) int64 {
 		if linfo.Mode()&os.ModeSymlink != 0 {
 			return nil
 		}
+		if _, ok := ex.Match(p, linfo.IsDir()); ok && p != path {
+			if linfo.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if !linfo.IsDir() {
 			// Use actual disk blocks for sparse file support
 			if stat, ok := linfo.Sys().(*syscall.Stat_t); ok {
@@ -13,10 +13,12 @@ import (
 
 // NormalScanner scans the entire filesystem hierarchically
 type NormalScanner struct {
-	workers  int
-	callback ProgressCallback
-	ctx      context.Context
-	cancel   context.CancelFunc
+	workers      int
+	callback     ProgressCallback
+	nodeCallback NodeCallback
+	fingerprints *FingerprintIndex
+	ctx          context.Context
+	cancel       context.CancelFunc
 }
 
 // NewNormalScanner creates a new NormalScanner with the specified number of workers
@@ -34,6 +36,25 @@ func (s *NormalScanner) SetProgressCallback(callback ProgressCallback) {
 	s.callback = callback
 }
 
+// SetNodeCallback sets a callback that receives each child node as soon as
+// its size is known, before the tree is complete
+func (s *NormalScanner) SetNodeCallback(callback NodeCallback) {
+	s.nodeCallback = callback
+}
+
+// SetFingerprintIndex enables incremental scans
+// Directories whose fingerprint in the index is unchanged are not read
+// again; the index is updated as the scan goes. Pass nil for full scans.
+func (s *NormalScanner) SetFingerprintIndex(idx *FingerprintIndex) {
+	s.fingerprints = idx
+}
+
+// Invalidate makes the next scan read dirs from disk rather than reuse
+// their listings from the fingerprint index
+func (s *NormalScanner) Invalidate(dirs ...string) {
+	s.fingerprints.Invalidate(dirs...)
+}
+
 // SetContext sets the context for cancellation support
 func (s *NormalScanner) SetContext(ctx context.Context) {
 	s.ctx, s.cancel = context.WithCancel(ctx)
@@ -65,20 +86,28 @@ func (s *NormalScanner) ScanPath(rootPath string) FullScanResult {
 	start := time.Now()
 
 	// Build the tree with immediate children
-	root := s.buildTree(rootPath)
+	var excluded exclusionLog
+	root := s.buildTree(rootPath, &excluded)
+
+	// Forget directories that are gone, unless the scan was cut short
+	if s.fingerprints != nil && !IsCancelled(s.ctx) {
+		s.fingerprints.Prune(rootPath)
+	}
 
 	return FullScanResult{
 		Mode:         ModeNormal,
 		Root:         root,
 		TotalSize:    root.Size,
 		ScanDuration: time.Since(start),
+		Excluded:     excluded.list(),
 	}
 }
 
 // buildTree builds a FileNode tree for the given path
 // It scans immediate children and calculates their sizes concurrently
 // Symlinks are skipped to avoid double-counting files
-func (s *NormalScanner) buildTree(rootPath string) *FileNode {
+// Excluded paths are skipped and recorded in excluded
+func (s *NormalScanner) buildTree(rootPath string, excluded *exclusionLog) *FileNode {
 	// Use Lstat to not follow symlinks
 	info, err := os.Lstat(rootPath)
 	if err != nil {
@@ -118,12 +147,19 @@ func (s *NormalScanner) buildTree(rootPath string) *FileNode {
 		return root
 	}
 
-	// Filter out symlinks first
+	// Filter out symlinks and excluded paths first
+	ex := CurrentExcluder().Rooted(rootPath)
 	var realEntries []os.DirEntry
 	for _, entry := range entries {
-		if entry.Type()&os.ModeSymlink == 0 {
-			realEntries = append(realEntries, entry)
+		if entry.Type()&os.ModeSymlink != 0 {
+			continue
+		}
+		childPath := filepath.Join(rootPath, entry.Name())
+		if rule, ok := ex.Match(childPath, entry.IsDir()); ok {
+			excluded.add(childPath, rule)
+			continue
 		}
+		realEntries = append(realEntries, entry)
 	}
 
 	// Scan children concurrently
@@ -164,8 +200,9 @@ func (s *NormalScanner) buildTree(rootPath string) *FileNode {
 
 				if entry.IsDir() {
 					// Calculate directory size using fast parallel walker
-					result := WalkDirectoryFast(childPath, 4)
+					result := walkDirectoryFast(childPath, 4, ex, s.fingerprints)
 					node.Size = result.Size
+					excluded.merge(result.Excluded)
 				} else {
 					// Use actual disk blocks for sparse file support
 					if stat, ok := childInfo.Sys().(*syscall.Stat_t); ok {
@@ -177,6 +214,10 @@ func (s *NormalScanner) buildTree(rootPath string) *FileNode {
 
 				results <- childResult{index: i, node: node}
 
+				if s.nodeCallback != nil && !IsCancelled(s.ctx) {
+					s.nodeCallback(node)
+				}
+
 				// Report progress
 				if s.callback != nil && !IsCancelled(s.ctx) {
 					s.callback(ScanProgress{
@@ -254,12 +295,17 @@ func (s *NormalScanner) GetDirectoryChildren(path string) ([]*FileNode, error) {
 		return nil, err
 	}
 
-	// Filter out symlinks first
+	// Filter out symlinks and excluded paths first
+	ex := CurrentExcluder().Rooted(path)
 	var realEntries []os.DirEntry
 	for _, entry := range entries {
-		if entry.Type()&os.ModeSymlink == 0 {
-			realEntries = append(realEntries, entry)
+		if entry.Type()&os.ModeSymlink != 0 {
+			continue
+		}
+		if _, ok := ex.Match(filepath.Join(path, entry.Name()), entry.IsDir()); ok {
+			continue
 		}
+		realEntries = append(realEntries, entry)
 	}
 
 	// Scan children concurrently
@@ -295,7 +341,7 @@ func (s *NormalScanner) GetDirectoryChildren(path string) ([]*FileNode, error) {
 
 				if entry.IsDir() {
 					// Use fast parallel walker for subdirectories
-					result := WalkDirectoryFast(childPath, 4)
+					result := walkDirectoryFast(childPath, 4, ex, s.fingerprints)
 					node.Size = result.Size
 				} else {
 					// Use actual disk blocks for sparse file support
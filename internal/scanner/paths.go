@@ -0,0 +1,71 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandPath resolves a category path pattern into concrete paths
+// It supports a leading ~, $VAR, ${VAR} and ${VAR:-default} expansion, and
+// glob patterns (*, ?, [...]). Patterns that reference an unset variable
+// without a default, or globs that match nothing, resolve to no paths.
+// Plain paths are returned even if they don't exist.
+func ExpandPath(pattern string) []string {
+	missing := false
+	p := os.Expand(pattern, func(name string) string {
+		fallback, hasDefault := "", false
+		if i := strings.Index(name, ":-"); i >= 0 {
+			name, fallback, hasDefault = name[:i], name[i+2:], true
+		}
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		if !hasDefault {
+			missing = true
+		}
+		return fallback
+	})
+	if missing || p == "" {
+		return nil
+	}
+
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		p = home + p[1:]
+	}
+	p = filepath.Clean(p)
+
+	if !IsGlobPattern(p) {
+		return []string{p}
+	}
+	matches, err := filepath.Glob(p)
+	if err != nil {
+		return nil
+	}
+	return matches
+}
+
+// IsGlobPattern reports whether a path contains glob metacharacters
+func IsGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// ResolvePaths expands every pattern and returns the unique concrete paths
+// in their original order
+func ResolvePaths(patterns []string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		for _, p := range ExpandPath(pattern) {
+			if !seen[p] {
+				seen[p] = true
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a-1", "a-2", "b"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("DISK_PEEK_TEST_DIR", dir)
+	t.Setenv("DISK_PEEK_TEST_UNSET", "")
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{"plain path", filepath.Join(dir, "b"), []string{filepath.Join(dir, "b")}},
+		{"missing plain path is kept", filepath.Join(dir, "missing"), []string{filepath.Join(dir, "missing")}},
+		{"env var", "$DISK_PEEK_TEST_DIR/b", []string{filepath.Join(dir, "b")}},
+		{"braced env var", "${DISK_PEEK_TEST_DIR}/b", []string{filepath.Join(dir, "b")}},
+		{"default unused", "${DISK_PEEK_TEST_DIR:-/nope}/b", []string{filepath.Join(dir, "b")}},
+		{"default used", "${DISK_PEEK_TEST_UNSET:-" + dir + "}/b", []string{filepath.Join(dir, "b")}},
+		{"unset without default", "$DISK_PEEK_TEST_UNSET/b", nil},
+		{"glob", filepath.Join(dir, "a-*"), []string{filepath.Join(dir, "a-1"), filepath.Join(dir, "a-2")}},
+		{"glob without matches", filepath.Join(dir, "z-*"), nil},
+		{"glob with trailing slash", filepath.Join(dir, "a-*") + "/", []string{filepath.Join(dir, "a-1"), filepath.Join(dir, "a-2")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExpandPath(tt.pattern)
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ExpandPath(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ExpandPath(%q)[%d] = %s, want %s", tt.pattern, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("tilde", func(t *testing.T) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			t.Skip("no home directory")
+		}
+		got := ExpandPath("~/.cache")
+		if len(got) != 1 || got[0] != filepath.Join(home, ".cache") {
+			t.Errorf("ExpandPath(~/.cache) = %v", got)
+		}
+	})
+}
+
+func TestResolvePaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"x", "y"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := ResolvePaths([]string{
+		filepath.Join(dir, "x"),
+		filepath.Join(dir, "*"),
+	})
+	want := []string{filepath.Join(dir, "x"), filepath.Join(dir, "y")}
+	if len(got) != len(want) {
+		t.Fatalf("ResolvePaths = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ResolvePaths[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package scanner
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// What a shredded file was stored on, see ShredReport
+const (
+	StorageDisk        = "disk"
+	StorageFlash       = "flash"
+	StorageCopyOnWrite = "copy-on-write"
+	StorageMemory      = "memory"
+	StorageUnknown     = "unknown"
+)
+
+// ShredReport is the audit record of one file removed by Shred
+type ShredReport struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Storage is what the file was stored on, one of the Storage constants
+	Storage string `json:"storage"`
+	// Overwritten is set when the contents were replaced with random data
+	// and flushed to the device before the file was unlinked
+	Overwritten bool `json:"overwritten"`
+	// Verified is set when reading the file back from the device returned
+	// the random data
+	Verified bool `json:"verified"`
+	// Warning explains why copies of the old data may survive
+	Warning string `json:"warning,omitempty"`
+	Removed bool   `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// shredChunk is how much of an overwrite is read back at a time
+const shredChunk = 1 << 20
+
+// Shred permanently deletes path, first overwriting every regular file in
+// it with random data where that destroys the old contents. On
+// copy-on-write and log-structured filesystems an overwrite lands in new
+// blocks, so files there are only unlinked; files with other hard links are
+// left intact as well. Each file gets a report saying what was done and,
+// when the old data may survive, why.
+func Shred(path string) ([]ShredReport, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		report := shredFile(path, info)
+		if !report.Removed {
+			return []ShredReport{report}, fmt.Errorf("shred %s: %s", path, report.Error)
+		}
+		return []ShredReport{report}, nil
+	}
+
+	reports := []ShredReport{}
+	failed := 0
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		report := shredFile(p, info)
+		if !report.Removed {
+			failed++
+		}
+		reports = append(reports, report)
+		return nil
+	})
+	// Never remove what couldn't be walked, it was not overwritten
+	if err != nil {
+		return reports, err
+	}
+	if failed > 0 {
+		return reports, fmt.Errorf("shred %s: %d files could not be removed", path, failed)
+	}
+	return reports, os.RemoveAll(path)
+}
+
+// shredFile overwrites a regular file if that is meaningful and removes it
+func shredFile(path string, info os.FileInfo) ShredReport {
+	report, overwritable := planShredFile(path, info)
+	if overwritable {
+		verified, err := overwrite(path, info)
+		switch {
+		case err != nil:
+			report.Warning = "not overwritten: " + err.Error()
+		case !verified && report.Warning == "":
+			report.Warning = "the overwrite could not be read back from the device"
+		}
+		report.Overwritten = err == nil
+		report.Verified = verified
+	}
+
+	if err := removeObscured(path); err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	report.Removed = true
+	return report
+}
+
+// planShredFile reports what path is stored on and whether overwriting it
+// destroys its data
+func planShredFile(path string, info os.FileInfo) (ShredReport, bool) {
+	report := ShredReport{Path: path, Size: info.Size()}
+	report.Storage, report.Warning = storageOf(path, info)
+
+	switch {
+	case !info.Mode().IsRegular(), report.Storage == StorageCopyOnWrite:
+		return report, false
+	case linkCount(info) > 1:
+		report.Warning = "other hard links share the data, so it is left intact"
+		return report, false
+	}
+	return report, true
+}
+
+// PlanShred reports, without touching anything, what Shred would find for
+// each file of path: where it is stored and why its data may survive
+func PlanShred(path string) []ShredReport {
+	reports := []ShredReport{}
+	_ = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			report, _ := planShredFile(p, info)
+			reports = append(reports, report)
+		}
+		return nil
+	})
+	return reports
+}
+
+// overwrite replaces the contents of path with random data, flushes it to
+// the device and reads it back. It reports whether the read came from the
+// device rather than the page cache and matched.
+func overwrite(path string, info os.FileInfo) (verified bool, err error) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if os.IsPermission(err) {
+		// Read-only files such as private keys can still be overwritten by
+		// their owner
+		if os.Chmod(path, info.Mode().Perm()|0200) == nil {
+			f, err = os.OpenFile(path, os.O_WRONLY, 0)
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var seed [32]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		f.Close()
+		return false, err
+	}
+	if _, err := io.CopyN(f, rand.NewChaCha8(seed), info.Size()); err != nil {
+		f.Close()
+		return false, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return false, err
+	}
+	if err := f.Close(); err != nil {
+		return false, err
+	}
+
+	fromDevice := dropCache(path)
+	matched, err := readsBack(path, seed, info.Size())
+	if err != nil {
+		return false, err
+	}
+	if !matched {
+		return false, fmt.Errorf("%s reads back different data than was written", path)
+	}
+	return fromDevice, nil
+}
+
+// readsBack compares path with size bytes of the stream seeded by seed
+func readsBack(path string, seed [32]byte, size int64) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	want := rand.NewChaCha8(seed)
+	got := make([]byte, shredChunk)
+	expected := make([]byte, shredChunk)
+	for remaining := size; remaining > 0; {
+		n := int64(len(got))
+		if remaining < n {
+			n = remaining
+		}
+		if _, err := io.ReadFull(f, got[:n]); err != nil {
+			return false, err
+		}
+		want.Read(expected[:n])
+		if !bytes.Equal(got[:n], expected[:n]) {
+			return false, nil
+		}
+		remaining -= n
+	}
+	return true, nil
+}
+
+// removeObscured renames path to a random name before unlinking it, so the
+// original name doesn't linger in the directory
+func removeObscured(path string) error {
+	var name [8]byte
+	if _, err := crand.Read(name[:]); err != nil {
+		return os.Remove(path)
+	}
+	obscured := filepath.Join(filepath.Dir(path), "."+hex.EncodeToString(name[:]))
+	if err := os.Rename(path, obscured); err != nil {
+		return os.Remove(path)
+	}
+	if err := os.Remove(obscured); err != nil {
+		// Leave the file where the caller expects it
+		_ = os.Rename(obscured, path)
+		return err
+	}
+	return nil
+}
+
+// linkCount returns the number of hard links to a file
+func linkCount(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Nlink)
+	}
+	return 1
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// zfsSuperMagic is missing from x/sys/unix as ZFS is not in the kernel tree
+const zfsSuperMagic = 0x2fc12fc1
+
+// storageOf tells what path is stored on and warns when overwriting it may
+// leave the old data behind
+func storageOf(path string, info os.FileInfo) (storage, warning string) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(path, &fs); err == nil {
+		switch uint32(fs.Type) {
+		case unix.BTRFS_SUPER_MAGIC, unix.BCACHEFS_SUPER_MAGIC, zfsSuperMagic:
+			return StorageCopyOnWrite, "copy-on-write filesystem: overwrites go to new blocks, the old data stays on disk until the space is reused"
+		case unix.F2FS_SUPER_MAGIC, unix.NILFS_SUPER_MAGIC:
+			return StorageCopyOnWrite, "log-structured filesystem: overwrites go to new blocks, the old data stays on disk until the space is reused"
+		case unix.TMPFS_MAGIC, unix.RAMFS_MAGIC:
+			return StorageMemory, ""
+		case unix.NFS_SUPER_MAGIC, unix.SMB_SUPER_MAGIC, unix.SMB2_SUPER_MAGIC, unix.CIFS_SUPER_MAGIC, unix.FUSE_SUPER_MAGIC:
+			return StorageUnknown, "network or FUSE filesystem: where overwrites land is up to the server"
+		case unix.OVERLAYFS_SUPER_MAGIC:
+			return StorageUnknown, "overlay filesystem: a copy in a lower layer is not overwritten"
+		}
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return StorageUnknown, "could not tell what the file is stored on"
+	}
+	switch rotational(uint64(stat.Dev)) {
+	case "1":
+		return StorageDisk, ""
+	case "0":
+		return StorageFlash, "flash storage remaps writes: old copies may survive in spare blocks until the drive erases them; only full-disk encryption guards against that"
+	default:
+		return StorageUnknown, "could not tell what the file is stored on"
+	}
+}
+
+// rotational reads the rotational flag of a block device from sysfs: "1"
+// for spinning disks, "0" for flash. Partitions take it from their disk.
+func rotational(dev uint64) string {
+	base := fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(dev), unix.Minor(dev))
+	for _, path := range []string{base + "/queue/rotational", base + "/../queue/rotational"} {
+		if data, err := os.ReadFile(path); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	return ""
+}
+
+// dropCache evicts path from the page cache, so the next read comes from
+// the device
+func dropCache(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED) == nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package scanner
+
+import "os"
+
+// storageOf can only inspect the storage on Linux
+func storageOf(path string, info os.FileInfo) (storage, warning string) {
+	return StorageUnknown, "could not tell what the file is stored on; on copy-on-write filesystems such as APFS overwrites don't reach the old data"
+}
+
+// dropCache is only implemented on Linux; reads may come from the cache
+func dropCache(path string) bool {
+	return false
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverwrite(t *testing.T) {
+	secret := bytes.Repeat([]byte("hunter2 "), 300000)
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, secret, 0400); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := overwrite(path, info); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != len(secret) {
+		t.Errorf("size changed from %d to %d", len(secret), len(data))
+	}
+	if bytes.Contains(data, []byte("hunter2")) {
+		t.Error("secret survived the overwrite")
+	}
+}
+
+func TestShred(t *testing.T) {
+	root := t.TempDir()
+	tree := filepath.Join(root, "dump")
+	if err := os.MkdirAll(filepath.Join(tree, "tables"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{"customers.sql": 5000, "tables/orders.sql": 3000} {
+		if err := os.WriteFile(filepath.Join(tree, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Overwriting a hard linked file would destroy the other name's data too
+	kept := filepath.Join(root, "kept.sql")
+	if err := os.WriteFile(kept, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(kept, filepath.Join(tree, "linked.sql")); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := PlanShred(tree)
+	if len(plan) != 3 {
+		t.Fatalf("PlanShred = %+v, want 3 files", plan)
+	}
+	if _, err := os.Stat(tree); err != nil {
+		t.Fatalf("PlanShred touched the tree: %v", err)
+	}
+
+	reports, err := Shred(tree)
+	if err != nil {
+		t.Fatalf("Shred: %v", err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("reports = %+v, want 3 files", reports)
+	}
+	for _, report := range reports {
+		if !report.Removed || report.Storage == "" {
+			t.Errorf("report = %+v, want a removed file with its storage", report)
+		}
+		linked := filepath.Base(report.Path) == "linked.sql"
+		want := !linked && report.Storage != StorageCopyOnWrite
+		if report.Overwritten != want {
+			t.Errorf("%s overwritten = %v, want %v", report.Path, report.Overwritten, want)
+		}
+		if linked && report.Warning == "" {
+			t.Errorf("%s has no warning about its other link", report.Path)
+		}
+	}
+
+	if _, err := os.Lstat(tree); !os.IsNotExist(err) {
+		t.Errorf("%s survived Shred: %v", tree, err)
+	}
+	if data, err := os.ReadFile(kept); err != nil || string(data) != "keep me" {
+		t.Errorf("hard link target = %q, %v; want it untouched", data, err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"sort"
 	"time"
+
+	"disk-peek/internal/xdg"
 )
 
 // DiskUsageSnapshot represents disk usage at a point in time
@@ -47,13 +49,11 @@ type TrendsManager struct {
 
 // NewTrendsManager creates a new trends manager
 func NewTrendsManager() (*TrendsManager, error) {
-	home, err := os.UserHomeDir()
+	dataPath, err := xdg.DataFile("trends.json")
 	if err != nil {
 		return nil, err
 	}
 
-	dataPath := filepath.Join(home, ".config", "disk-peek", "trends.json")
-
 	tm := &TrendsManager{
 		dataPath:  dataPath,
 		snapshots: []DiskUsageSnapshot{},
@@ -22,6 +22,9 @@ type Category struct {
 	Icon        string     `json:"icon"`
 	Color       string     `json:"color"`
 	Paths       []string   `json:"-"` // Don't expose raw paths to frontend
+	// ItemPattern, if set, makes the items of a path the directories below
+	// it whose name matches, at any depth, rather than its immediate children
+	ItemPattern string     `json:"-"`
 	Size        int64      `json:"size"`
 	ItemCount   int        `json:"itemCount"`
 	Children    []Category `json:"children,omitempty"`
@@ -36,6 +39,10 @@ type FileNode struct {
 	IsDir    bool        `json:"isDir"`
 	ModTime  time.Time   `json:"modTime,omitempty"`
 	Children []*FileNode `json:"children,omitempty"`
+	// Newest access and modification times of any file in the subtree,
+	// only set for dev category items
+	LastAccess   time.Time `json:"lastAccess,omitempty"`
+	LastModified time.Time `json:"lastModified,omitempty"`
 }
 
 // ScanResult is the unified result for Dev Mode scans
@@ -44,6 +51,7 @@ type ScanResult struct {
 	Categories   []Category    `json:"categories"`
 	TotalSize    int64         `json:"totalSize"`
 	ScanDuration time.Duration `json:"scanDuration"`
+	Excluded     []Exclusion   `json:"excluded,omitempty"`
 }
 
 // FullScanResult is the result for Normal Mode scans
@@ -52,6 +60,7 @@ type FullScanResult struct {
 	Root         *FileNode     `json:"root"`
 	TotalSize    int64         `json:"totalSize"`
 	ScanDuration time.Duration `json:"scanDuration"`
+	Excluded     []Exclusion   `json:"excluded,omitempty"`
 }
 
 // ScanProgress reports scan progress to the frontend
@@ -75,6 +84,74 @@ type CleanResult struct {
 	DeletedPaths  []string     `json:"deletedPaths"`
 	Errors        []string     `json:"errors,omitempty"`
 	DetailedErrors []CleanError `json:"detailedErrors,omitempty"`
+	// OperationID identifies the journal entry for restoring trashed paths
+	OperationID   string       `json:"operationId,omitempty"`
+	// DryRun results describe what would happen; nothing was deleted
+	DryRun        bool              `json:"dryRun,omitempty"`
+	Plan          []PlannedDeletion `json:"plan,omitempty"`
+	// Safety holds the git verdicts of the deleted paths, when they were
+	// checked; dry runs carry them on each Plan entry instead
+	Safety        []PathSafety      `json:"safety,omitempty"`
+	// DedupedPaths are the duplicates replaced by links to the kept file,
+	// FreedBytes then counts the data that is no longer stored twice
+	DedupedPaths  []string          `json:"dedupedPaths,omitempty"`
+	// Shredded reports, file by file, how shredded paths were overwritten
+	Shredded      []ShredReport     `json:"shredded,omitempty"`
+}
+
+// Actions a cleanup takes on a path
+const (
+	ActionTrash  = "trash"
+	ActionRemove = "remove"
+	// ActionReflink and ActionHardlink keep a duplicate's path but share the
+	// data of the kept file, see DedupeDuplicates
+	ActionReflink  = "reflink"
+	ActionHardlink = "hardlink"
+	// ActionShred overwrites files before removing them, see Shred
+	ActionShred = "shred"
+)
+
+// PlannedDeletion is one path a dry run would delete
+type PlannedDeletion struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Action string `json:"action"`
+	// Error is a problem detected up front that would stop the deletion
+	Error *CleanError `json:"error,omitempty"`
+	// Safety is the git verdict on the path, when it was checked
+	Safety *PathSafety `json:"safety,omitempty"`
+}
+
+// Safety levels of deleting a path, judged by its git repository
+const (
+	// SafetyUnknown: not inside a git repository, or git is unavailable
+	SafetyUnknown = "unknown"
+	// SafetySafe: ignored by git, nothing would be lost
+	SafetySafe = "safe"
+	// SafetyWarning: holds untracked files that git does not ignore
+	SafetyWarning = "warning"
+	// SafetyDanger: holds files tracked by git
+	SafetyDanger = "danger"
+)
+
+// PathSafety is the verdict of inspecting the git repository around a path
+// before deleting it
+type PathSafety struct {
+	Path  string `json:"path"`
+	Level string `json:"level"`
+	// Repo is the root of the enclosing work tree
+	Repo    string `json:"repo,omitempty"`
+	Ignored bool   `json:"ignored"`
+	// TrackedFiles are files below the path that git tracks, ModifiedFiles
+	// the ones among them with uncommitted changes
+	TrackedFiles  int `json:"trackedFiles"`
+	ModifiedFiles int `json:"modifiedFiles"`
+	// UntrackedFiles are files below the path neither tracked nor ignored
+	UntrackedFiles int `json:"untrackedFiles"`
+	// RepoDirty is set when the repository has uncommitted changes anywhere
+	RepoDirty bool `json:"repoDirty"`
+	// Reasons explain the level for display
+	Reasons []string `json:"reasons,omitempty"`
 }
 
 // CleanProgress reports cleaning progress to the frontend
@@ -95,12 +172,19 @@ type WalkResult struct {
 	Size      int64
 	FileCount int
 	DirCount  int
+	Excluded  []Exclusion
 	Error     error
+	// Newest access and modification times of the files walked
+	LastAccess   time.Time
+	LastModified time.Time
 }
 
 // ProgressCallback is called during scanning to report progress
 type ProgressCallback func(ScanProgress)
 
+// NodeCallback is called with each FileNode as soon as it has been sized
+type NodeCallback func(*FileNode)
+
 // ScanOptions contains options for scanning operations
 type ScanOptions struct {
 	Ctx      context.Context
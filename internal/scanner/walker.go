@@ -3,6 +3,7 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sync"
@@ -13,7 +14,14 @@ import (
 // It skips symlinks and tracks inodes to avoid double-counting hardlinked files
 // Uses actual disk blocks to handle sparse files correctly
 func WalkDirectory(root string) WalkResult {
+	return walkDirectory(root, CurrentExcluder().Rooted(root))
+}
+
+// walkDirectory is WalkDirectory with an excluder that may be rooted
+// higher up than root, so anchored patterns keep their meaning
+func walkDirectory(root string, ex *Excluder) WalkResult {
 	result := WalkResult{Path: root}
+	var excluded exclusionLog
 
 	// Use Lstat to not follow symlinks
 	info, err := os.Lstat(root)
@@ -35,9 +43,13 @@ func WalkDirectory(root string) WalkResult {
 			result.Size = info.Size()
 		}
 		result.FileCount = 1
+		result.track(info)
 		return result
 	}
 
+	// Adding or removing entries counts as modifying the directory
+	result.LastModified = info.ModTime()
+
 	// Track seen inodes to avoid counting hardlinked files multiple times
 	seenInodes := make(map[uint64]bool)
 	var mu sync.Mutex
@@ -54,6 +66,15 @@ func WalkDirectory(root string) WalkResult {
 			return nil // Skip symlinks entirely
 		}
 
+		// Skip excluded paths (and everything below excluded directories)
+		if rule, ok := ex.Match(path, d.IsDir()); ok {
+			excluded.add(path, rule)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if d.IsDir() {
 			mu.Lock()
 			result.DirCount++
@@ -74,11 +95,13 @@ func WalkDirectory(root string) WalkResult {
 					// Use actual disk blocks instead of logical size (handles sparse files)
 					result.Size += stat.Blocks * 512
 					result.FileCount++
+					result.track(info)
 					mu.Unlock()
 				} else {
 					mu.Lock()
 					result.Size += info.Size()
 					result.FileCount++
+					result.track(info)
 					mu.Unlock()
 				}
 			}
@@ -88,13 +111,28 @@ func WalkDirectory(root string) WalkResult {
 	})
 
 	result.Error = err
+	result.Excluded = excluded.list()
 	return result
 }
 
 // WalkDirectoryFast is an optimized parallel directory walker
 // It uses bounded parallelism with a semaphore to maximize throughput
 func WalkDirectoryFast(root string, numWorkers int) WalkResult {
+	return walkDirectoryFast(root, numWorkers, CurrentExcluder().Rooted(root), nil)
+}
+
+// walkDirectoryFast is WalkDirectoryFast with an excluder that may be
+// rooted higher up than root. With a fingerprint index, listings of
+// unchanged directories are reused instead of read from disk.
+func walkDirectoryFast(root string, numWorkers int, ex *Excluder, idx *FingerprintIndex) WalkResult {
 	result := WalkResult{Path: root}
+	var excluded exclusionLog
+
+	if rule, ok := ex.Match(root, true); ok {
+		excluded.add(root, rule)
+		result.Excluded = excluded.list()
+		return result
+	}
 
 	info, err := os.Lstat(root)
 	if err != nil {
@@ -136,63 +174,57 @@ func WalkDirectoryFast(root string, numWorkers int) WalkResult {
 	var wg sync.WaitGroup
 
 	// Recursive directory walker with bounded parallelism
-	var walkDir func(dirPath string)
-	walkDir = func(dirPath string) {
+	var walkDir func(dirPath string, dirInfo os.FileInfo)
+	walkDir = func(dirPath string, dirInfo os.FileInfo) {
 		defer wg.Done()
 
-		entries, err := os.ReadDir(dirPath)
-		if err != nil {
+		listing, ok := idx.listing(dirPath, dirInfo, ex)
+		if !ok {
 			return
 		}
+		excluded.merge(listing.Excluded)
 
-		var localSize int64
-		var localFiles int64
-		var localDirs int64
+		localSize := listing.FileSize
+		localFiles := int64(listing.FileCount)
+		localDirs := int64(len(listing.Subdirs))
 
-		for _, entry := range entries {
-			// Skip symlinks
-			if entry.Type()&os.ModeSymlink != 0 {
+		inodeMu.Lock()
+		for _, linked := range listing.Linked {
+			if seenInodes[linked.Inode] {
 				continue
 			}
+			seenInodes[linked.Inode] = true
+			localSize += linked.Size
+			localFiles++
+		}
+		inodeMu.Unlock()
 
-			fullPath := filepath.Join(dirPath, entry.Name())
-
-			if entry.IsDir() {
-				localDirs++
-				// Try to acquire semaphore for parallel processing
-				select {
-				case sem <- struct{}{}:
-					// Got a slot, process in parallel
-					wg.Add(1)
-					go func(path string) {
-						walkDir(path)
-						<-sem // Release slot
-					}(fullPath)
-				default:
-					// No slot available, process inline
-					wg.Add(1)
-					walkDir(fullPath)
-				}
-			} else {
-				entryInfo, err := entry.Info()
-				if err != nil {
+		for _, name := range listing.Subdirs {
+			fullPath := filepath.Join(dirPath, name)
+
+			// The index needs the subdirectory's own fingerprint
+			var subInfo os.FileInfo
+			if idx != nil {
+				info, err := os.Lstat(fullPath)
+				if err != nil || !info.IsDir() {
 					continue
 				}
+				subInfo = info
+			}
 
-				if stat, ok := entryInfo.Sys().(*syscall.Stat_t); ok {
-					inode := stat.Ino
-					inodeMu.Lock()
-					if seenInodes[inode] {
-						inodeMu.Unlock()
-						continue
-					}
-					seenInodes[inode] = true
-					inodeMu.Unlock()
-					localSize += stat.Blocks * 512
-				} else {
-					localSize += entryInfo.Size()
-				}
-				localFiles++
+			// Try to acquire semaphore for parallel processing
+			select {
+			case sem <- struct{}{}:
+				// Got a slot, process in parallel
+				wg.Add(1)
+				go func(path string, info os.FileInfo) {
+					walkDir(path, info)
+					<-sem // Release slot
+				}(fullPath, subInfo)
+			default:
+				// No slot available, process inline
+				wg.Add(1)
+				walkDir(fullPath, subInfo)
 			}
 		}
 
@@ -206,12 +238,13 @@ func WalkDirectoryFast(root string, numWorkers int) WalkResult {
 
 	// Start walking from root
 	wg.Add(1)
-	walkDir(root)
+	walkDir(root, info)
 	wg.Wait()
 
 	result.Size = totalSize
 	result.FileCount = int(totalFiles)
 	result.DirCount = int(totalDirs) + 1 // Include root directory
+	result.Excluded = excluded.list()
 
 	return result
 }
@@ -220,6 +253,8 @@ func WalkDirectoryFast(root string, numWorkers int) WalkResult {
 // It skips symlinks to avoid double-counting files
 func WalkDirectoryWithCallback(root string, callback func(path string, size int64)) WalkResult {
 	result := WalkResult{Path: root}
+	ex := CurrentExcluder().Rooted(root)
+	var excluded exclusionLog
 
 	// Use Lstat to not follow symlinks
 	info, err := os.Lstat(root)
@@ -252,6 +287,14 @@ func WalkDirectoryWithCallback(root string, callback func(path string, size int6
 			return nil // Skip symlinks entirely
 		}
 
+		if rule, ok := ex.Match(path, d.IsDir()); ok {
+			excluded.add(path, rule)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if d.IsDir() {
 			result.DirCount++
 			if callback != nil {
@@ -269,6 +312,7 @@ func WalkDirectoryWithCallback(root string, callback func(path string, size int6
 	})
 
 	result.Error = err
+	result.Excluded = excluded.list()
 	return result
 }
 
@@ -363,6 +407,7 @@ func GetDirectoryItems(root string) ([]FileNode, error) {
 	}
 
 	items := make([]FileNode, 0, len(entries))
+	ex := CurrentExcluder().Rooted(root)
 
 	for _, entry := range entries {
 		// Skip symlinks entirely
@@ -371,6 +416,9 @@ func GetDirectoryItems(root string) ([]FileNode, error) {
 		}
 
 		path := filepath.Join(root, entry.Name())
+		if _, ok := ex.Match(path, entry.IsDir()); ok {
+			continue
+		}
 		info, err := entry.Info()
 		if err != nil {
 			continue
@@ -385,8 +433,10 @@ func GetDirectoryItems(root string) ([]FileNode, error) {
 
 		if entry.IsDir() {
 			// Calculate directory size
-			result := WalkDirectory(path)
+			result := walkDirectory(path, ex)
 			node.Size = result.Size
+			node.LastAccess = result.LastAccess
+			node.LastModified = result.LastModified
 		} else {
 			// Use actual disk blocks for sparse file support
 			if stat, ok := info.Sys().(*syscall.Stat_t); ok {
@@ -394,6 +444,8 @@ func GetDirectoryItems(root string) ([]FileNode, error) {
 			} else {
 				node.Size = info.Size()
 			}
+			node.LastAccess = accessTime(info)
+			node.LastModified = info.ModTime()
 		}
 
 		items = append(items, node)
@@ -402,6 +454,59 @@ func GetDirectoryItems(root string) ([]FileNode, error) {
 	return items, nil
 }
 
+// findItems returns the directories below root whose name matches pattern,
+// without looking inside the matches. Symlinks and excluded paths are
+// skipped.
+func findItems(root, pattern string) ([]FileNode, error) {
+	ex := CurrentExcluder().Rooted(root)
+	items := []FileNode{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if path == root || !d.IsDir() {
+			return nil
+		}
+		if _, ok := ex.Match(path, true); ok {
+			return filepath.SkipDir
+		}
+		if matched, _ := filepath.Match(pattern, d.Name()); !matched {
+			return nil
+		}
+		if node, err := getPathItem(path); err == nil {
+			items = append(items, node)
+		}
+		return filepath.SkipDir
+	})
+	return items, err
+}
+
+// getPathItem returns a single path as a FileNode with its total size
+// Symlinks are reported with zero size to avoid double-counting files
+func getPathItem(path string) (FileNode, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return FileNode{}, err
+	}
+
+	node := FileNode{
+		Name:    filepath.Base(path),
+		Path:    path,
+		IsDir:   info.IsDir(),
+		ModTime: info.ModTime(),
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		result := WalkDirectory(path)
+		node.Size = result.Size
+		node.LastAccess = result.LastAccess
+		node.LastModified = result.LastModified
+	}
+	return node, nil
+}
+
 // FormatSize converts bytes to human-readable format
 func FormatSize(bytes int64) string {
 	const (
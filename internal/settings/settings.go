@@ -2,15 +2,66 @@ package settings
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+
+	"disk-peek/internal/xdg"
 )
 
 // Settings represents user preferences
 type Settings struct {
 	PermanentDelete    bool              `json:"permanentDelete"`
 	DisabledCategories map[string]bool   `json:"disabledCategories"`
+	Exclusions         Exclusions        `json:"exclusions"`
+	WatchEnabled       bool              `json:"watchEnabled"`
+	Policies           []Policy          `json:"policies"`
+	ProjectSearch      ProjectSearch     `json:"projectSearch"`
+}
+
+// Exclusions are the rules every scanner skips
+type Exclusions struct {
+	// Patterns are gitignore-style patterns such as "node_modules" or "build/"
+	Patterns []string `json:"patterns"`
+	// Paths are absolute path prefixes, ~ and $VARS are expanded
+	Paths []string `json:"paths"`
+	// Mounts are mount points never crossed into, "*" for any other filesystem
+	Mounts []string `json:"mounts"`
+}
+
+// ProjectSearch configures where node_modules and project artifacts are
+// looked for
+type ProjectSearch struct {
+	// Roots replace the default project folders (~/Projects, ~/Code, ...)
+	// "~", $VARS and globs are expanded.
+	Roots []string `json:"roots"`
+	// MaxDepth is how many levels below a root to look, 0 for no limit
+	MaxDepth int `json:"maxDepth"`
+	// StopAtProject stops descending into a directory once an artifact
+	// such as node_modules is found in it
+	StopAtProject bool `json:"stopAtProject"`
+}
+
+// PolicyTargetNodeModules is the Policy target for node_modules directories
+// found in project folders
+const PolicyTargetNodeModules = "node-modules"
+
+// Policy is a cleanup rule the scheduler applies without user interaction
+type Policy struct {
+	ID      string `json:"id"`
+	Enabled bool   `json:"enabled"`
+	// Target is a dev category ID or PolicyTargetNodeModules
+	Target string `json:"target"`
+	// MinSize skips the run until the target exceeds this many bytes
+	MinSize int64 `json:"minSize,omitempty"`
+	// MaxAgeDays only cleans items (or node_modules of projects) untouched
+	// for this long; it is required for PolicyTargetNodeModules
+	MaxAgeDays int `json:"maxAgeDays,omitempty"`
+	// IntervalHours runs the policy at most this often, e.g. 168 for weekly
+	IntervalHours int `json:"intervalHours,omitempty"`
+	// Permanent deletes instead of moving to the trash
+	Permanent bool `json:"permanent"`
 }
 
 // DefaultSettings returns the default settings
@@ -18,6 +69,15 @@ func DefaultSettings() *Settings {
 	return &Settings{
 		PermanentDelete:    false,
 		DisabledCategories: make(map[string]bool),
+		Exclusions: Exclusions{
+			Patterns: []string{},
+			Paths:    []string{},
+			Mounts:   []string{},
+		},
+		Policies: []Policy{},
+		ProjectSearch: ProjectSearch{
+			Roots: []string{},
+		},
 	}
 }
 
@@ -26,16 +86,12 @@ var (
 	mu      sync.RWMutex
 )
 
-// getSettingsPath returns the path to the settings file
+// getSettingsPath returns the path to the settings file ($XDG_CONFIG_HOME/disk-peek)
 func getSettingsPath() (string, error) {
-	home, err := os.UserHomeDir()
+	configDir, err := xdg.ConfigDir()
 	if err != nil {
 		return "", err
 	}
-	configDir := filepath.Join(home, ".config", "disk-peek")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return "", err
-	}
 	return filepath.Join(configDir, "settings.json"), nil
 }
 
@@ -150,3 +206,105 @@ func GetPermanentDelete() bool {
 	}
 	return settings.PermanentDelete
 }
+
+// SetExclusions replaces the exclusion rules
+func SetExclusions(exclusions Exclusions) error {
+	settings := Get()
+	if settings == nil {
+		settings = DefaultSettings()
+	}
+
+	settings.Exclusions = exclusions
+	return Save(settings)
+}
+
+// GetExclusions returns the exclusion rules
+func GetExclusions() Exclusions {
+	settings := Get()
+	if settings == nil {
+		return DefaultSettings().Exclusions
+	}
+	return settings.Exclusions
+}
+
+// SetWatchEnabled sets whether scanned directories are watched for changes
+func SetWatchEnabled(enabled bool) error {
+	settings := Get()
+	if settings == nil {
+		settings = DefaultSettings()
+	}
+
+	settings.WatchEnabled = enabled
+	return Save(settings)
+}
+
+// GetWatchEnabled returns whether scanned directories are watched for changes
+func GetWatchEnabled() bool {
+	settings := Get()
+	if settings == nil {
+		return false
+	}
+	return settings.WatchEnabled
+}
+
+// SetPolicies replaces the automatic cleanup policies
+// Every policy needs its own non-empty ID, and a target that is
+// PolicyTargetNodeModules or a category ID isCategory accepts. A
+// node_modules policy must set MaxAgeDays, so it never removes the
+// dependencies of every project at once.
+func SetPolicies(policies []Policy, isCategory func(id string) bool) error {
+	ids := make(map[string]bool, len(policies))
+	for i, p := range policies {
+		if p.ID == "" {
+			return fmt.Errorf("policy %d has no id", i+1)
+		}
+		if ids[p.ID] {
+			return fmt.Errorf("policy %q is defined twice", p.ID)
+		}
+		ids[p.ID] = true
+
+		if p.Target != PolicyTargetNodeModules && !isCategory(p.Target) {
+			return fmt.Errorf("policy %q: unknown target %q", p.ID, p.Target)
+		}
+		if p.Target == PolicyTargetNodeModules && p.MaxAgeDays <= 0 {
+			return fmt.Errorf("policy %q: the %s target needs maxAgeDays", p.ID, PolicyTargetNodeModules)
+		}
+	}
+
+	settings := Get()
+	if settings == nil {
+		settings = DefaultSettings()
+	}
+
+	settings.Policies = policies
+	return Save(settings)
+}
+
+// GetPolicies returns the automatic cleanup policies
+func GetPolicies() []Policy {
+	settings := Get()
+	if settings == nil {
+		return DefaultSettings().Policies
+	}
+	return settings.Policies
+}
+
+// SetProjectSearch sets where project artifacts are looked for
+func SetProjectSearch(search ProjectSearch) error {
+	settings := Get()
+	if settings == nil {
+		settings = DefaultSettings()
+	}
+
+	settings.ProjectSearch = search
+	return Save(settings)
+}
+
+// GetProjectSearch returns where project artifacts are looked for
+func GetProjectSearch() ProjectSearch {
+	settings := Get()
+	if settings == nil {
+		return DefaultSettings().ProjectSearch
+	}
+	return settings.ProjectSearch
+}
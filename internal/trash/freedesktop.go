@@ -0,0 +1,389 @@
+//go:build !windows
+
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"disk-peek/internal/xdg"
+)
+
+// moveToTrashLinux implements the FreeDesktop.org Trash specification
+// https://specifications.freedesktop.org/trash-spec/trashspec-latest.html
+// Paths on the home filesystem go to the home trash, others to the trash
+// directory at the top of their mount. Nothing is ever copied across
+// filesystems: without a usable trash on the path's own filesystem Move
+// fails with ErrCrossDevice.
+func moveToTrashLinux(path string) (Location, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Location{}, err
+	}
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return Location{}, err
+	}
+
+	trashDir, topdir, err := trashDirFor(abs, deviceOf(info))
+	if err != nil {
+		return Location{}, err
+	}
+	return trashInto(trashDir, topdir, abs, info.IsDir())
+}
+
+// availableLinux checks that path has a trash directory to go to, without
+// creating one
+func availableLinux(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return err
+	}
+	_, _, err = trashDirWith(abs, deviceOf(info), usableTrashDir)
+	return err
+}
+
+// trashDirFor picks the trash directory for a path on device dev and the
+// top directory it belongs to, which is "" for the home trash. A missing
+// per-user trash at the top of a mount is created.
+func trashDirFor(path string, dev uint64) (trashDir, topdir string, err error) {
+	return trashDirWith(path, dev, ensureTrashDir)
+}
+
+// trashDirWith is trashDirFor with prepare deciding whether a per-user
+// trash directory at the top of a mount can be used
+func trashDirWith(path string, dev uint64, prepare func(dir string) error) (trashDir, topdir string, err error) {
+	dataHome := xdg.DataHome()
+	if dataHome == "" {
+		return "", "", fmt.Errorf("cannot determine home directory")
+	}
+	home := filepath.Join(dataHome, "Trash")
+	if info, err := os.Stat(existingAncestor(home)); err == nil && deviceOf(info) == dev {
+		return home, "", nil
+	}
+
+	topdir = mountPoint(path, dev)
+	if topdir == path {
+		return "", "", fmt.Errorf("%s is a mount point and can't be trashed", path)
+	}
+	trashDir, err = topdirTrashWith(topdir, prepare)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s: %v", ErrCrossDevice, path, err)
+	}
+	return trashDir, topdir, nil
+}
+
+// topdirTrash returns the trash directory for the current user at the top
+// of a mount, creating it if needed: $topdir/.Trash/$uid when the
+// administrator set up a sticky, non-symlinked $topdir/.Trash, otherwise
+// $topdir/.Trash-$uid
+func topdirTrash(topdir string) (string, error) {
+	return topdirTrashWith(topdir, ensureTrashDir)
+}
+
+// topdirTrashWith is topdirTrash with prepare checking or creating the
+// chosen directory
+func topdirTrashWith(topdir string, prepare func(dir string) error) (string, error) {
+	uid := strconv.Itoa(os.Getuid())
+
+	shared := filepath.Join(topdir, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		dir := filepath.Join(shared, uid)
+		if err := prepare(dir); err == nil {
+			return dir, nil
+		}
+	}
+
+	dir := filepath.Join(topdir, ".Trash-"+uid)
+	if err := prepare(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ensureTrashDir creates a per-user trash directory if needed and checks
+// it is a real directory owned by the current user
+func ensureTrashDir(dir string) error {
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	return checkTrashDir(dir, info)
+}
+
+// usableTrashDir checks, without creating anything, that a per-user trash
+// directory exists as ensureTrashDir requires or could be created
+func usableTrashDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if os.IsNotExist(err) {
+		parent := filepath.Dir(dir)
+		if err := syscall.Access(parent, accessWrite|accessSearch); err != nil {
+			return &os.PathError{Op: "access", Path: parent, Err: err}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return checkTrashDir(dir, info)
+}
+
+// Modes for syscall.Access
+const (
+	accessWrite  = 0x2
+	accessSearch = 0x1
+)
+
+// checkTrashDir checks that a per-user trash directory is a real directory
+// owned by the current user
+func checkTrashDir(dir string, info os.FileInfo) error {
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by another user", dir)
+	}
+	return nil
+}
+
+// trashInto moves path into trashDir, writing its .trashinfo first so the
+// name is claimed before the move
+func trashInto(trashDir, topdir, path string, isDir bool) (Location, error) {
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return Location{}, err
+		}
+	}
+
+	// Per-mount trashes record paths relative to the top directory, so
+	// they stay valid wherever the filesystem is mounted
+	original := path
+	if topdir != "" {
+		if rel, err := filepath.Rel(topdir, path); err == nil {
+			original = rel
+		}
+	}
+	content := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		escapePath(original), time.Now().Format("2006-01-02T15:04:05"))
+
+	name, infoPath, err := claimName(filesDir, infoDir, filepath.Base(path), content)
+	if err != nil {
+		return Location{}, err
+	}
+
+	trashPath := filepath.Join(filesDir, name)
+	if err := os.Rename(path, trashPath); err != nil {
+		os.Remove(infoPath)
+		if errors.Is(err, syscall.EXDEV) {
+			return Location{}, fmt.Errorf("%w: %s", ErrCrossDevice, path)
+		}
+		return Location{}, err
+	}
+
+	if isDir {
+		// The cache only saves readers a walk, it is fine to miss an update
+		_ = addDirectorySize(trashDir, name, infoPath)
+	}
+	return Location{TrashPath: trashPath, InfoPath: infoPath}, nil
+}
+
+// claimName finds a free name in the trash and creates its .trashinfo
+// with O_EXCL, so concurrent trashers never pick the same name
+func claimName(filesDir, infoDir, base, content string) (name, infoPath string, err error) {
+	for counter := 0; ; counter++ {
+		name = base
+		if counter > 0 {
+			name = fmt.Sprintf("%s.%d", base, counter)
+		}
+		if _, err := os.Lstat(filepath.Join(filesDir, name)); err == nil {
+			continue
+		}
+
+		infoPath = filepath.Join(infoDir, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		_, err = f.WriteString(content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return "", "", err
+		}
+		return name, infoPath, nil
+	}
+}
+
+// addDirectorySize records a trashed directory in the trash's
+// directorysizes cache as "size mtime name", mtime being that of its
+// .trashinfo. Entries of directories no longer in the trash are dropped.
+func addDirectorySize(trashDir, name, infoPath string) error {
+	info, err := os.Stat(infoPath)
+	if err != nil {
+		return err
+	}
+	size := treeSize(filepath.Join(trashDir, "files", name))
+
+	return rewriteDirectorySizes(trashDir, func(entry string) bool {
+		return entry != name
+	}, fmt.Sprintf("%d %d %s", size, info.ModTime().Unix(), escapePath(name)))
+}
+
+// forgetDirectorySize drops a restored directory from the directorysizes
+// cache of the trash it was in
+func forgetDirectorySize(loc Location) error {
+	if loc.TrashPath == "" || loc.InfoPath == "" {
+		return nil
+	}
+	trashDir := filepath.Dir(filepath.Dir(loc.TrashPath))
+	name := filepath.Base(loc.TrashPath)
+	return rewriteDirectorySizes(trashDir, func(entry string) bool {
+		return entry != name
+	}, "")
+}
+
+// rewriteDirectorySizes atomically replaces the directorysizes cache with
+// the entries keep accepts whose directories are still trashed, plus add
+func rewriteDirectorySizes(trashDir string, keep func(name string) bool, add string) error {
+	cachePath := filepath.Join(trashDir, "directorysizes")
+	data, err := os.ReadFile(cachePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.IsNotExist(err) && add == "" {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		name, err := url.PathUnescape(fields[2])
+		if err != nil || !keep(name) {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(trashDir, "files", name)); err != nil {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if add != "" {
+		lines = append(lines, add)
+	}
+
+	tmp, err := os.CreateTemp(trashDir, ".directorysizes-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	content := ""
+	if len(lines) > 0 {
+		content = strings.Join(lines, "\n") + "\n"
+	}
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cachePath)
+}
+
+// treeSize sums the sizes of the files below dir
+func treeSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// escapePath percent-encodes a path for a .trashinfo file: every byte
+// except unreserved URI characters and "/" is escaped
+func escapePath(path string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+	return b.String()
+}
+
+// mountPoint returns the top directory of the mount holding path: the
+// highest ancestor still on device dev
+func mountPoint(path string, dev uint64) string {
+	dir := path
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		info, err := os.Stat(parent)
+		if err != nil || deviceOf(info) != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// existingAncestor returns path or its nearest ancestor that exists
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// deviceOf returns the device a file is on
+func deviceOf(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev)
+	}
+	return 0
+}
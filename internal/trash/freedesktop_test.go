@@ -0,0 +1,177 @@
+//go:build !windows
+
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTrashIntoHome(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	trashDir := filepath.Join(dataHome, "Trash")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "50% off – sale.txt")
+	if err := os.WriteFile(file, []byte("deal"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loc, err := moveToTrashLinux(file)
+	if err != nil {
+		t.Fatalf("moveToTrashLinux: %v", err)
+	}
+	if loc.TrashPath != filepath.Join(trashDir, "files", "50% off – sale.txt") {
+		t.Errorf("TrashPath = %s", loc.TrashPath)
+	}
+	info, err := os.ReadFile(loc.InfoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPath := "Path=" + escapePath(dir) + "/50%25%20off%20%E2%80%93%20sale.txt\n"
+	if !strings.HasPrefix(string(info), "[Trash Info]\n") || !strings.Contains(string(info), wantPath) {
+		t.Errorf(".trashinfo = %q, want %q", info, wantPath)
+	}
+
+	// A second file of the same name gets its own entry
+	if err := os.WriteFile(file, []byte("deal 2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	second, err := moveToTrashLinux(file)
+	if err != nil {
+		t.Fatalf("moveToTrashLinux: %v", err)
+	}
+	if second.TrashPath == loc.TrashPath || second.InfoPath == loc.InfoPath {
+		t.Errorf("second file reused %+v", loc)
+	}
+}
+
+func TestDirectorySizes(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	cachePath := filepath.Join(dataHome, "Trash", "directorysizes")
+
+	tree := filepath.Join(t.TempDir(), "my build")
+	if err := os.MkdirAll(filepath.Join(tree, "obj"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{"a.o": 300, "obj/b.o": 700} {
+		if err := os.WriteFile(filepath.Join(tree, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loc, err := moveToTrashLinux(tree)
+	if err != nil {
+		t.Fatalf("moveToTrashLinux: %v", err)
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("directorysizes: %v", err)
+	}
+	info, _ := os.Stat(loc.InfoPath)
+	want := "1000 " + strconv.FormatInt(info.ModTime().Unix(), 10) + " my%20build\n"
+	if string(data) != want {
+		t.Errorf("directorysizes = %q, want %q", data, want)
+	}
+
+	// Putting the directory back drops its entry
+	if err := PutBack(loc, tree); err != nil {
+		t.Fatalf("PutBack: %v", err)
+	}
+	if data, _ := os.ReadFile(cachePath); len(data) != 0 {
+		t.Errorf("directorysizes after PutBack = %q, want empty", data)
+	}
+}
+
+func TestTopdirTrash(t *testing.T) {
+	uid := strconv.Itoa(os.Getuid())
+
+	t.Run("administrator trash", func(t *testing.T) {
+		topdir := t.TempDir()
+		shared := filepath.Join(topdir, ".Trash")
+		if err := os.Mkdir(shared, 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(shared, 0777|os.ModeSticky); err != nil {
+			t.Fatal(err)
+		}
+		dir, err := topdirTrash(topdir)
+		if err != nil || dir != filepath.Join(shared, uid) {
+			t.Errorf("topdirTrash = %s, %v; want %s", dir, err, filepath.Join(shared, uid))
+		}
+	})
+
+	t.Run("not sticky", func(t *testing.T) {
+		topdir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(topdir, ".Trash"), 0777); err != nil {
+			t.Fatal(err)
+		}
+		dir, err := topdirTrash(topdir)
+		if err != nil || dir != filepath.Join(topdir, ".Trash-"+uid) {
+			t.Errorf("topdirTrash = %s, %v; want the per-user trash", dir, err)
+		}
+	})
+
+	t.Run("symlinked", func(t *testing.T) {
+		topdir := t.TempDir()
+		elsewhere := t.TempDir()
+		if err := os.Chmod(elsewhere, 0777|os.ModeSticky); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(elsewhere, filepath.Join(topdir, ".Trash")); err != nil {
+			t.Fatal(err)
+		}
+		dir, err := topdirTrash(topdir)
+		if err != nil || dir != filepath.Join(topdir, ".Trash-"+uid) {
+			t.Errorf("topdirTrash = %s, %v; want the per-user trash", dir, err)
+		}
+	})
+
+	t.Run("relative path in trashinfo", func(t *testing.T) {
+		topdir := t.TempDir()
+		file := filepath.Join(topdir, "photos", "img 1.jpg")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("jpeg"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		trashDir, err := topdirTrash(topdir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loc, err := trashInto(trashDir, topdir, file, false)
+		if err != nil {
+			t.Fatalf("trashInto: %v", err)
+		}
+		info, _ := os.ReadFile(loc.InfoPath)
+		if !strings.Contains(string(info), "\nPath=photos/img%201.jpg\n") {
+			t.Errorf(".trashinfo = %q, want a path relative to the top directory", info)
+		}
+	})
+}
+
+func TestUsableTrashDir(t *testing.T) {
+	uid := strconv.Itoa(os.Getuid())
+	topdir := t.TempDir()
+
+	dir, err := topdirTrashWith(topdir, usableTrashDir)
+	if err != nil || dir != filepath.Join(topdir, ".Trash-"+uid) {
+		t.Fatalf("topdirTrashWith = %s, %v; want the per-user trash", dir, err)
+	}
+	if _, err := os.Lstat(dir); !os.IsNotExist(err) {
+		t.Errorf("checking created %s", dir)
+	}
+
+	if err := os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := topdirTrashWith(topdir, usableTrashDir); err == nil {
+		t.Error("a file in place of the trash directory was accepted")
+	}
+}
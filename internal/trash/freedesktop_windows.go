@@ -0,0 +1,28 @@
+package trash
+
+import "fmt"
+
+// moveToTrashLinux is never called on Windows
+func moveToTrashLinux(path string) (Location, error) {
+	return Location{}, fmt.Errorf("FreeDesktop trash is not available on Windows")
+}
+
+// availableLinux is never called on Windows
+func availableLinux(path string) error {
+	return nil
+}
+
+// forgetDirectorySize has no cache to update on Windows
+func forgetDirectorySize(loc Location) error {
+	return nil
+}
+
+// listItems can't read the Recycle Bin
+func listItems() ([]Item, error) {
+	return nil, fmt.Errorf("listing the trash is not available on Windows")
+}
+
+// findItem can't read the Recycle Bin
+func findItem(id string) (Item, error) {
+	return Item{}, fmt.Errorf("listing the trash is not available on Windows")
+}
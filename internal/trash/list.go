@@ -0,0 +1,100 @@
+package trash
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"disk-peek/internal/audit"
+)
+
+// Item is something in a FreeDesktop trash
+type Item struct {
+	// ID identifies the item for Restore: the path of its .trashinfo file
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	OriginalPath string    `json:"originalPath"`
+	DeletedAt    time.Time `json:"deletedAt"`
+	Size         int64     `json:"size"`
+	IsDir        bool      `json:"isDir"`
+	// TrashDir is the trash holding the item: the home trash or a
+	// per-mount one
+	TrashDir  string `json:"trashDir"`
+	TrashPath string `json:"trashPath"`
+}
+
+// PurgeResult reports what Purge and Empty removed
+type PurgeResult struct {
+	Removed    []Item   `json:"removed"`
+	FreedBytes int64    `json:"freedBytes"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// List returns the items of the home trash and of every per-mount trash of
+// the current user, largest first. Items whose .trashinfo has no matching
+// file are skipped.
+func List() ([]Item, error) {
+	items, err := listItems()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Size > items[j].Size
+	})
+	return items, nil
+}
+
+// Restore moves the item with the given ID back to its original path,
+// which must not exist
+func Restore(id string) error {
+	item, err := findItem(id)
+	if err != nil {
+		return err
+	}
+	return PutBack(Location{TrashPath: item.TrashPath, InfoPath: item.ID}, item.OriginalPath)
+}
+
+// Purge permanently deletes the items trashed more than olderThan ago
+func Purge(olderThan time.Duration) PurgeResult {
+	cutoff := time.Now().Add(-olderThan)
+	return purge(audit.SourcePurgeTrash, func(item Item) bool {
+		return !item.DeletedAt.IsZero() && item.DeletedAt.Before(cutoff)
+	})
+}
+
+// Empty permanently deletes everything in the trash
+func Empty() PurgeResult {
+	return purge(audit.SourceEmptyTrash, func(Item) bool { return true })
+}
+
+// purge deletes the items match accepts, each file before its .trashinfo
+// as the specification asks, and records them in the audit log under source
+func purge(source string, match func(Item) bool) PurgeResult {
+	result := PurgeResult{Removed: []Item{}}
+
+	items, err := List()
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	for _, item := range items {
+		if !match(item) {
+			continue
+		}
+		err := os.RemoveAll(item.TrashPath)
+		_ = audit.Append(audit.Deletion(item.OriginalPath, item.Size, audit.MethodPermanent, source, err))
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		if err := os.Remove(item.ID); err != nil && !os.IsNotExist(err) {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		if item.IsDir {
+			_ = forgetDirectorySize(Location{TrashPath: item.TrashPath, InfoPath: item.ID})
+		}
+		result.Removed = append(result.Removed, item)
+		result.FreedBytes += item.Size
+	}
+	return result
+}
@@ -0,0 +1,242 @@
+//go:build !windows
+
+package trash
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"disk-peek/internal/xdg"
+)
+
+// trashLocation is a trash directory and the top directory its relative
+// paths start from
+type trashLocation struct {
+	dir    string
+	topdir string
+}
+
+// listItems reads the items of every trash directory of the user
+func listItems() ([]Item, error) {
+	var items []Item
+	for _, loc := range trashLocations() {
+		found, err := listTrash(loc)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, found...)
+	}
+	return items, nil
+}
+
+// findItem looks up an item by ID, which must be a .trashinfo file of one
+// of the user's trash directories
+func findItem(id string) (Item, error) {
+	for _, loc := range trashLocations() {
+		if filepath.Dir(id) != filepath.Join(loc.dir, "info") {
+			continue
+		}
+		return readItem(loc, id, readDirectorySizes(loc.dir))
+	}
+	return Item{}, fmt.Errorf("%s is not in a trash directory", id)
+}
+
+// listTrash reads the items of one trash directory
+func listTrash(loc trashLocation) ([]Item, error) {
+	entries, err := os.ReadDir(filepath.Join(loc.dir, "info"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := readDirectorySizes(loc.dir)
+	var items []Item
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".trashinfo") {
+			continue
+		}
+		item, err := readItem(loc, filepath.Join(loc.dir, "info", entry.Name()), sizes)
+		if err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// readItem parses a .trashinfo file and sizes up the trashed file
+func readItem(loc trashLocation, infoPath string, sizes map[string]directorySize) (Item, error) {
+	name := strings.TrimSuffix(filepath.Base(infoPath), ".trashinfo")
+	item := Item{
+		ID:        infoPath,
+		Name:      name,
+		TrashDir:  loc.dir,
+		TrashPath: filepath.Join(loc.dir, "files", name),
+	}
+
+	info, err := os.Lstat(item.TrashPath)
+	if err != nil {
+		return Item{}, err
+	}
+	item.IsDir = info.IsDir()
+
+	f, err := os.Open(infoPath)
+	if err != nil {
+		return Item{}, err
+	}
+	defer f.Close()
+	infoStat, err := f.Stat()
+	if err != nil {
+		return Item{}, err
+	}
+
+	inSection := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "[Trash Info]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inSection || !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			if path, err := url.PathUnescape(value); err == nil {
+				if !filepath.IsAbs(path) && loc.topdir != "" {
+					path = filepath.Join(loc.topdir, path)
+				}
+				item.OriginalPath = path
+			}
+		case "DeletionDate":
+			if date, err := time.ParseInLocation("2006-01-02T15:04:05", value, time.Local); err == nil {
+				item.DeletedAt = date
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Item{}, err
+	}
+	if item.OriginalPath == "" {
+		return Item{}, fmt.Errorf("%s has no Path", infoPath)
+	}
+
+	switch {
+	case !item.IsDir:
+		item.Size = info.Size()
+	case sizes[name].mtime == infoStat.ModTime().Unix():
+		item.Size = sizes[name].size
+	default:
+		item.Size = treeSize(item.TrashPath)
+	}
+	return item, nil
+}
+
+// directorySize is an entry of a directorysizes cache
+type directorySize struct {
+	size  int64
+	mtime int64
+}
+
+// readDirectorySizes reads the directorysizes cache of a trash directory
+func readDirectorySizes(trashDir string) map[string]directorySize {
+	sizes := make(map[string]directorySize)
+	data, err := os.ReadFile(filepath.Join(trashDir, "directorysizes"))
+	if err != nil {
+		return sizes
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err1 := strconv.ParseInt(fields[0], 10, 64)
+		mtime, err2 := strconv.ParseInt(fields[1], 10, 64)
+		name, err3 := url.PathUnescape(fields[2])
+		if err1 == nil && err2 == nil && err3 == nil {
+			sizes[name] = directorySize{size: size, mtime: mtime}
+		}
+	}
+	return sizes
+}
+
+// trashLocations returns the home trash and the current user's trash
+// directories at the top of every mounted filesystem
+func trashLocations() []trashLocation {
+	var locations []trashLocation
+	if dataHome := xdg.DataHome(); dataHome != "" {
+		locations = append(locations, trashLocation{dir: filepath.Join(dataHome, "Trash")})
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+	for _, topdir := range mountPoints() {
+		for _, dir := range []string{
+			filepath.Join(topdir, ".Trash", uid),
+			filepath.Join(topdir, ".Trash-"+uid),
+		} {
+			if info, err := os.Lstat(dir); err == nil && info.IsDir() {
+				locations = append(locations, trashLocation{dir: dir, topdir: topdir})
+			}
+		}
+	}
+	return locations
+}
+
+// mountsFile lists the mounted filesystems; tests point it elsewhere
+var mountsFile = "/proc/self/mounts"
+
+// mountPoints lists the mounted filesystems from mountsFile. Where it
+// doesn't exist only the home trash is found.
+func mountPoints() []string {
+	f, err := os.Open(mountsFile)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var points []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		point := unescapeMount(fields[1])
+		if !seen[point] {
+			seen[point] = true
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+// unescapeMount decodes the octal escapes (\040 for a space) of
+// /proc/self/mounts
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
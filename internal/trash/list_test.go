@@ -0,0 +1,202 @@
+//go:build !windows
+
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// isolateTrash confines the trash to a fresh home trash and, unless
+// mounts is given, no per-mount trash directories
+func isolateTrash(t *testing.T, mounts ...string) string {
+	t.Helper()
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	table := ""
+	for _, mount := range mounts {
+		table += "/dev/sdz1 " + mount + " ext4 rw 0 0\n"
+	}
+	file := filepath.Join(t.TempDir(), "mounts")
+	if err := os.WriteFile(file, []byte(table), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved := mountsFile
+	mountsFile = file
+	t.Cleanup(func() { mountsFile = saved })
+	return dataHome
+}
+
+// trashFile creates a file of size bytes at path and moves it to the trash
+func trashFile(t *testing.T, path string, size int) Location {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loc, err := moveToTrashLinux(path)
+	if err != nil {
+		t.Fatalf("moveToTrashLinux: %v", err)
+	}
+	return loc
+}
+
+// backdate rewrites the deletion date of a trashed item
+func backdate(t *testing.T, loc Location, original string, deleted time.Time) {
+	t.Helper()
+	content := "[Trash Info]\nPath=" + escapePath(original) +
+		"\nDeletionDate=" + deleted.Format("2006-01-02T15:04:05") + "\n"
+	if err := os.WriteFile(loc.InfoPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestList(t *testing.T) {
+	dataHome := isolateTrash(t)
+	dir := t.TempDir()
+
+	small := filepath.Join(dir, "notes 1.txt")
+	trashFile(t, small, 100)
+	big := filepath.Join(dir, "build", "out")
+	if err := os.MkdirAll(big, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{"a": 3000, "b": 2000} {
+		if err := os.WriteFile(filepath.Join(big, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := moveToTrashLinux(big); err != nil {
+		t.Fatal(err)
+	}
+
+	// An orphan .trashinfo is not listed
+	orphan := filepath.Join(dataHome, "Trash", "info", "gone.trashinfo")
+	if err := os.WriteFile(orphan, []byte("[Trash Info]\nPath=/gone\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("List returned %d items, want 2: %+v", len(items), items)
+	}
+	if items[0].OriginalPath != big || !items[0].IsDir || items[0].Size != 5000 {
+		t.Errorf("largest item = %+v, want %s of 5000 bytes", items[0], big)
+	}
+	var found bool
+	for _, item := range items {
+		if item.OriginalPath == small {
+			found = true
+			if item.Size != 100 || item.IsDir {
+				t.Errorf("%s = %+v", small, item)
+			}
+			if time.Since(item.DeletedAt) > time.Minute {
+				t.Errorf("DeletedAt = %v, want about now", item.DeletedAt)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("%s not listed", small)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	isolateTrash(t)
+	file := filepath.Join(t.TempDir(), "report.pdf")
+	loc := trashFile(t, file, 10)
+
+	if err := Restore(loc.InfoPath); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("%s not restored: %v", file, err)
+	}
+	if _, err := os.Stat(loc.InfoPath); !os.IsNotExist(err) {
+		t.Errorf(".trashinfo still there: %v", err)
+	}
+
+	// Only .trashinfo files of a trash directory are accepted
+	if err := Restore(file); err == nil {
+		t.Error("Restore accepted a path outside the trash")
+	}
+}
+
+func TestPurgeAndEmpty(t *testing.T) {
+	isolateTrash(t)
+	dir := t.TempDir()
+
+	old := filepath.Join(dir, "old.log")
+	oldLoc := trashFile(t, old, 500)
+	backdate(t, oldLoc, old, time.Now().Add(-40*24*time.Hour))
+	recent := filepath.Join(dir, "recent.log")
+	recentLoc := trashFile(t, recent, 200)
+
+	result := Purge(30 * 24 * time.Hour)
+	if len(result.Errors) > 0 {
+		t.Fatalf("Purge errors: %v", result.Errors)
+	}
+	if len(result.Removed) != 1 || result.Removed[0].OriginalPath != old || result.FreedBytes != 500 {
+		t.Errorf("Purge = %+v, want only %s", result, old)
+	}
+	for _, path := range []string{oldLoc.TrashPath, oldLoc.InfoPath} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s survived Purge", path)
+		}
+	}
+	if _, err := os.Lstat(recentLoc.TrashPath); err != nil {
+		t.Errorf("recent item purged: %v", err)
+	}
+
+	result = Empty()
+	if len(result.Removed) != 1 || result.FreedBytes != 200 {
+		t.Errorf("Empty = %+v, want the recent item", result)
+	}
+	if items, _ := List(); len(items) != 0 {
+		t.Errorf("trash not empty: %+v", items)
+	}
+}
+
+func TestListPerMountTrash(t *testing.T) {
+	topdir := t.TempDir()
+	isolateTrash(t, topdir)
+
+	file := filepath.Join(topdir, "photos", "img.jpg")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	trashDir, err := topdirTrash(topdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trashInto(trashDir, topdir, file, false); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(items) != 1 || items[0].OriginalPath != file || items[0].TrashDir != trashDir {
+		t.Errorf("List = %+v, want %s in %s", items, file, trashDir)
+	}
+}
+
+func TestUnescapeMount(t *testing.T) {
+	if got := unescapeMount(`/media/USB\040Stick`); got != "/media/USB Stick" {
+		t.Errorf("unescapeMount = %q", got)
+	}
+	if got := unescapeMount(`/odd\`); got != `/odd\` {
+		t.Errorf("unescapeMount = %q", got)
+	}
+}
@@ -1,21 +1,49 @@
 package trash
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
+
+	"disk-peek/internal/xdg"
 )
 
+// Location is where a trashed item ended up
+// Both paths are empty when the platform doesn't reveal it.
+type Location struct {
+	// TrashPath is the trashed file or directory itself
+	TrashPath string `json:"trashPath,omitempty"`
+	// InfoPath is the FreeDesktop .trashinfo file describing it
+	InfoPath string `json:"infoPath,omitempty"`
+	// Removed is set when the platform trash failed and the item was
+	// deleted permanently instead
+	Removed bool `json:"removed,omitempty"`
+}
+
+// ErrCrossDevice is returned when a path's filesystem has no usable trash
+// directory. Trashing it elsewhere would mean copying and deleting it,
+// which Move never does; callers may delete it permanently instead.
+var ErrCrossDevice = errors.New("no trash directory on the path's filesystem")
+
 // MoveToTrash moves a file or directory to the system trash/recycle bin
 // Returns nil on success, error on failure
 func MoveToTrash(path string) error {
+	_, err := Move(path)
+	return err
+}
+
+// Move moves a file or directory to the system trash and reports where it
+// went, so it can be put back later. On macOS and Windows a path the trash
+// refuses is deleted permanently instead, which the location reports as
+// Removed.
+func Move(path string) (Location, error) {
 	// Check if path exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil // Already doesn't exist, consider it success
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return Location{}, nil // Already doesn't exist, consider it success
 	}
 
 	switch runtime.GOOS {
@@ -24,123 +52,87 @@ func MoveToTrash(path string) error {
 	case "linux":
 		return moveToTrashLinux(path)
 	case "windows":
-		return moveToTrashWindows(path)
+		removed, err := moveToTrashWindows(path)
+		return Location{Removed: removed}, err
 	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+		return Location{}, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
 }
 
-// moveToTrashMacOS uses AppleScript to move files to Trash on macOS
-func moveToTrashMacOS(path string) error {
-	// Escape backslashes and double quotes for AppleScript string
-	escapedPath := strings.ReplaceAll(path, `\`, `\\`)
-	escapedPath = strings.ReplaceAll(escapedPath, `"`, `\"`)
-
-	// Use macOS trash command via osascript for proper Trash behavior
-	// This preserves the "Put Back" functionality
-	cmd := exec.Command("osascript", "-e",
-		`tell application "Finder" to delete POSIX file "`+escapedPath+`"`)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Fallback: try direct removal if Finder fails
-		return os.RemoveAll(path)
+// Available checks, without moving anything, that Move could trash path.
+// On Linux it fails with ErrCrossDevice when the path's filesystem has no
+// trash directory Move could use or create; nothing is created here. Other
+// platforms are not checked.
+func Available(path string) error {
+	if runtime.GOOS != "linux" {
+		return nil
 	}
-	_ = output
-	return nil
+	return availableLinux(path)
 }
 
-// moveToTrashLinux implements the FreeDesktop.org Trash specification
-// https://specifications.freedesktop.org/trash-spec/trashspec-latest.html
-func moveToTrashLinux(path string) error {
-	// Try using gio (GNOME) first, then trash-cli, then fallback to manual
-	if err := trashWithGio(path); err == nil {
-		return nil
+// PutBack moves a trashed item to target and removes its .trashinfo
+// target must not exist.
+func PutBack(loc Location, target string) error {
+	if loc.TrashPath == "" {
+		return fmt.Errorf("trash location unknown for %s", target)
 	}
-
-	if err := trashWithTrashCli(path); err == nil {
-		return nil
+	if _, err := os.Lstat(target); err == nil {
+		return os.ErrExist
 	}
-
-	// Manual implementation of FreeDesktop.org Trash spec
-	return trashManualLinux(path)
-}
-
-// trashWithGio uses GNOME's gio command
-func trashWithGio(path string) error {
-	cmd := exec.Command("gio", "trash", path)
-	return cmd.Run()
-}
-
-// trashWithTrashCli uses the trash-cli package
-func trashWithTrashCli(path string) error {
-	cmd := exec.Command("trash-put", path)
-	return cmd.Run()
-}
-
-// trashManualLinux implements the FreeDesktop.org Trash spec manually
-func trashManualLinux(path string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
+	if _, err := os.Lstat(loc.TrashPath); err != nil {
 		return err
 	}
-
-	trashDir := filepath.Join(home, ".local", "share", "Trash")
-	filesDir := filepath.Join(trashDir, "files")
-	infoDir := filepath.Join(trashDir, "info")
-
-	// Create trash directories if they don't exist
-	if err := os.MkdirAll(filesDir, 0700); err != nil {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
 		return err
 	}
-	if err := os.MkdirAll(infoDir, 0700); err != nil {
+	if err := os.Rename(loc.TrashPath, target); err != nil {
 		return err
 	}
-
-	// Get the base name and create a unique trash name
-	baseName := filepath.Base(path)
-	trashName := baseName
-	counter := 1
-
-	// Handle name conflicts
-	for {
-		trashPath := filepath.Join(filesDir, trashName)
-		if _, err := os.Stat(trashPath); os.IsNotExist(err) {
-			break
-		}
-		trashName = fmt.Sprintf("%s.%d", baseName, counter)
-		counter++
+	if loc.InfoPath != "" {
+		_ = os.Remove(loc.InfoPath)
 	}
+	_ = forgetDirectorySize(loc)
+	return nil
+}
 
-	// Create the .trashinfo file
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return err
+// moveToTrashMacOS uses AppleScript to move files to Trash on macOS
+// Finder renames items that clash with something already in the Trash, so
+// the location is only reported when the original name is free.
+func moveToTrashMacOS(path string) (Location, error) {
+	var loc Location
+	if home, err := os.UserHomeDir(); err == nil {
+		candidate := filepath.Join(home, ".Trash", filepath.Base(path))
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			loc.TrashPath = candidate
+		}
 	}
 
-	infoContent := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
-		absPath,
-		time.Now().Format("2006-01-02T15:04:05"))
+	// Escape backslashes and double quotes for AppleScript string
+	escapedPath := strings.ReplaceAll(path, `\`, `\\`)
+	escapedPath = strings.ReplaceAll(escapedPath, `"`, `\"`)
 
-	infoPath := filepath.Join(infoDir, trashName+".trashinfo")
-	if err := os.WriteFile(infoPath, []byte(infoContent), 0600); err != nil {
-		return err
+	// Use macOS trash command via osascript for proper Trash behavior
+	// This preserves the "Put Back" functionality
+	cmd := exec.Command("osascript", "-e",
+		`tell application "Finder" to delete POSIX file "`+escapedPath+`"`)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// Fallback: try direct removal if Finder fails
+		return Location{Removed: true}, os.RemoveAll(path)
 	}
+	_ = output
 
-	// Move the file to trash
-	trashPath := filepath.Join(filesDir, trashName)
-	if err := os.Rename(path, trashPath); err != nil {
-		// If rename fails (cross-device), try copy and delete
-		// For simplicity, we'll just return the error
-		// A full implementation would copy the file
-		os.Remove(infoPath) // Clean up the info file
-		return err
+	if loc.TrashPath != "" {
+		if _, err := os.Lstat(loc.TrashPath); err != nil {
+			loc.TrashPath = ""
+		}
 	}
-
-	return nil
+	return loc, nil
 }
 
 // moveToTrashWindows uses PowerShell to move files to Recycle Bin
-func moveToTrashWindows(path string) error {
+// It reports whether the path had to be deleted permanently instead.
+func moveToTrashWindows(path string) (bool, error) {
 	// Escape the path for PowerShell
 	escapedPath := strings.ReplaceAll(path, `'`, `''`)
 
@@ -159,18 +151,18 @@ if ($item) {
 		// Fallback: try using recycle command if available
 		return trashWithRecycleBin(path)
 	}
-	return nil
+	return false, nil
 }
 
 // trashWithRecycleBin is a fallback for Windows using the recycle command
-func trashWithRecycleBin(path string) error {
+func trashWithRecycleBin(path string) (bool, error) {
 	// Try using the Windows recycle command (if installed)
 	cmd := exec.Command("recycle", path)
 	if err := cmd.Run(); err != nil {
 		// Last resort: use direct deletion
-		return os.RemoveAll(path)
+		return true, os.RemoveAll(path)
 	}
-	return nil
+	return false, nil
 }
 
 // IsTrashSupported returns true if the current platform supports trash functionality
@@ -194,7 +186,7 @@ func GetTrashLocation() (string, error) {
 	case "darwin":
 		return filepath.Join(home, ".Trash"), nil
 	case "linux":
-		return filepath.Join(home, ".local", "share", "Trash"), nil
+		return filepath.Join(xdg.DataHome(), "Trash"), nil
 	case "windows":
 		// Windows doesn't have a direct path to the Recycle Bin
 		return "", fmt.Errorf("recycle bin path not directly accessible on Windows")
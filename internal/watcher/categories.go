@@ -0,0 +1,76 @@
+package watcher
+
+import (
+	"path/filepath"
+
+	"disk-peek/internal/scanner"
+)
+
+// CategoryRoots returns the resolved paths of every leaf category
+func CategoryRoots(categories []scanner.Category) []string {
+	var roots []string
+	var collect func(cats []scanner.Category)
+	collect = func(cats []scanner.Category) {
+		for _, cat := range cats {
+			roots = append(roots, scanner.ResolvePaths(cat.Paths)...)
+			collect(cat.Children)
+		}
+	}
+	collect(categories)
+	return roots
+}
+
+// AffectedCategories returns the IDs of categories with a path that
+// contains one of the changed directories or was added to or removed from one
+func AffectedCategories(categories []scanner.Category, dirs []string) []string {
+	var ids []string
+	var check func(cats []scanner.Category)
+	check = func(cats []scanner.Category) {
+		for _, cat := range cats {
+			check(cat.Children)
+			if categoryChanged(cat, dirs) {
+				ids = append(ids, cat.ID)
+			}
+		}
+	}
+	check(categories)
+	return ids
+}
+
+func categoryChanged(cat scanner.Category, dirs []string) bool {
+	for _, path := range scanner.ResolvePaths(cat.Paths) {
+		for _, dir := range dirs {
+			if isWithin(dir, path) || filepath.Dir(path) == dir {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UpdateCategory replaces the category with the same ID in result, then
+// recomputes parent sizes and the total. It reports whether the total
+// changed.
+func UpdateCategory(result *scanner.ScanResult, updated scanner.Category) bool {
+	existing := scanner.GetCategoryByID(result.Categories, updated.ID)
+	if existing == nil {
+		return false
+	}
+	*existing = updated
+
+	var sum func(cats []scanner.Category) int64
+	sum = func(cats []scanner.Category) int64 {
+		var total int64
+		for i := range cats {
+			if len(cats[i].Children) > 0 {
+				cats[i].Size = sum(cats[i].Children)
+			}
+			total += cats[i].Size
+		}
+		return total
+	}
+
+	previous := result.TotalSize
+	result.TotalSize = sum(result.Categories)
+	return result.TotalSize != previous
+}
@@ -0,0 +1,138 @@
+//go:build linux
+
+package watcher
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// inotifyMask selects events that change a directory's disk usage
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF |
+	syscall.IN_ONLYDIR | syscall.IN_DONT_FOLLOW
+
+// errTooManyWatches means fs.inotify.max_user_watches was reached
+var errTooManyWatches = errors.New("inotify watch limit reached")
+
+// inotifyBackend watches every directory of a tree with inotify
+// inotify is not recursive, so new subdirectories are added as they appear.
+type inotifyBackend struct {
+	notify func(dir string)
+	file   *os.File
+	fd     int
+
+	mu      sync.Mutex
+	watches map[int]string
+	roots   []string
+}
+
+func newInotifyBackend(notify func(dir string)) (backend, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &inotifyBackend{
+		notify: notify,
+		// A non-blocking fd lets Close interrupt a pending Read
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		fd:      fd,
+		watches: make(map[int]string),
+	}
+	go b.readLoop()
+	return b, nil
+}
+
+func (b *inotifyBackend) addTree(root string) error {
+	b.mu.Lock()
+	b.roots = append(b.roots, root)
+	b.mu.Unlock()
+
+	return b.watchTree(root)
+}
+
+// watchTree adds a watch for root and every directory below it
+func (b *inotifyBackend) watchTree(root string) error {
+	return walkDirs(root, func(dir string) error {
+		wd, err := syscall.InotifyAddWatch(b.fd, dir, inotifyMask)
+		if err != nil {
+			if err == syscall.ENOSPC {
+				return errTooManyWatches
+			}
+			// Unreadable directories can't be watched or scanned
+			return filepath.SkipDir
+		}
+		b.mu.Lock()
+		b.watches[wd] = dir
+		b.mu.Unlock()
+		return nil
+	})
+}
+
+func (b *inotifyBackend) close() error {
+	return b.file.Close()
+}
+
+// readLoop reads events until the backend is closed
+func (b *inotifyBackend) readLoop() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			wd := int(int32(binary.NativeEndian.Uint32(buf[offset:])))
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[nameStart:nameStart+nameLen], "\x00"))
+			offset = nameStart + nameLen
+
+			b.handle(wd, mask, name)
+		}
+	}
+}
+
+// handle processes a single inotify event
+func (b *inotifyBackend) handle(wd int, mask uint32, name string) {
+	// Events were dropped, so anything may have changed
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		b.mu.Lock()
+		roots := append([]string(nil), b.roots...)
+		b.mu.Unlock()
+		for _, root := range roots {
+			b.notify(root)
+		}
+		return
+	}
+
+	b.mu.Lock()
+	dir, ok := b.watches[wd]
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(b.watches, wd)
+	}
+	b.mu.Unlock()
+	if !ok || mask&syscall.IN_IGNORED != 0 {
+		return
+	}
+
+	// New directories need their own watches
+	if mask&syscall.IN_ISDIR != 0 && mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		_ = b.watchTree(filepath.Join(dir, name))
+	}
+
+	if mask&syscall.IN_DELETE_SELF != 0 {
+		b.notify(filepath.Dir(dir))
+		return
+	}
+	b.notify(dir)
+}
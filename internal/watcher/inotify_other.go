@@ -0,0 +1,7 @@
+//go:build !linux
+
+package watcher
+
+func newInotifyBackend(notify func(dir string)) (backend, error) {
+	return nil, errUnsupported
+}
@@ -0,0 +1,118 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// pollBackend detects changes by checking directory mtimes periodically
+// Adding, removing or renaming an entry updates its directory's mtime, but
+// files growing in place do not, so those are only noticed with inotify.
+type pollBackend struct {
+	notify   func(dir string)
+	interval time.Duration
+
+	mu   sync.Mutex
+	dirs map[string]time.Time
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func newPollBackend(interval time.Duration, notify func(dir string)) *pollBackend {
+	b := &pollBackend{
+		notify:   notify,
+		interval: interval,
+		dirs:     make(map[string]time.Time),
+		stop:     make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+func (b *pollBackend) addTree(root string) error {
+	return walkDirs(root, func(dir string) error {
+		info, err := os.Lstat(dir)
+		if err != nil {
+			return filepath.SkipDir
+		}
+		b.mu.Lock()
+		b.dirs[dir] = info.ModTime()
+		b.mu.Unlock()
+		return nil
+	})
+}
+
+func (b *pollBackend) close() error {
+	b.once.Do(func() { close(b.stop) })
+	return nil
+}
+
+func (b *pollBackend) loop() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.poll()
+		}
+	}
+}
+
+// poll checks every known directory once
+func (b *pollBackend) poll() {
+	b.mu.Lock()
+	known := make(map[string]time.Time, len(b.dirs))
+	for dir, modTime := range b.dirs {
+		known[dir] = modTime
+	}
+	b.mu.Unlock()
+
+	for dir, modTime := range known {
+		select {
+		case <-b.stop:
+			return
+		default:
+		}
+
+		info, err := os.Lstat(dir)
+		if err != nil || !info.IsDir() {
+			// Gone; its parent's mtime changed too and is reported there
+			b.mu.Lock()
+			delete(b.dirs, dir)
+			b.mu.Unlock()
+			continue
+		}
+		if info.ModTime().Equal(modTime) {
+			continue
+		}
+
+		b.mu.Lock()
+		b.dirs[dir] = info.ModTime()
+		b.mu.Unlock()
+		b.notify(dir)
+
+		// Start watching directories created since the last poll
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			sub := filepath.Join(dir, entry.Name())
+			b.mu.Lock()
+			_, ok := b.dirs[sub]
+			b.mu.Unlock()
+			if !ok {
+				_ = b.addTree(sub)
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package watcher
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"disk-peek/internal/scanner"
+)
+
+// TreeUpdate describes a node whose size or children changed
+type TreeUpdate struct {
+	Path      string            `json:"path"`
+	Node      *scanner.FileNode `json:"node"`
+	Delta     int64             `json:"delta"`
+	TotalSize int64             `json:"totalSize"`
+}
+
+// Tree is a normal scan result kept current from watcher changes
+// Only nodes in the tree are updated: a change deep inside a directory
+// whose children were never loaded resizes that directory.
+type Tree struct {
+	mu      sync.Mutex
+	result  scanner.FullScanResult
+	scanner *scanner.NormalScanner
+}
+
+// NewTree wraps a scan result
+// s measures changed directories; give it a fingerprint index so only the
+// directories that changed are read.
+func NewTree(result scanner.FullScanResult, s *scanner.NormalScanner) *Tree {
+	return &Tree{result: result, scanner: s}
+}
+
+// Result returns the current scan result
+func (t *Tree) Result() scanner.FullScanResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.result
+}
+
+// Graft attaches lazily loaded children so later changes below them are
+// applied at that depth
+func (t *Tree) Graft(path string, children []*scanner.FileNode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	chain := t.chain(path)
+	if len(chain) == 0 {
+		return
+	}
+	if node := chain[len(chain)-1]; node.Path == path {
+		node.Children = children
+	}
+}
+
+// Apply re-measures the nodes containing the changed directories and
+// propagates size deltas to their ancestors. The tree stays readable while
+// the directories are measured.
+func (t *Tree) Apply(dirs []string) []TreeUpdate {
+	// The directories named by events changed even if their fingerprint
+	// didn't, e.g. when a file in them grew in place
+	t.scanner.Invalidate(dirs...)
+
+	t.mu.Lock()
+	// Deepest nodes first, so refreshing an ancestor sees their new sizes
+	targets := make(map[string]bool)
+	for _, dir := range dirs {
+		if chain := t.chain(dir); len(chain) > 0 {
+			targets[chain[len(chain)-1].Path] = true
+		}
+	}
+	t.mu.Unlock()
+
+	paths := make([]string, 0, len(targets))
+	for path := range targets {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Count(paths[i], string(filepath.Separator)) > strings.Count(paths[j], string(filepath.Separator))
+	})
+
+	measured := make(map[string][]*scanner.FileNode, len(paths))
+	for _, path := range paths {
+		children, err := t.scanner.GetDirectoryChildren(path)
+		if err != nil {
+			// The directory is gone; its parent reports the removal
+			continue
+		}
+		measured[path] = children
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var updates []TreeUpdate
+	for _, path := range paths {
+		children, ok := measured[path]
+		if !ok {
+			continue
+		}
+		// Look the node up again, a graft may have replaced it meanwhile
+		chain := t.chain(path)
+		if len(chain) == 0 || chain[len(chain)-1].Path != path {
+			continue
+		}
+		node := chain[len(chain)-1]
+
+		var size int64
+		for _, child := range children {
+			size += child.Size
+		}
+
+		// Grafted grandchildren are dropped rather than kept with sizes that
+		// may already be counted in the fresh children
+		refreshed := len(node.Children) > 0
+		if refreshed {
+			node.Children = children
+		}
+
+		delta := size - node.Size
+		if delta == 0 && !refreshed {
+			continue
+		}
+		for _, ancestor := range chain {
+			ancestor.Size += delta
+		}
+		t.result.TotalSize = t.result.Root.Size
+
+		updates = append(updates, TreeUpdate{
+			Path:      node.Path,
+			Node:      node,
+			Delta:     delta,
+			TotalSize: t.result.TotalSize,
+		})
+	}
+	return updates
+}
+
+// chain returns the nodes from the root down to the deepest node that is
+// path or contains it
+func (t *Tree) chain(path string) []*scanner.FileNode {
+	node := t.result.Root
+	if node == nil || !isWithin(path, node.Path) {
+		return nil
+	}
+
+	chain := []*scanner.FileNode{node}
+	for node.Path != path {
+		var next *scanner.FileNode
+		for _, child := range node.Children {
+			if child.IsDir && isWithin(path, child.Path) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		chain = append(chain, next)
+		node = next
+	}
+	return chain
+}
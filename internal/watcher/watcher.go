@@ -0,0 +1,237 @@
+// Package watcher keeps scan results fresh by watching the scanned roots
+// On Linux it uses inotify; elsewhere, or when the inotify watch limit is
+// reached, it falls back to polling directory mtimes.
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"disk-peek/internal/scanner"
+)
+
+// errUnsupported is returned by backends not available on this platform
+var errUnsupported = errors.New("not supported on this platform")
+
+// ChangeCallback receives the directories whose contents changed
+// Changes are batched, so each call covers everything since the last one.
+type ChangeCallback func(dirs []string)
+
+// Options configures a Watcher
+type Options struct {
+	// Debounce is how long changes are collected before the callback runs
+	Debounce time.Duration
+	// PollInterval is how often directories are checked when polling
+	PollInterval time.Duration
+	// Poll forces polling even where inotify is available
+	Poll bool
+}
+
+// DefaultOptions returns sensible defaults
+func DefaultOptions() Options {
+	return Options{
+		Debounce:     2 * time.Second,
+		PollInterval: 30 * time.Second,
+	}
+}
+
+// backend watches directory trees and reports directories that changed
+type backend interface {
+	// addTree watches root and every directory below it
+	addTree(root string) error
+	close() error
+}
+
+// Watcher reports changes below a set of root directories
+type Watcher struct {
+	options  Options
+	callback ChangeCallback
+
+	mu      sync.Mutex
+	backend backend
+	polling bool
+	pending map[string]bool
+	timer   *time.Timer
+	closed  bool
+}
+
+// New creates a Watcher that reports changes to callback
+func New(options Options, callback ChangeCallback) *Watcher {
+	if options.Debounce <= 0 {
+		options.Debounce = DefaultOptions().Debounce
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultOptions().PollInterval
+	}
+	return &Watcher{
+		options:  options,
+		callback: callback,
+		pending:  make(map[string]bool),
+	}
+}
+
+// Watch replaces the watched roots
+// Roots that don't exist are ignored. Excluded paths are not watched.
+func (w *Watcher) Watch(roots []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.backend != nil {
+		_ = w.backend.close()
+		w.backend = nil
+	}
+	w.closed = false
+
+	roots = existingRoots(roots)
+	if len(roots) == 0 {
+		return nil
+	}
+
+	if !w.options.Poll {
+		b, err := newInotifyBackend(w.notify)
+		if err == nil {
+			if err = addTrees(b, roots); err == nil {
+				w.backend, w.polling = b, false
+				return nil
+			}
+			_ = b.close()
+		}
+	}
+
+	// inotify is unavailable or ran out of watches
+	b := newPollBackend(w.options.PollInterval, w.notify)
+	if err := addTrees(b, roots); err != nil {
+		_ = b.close()
+		return err
+	}
+	w.backend, w.polling = b, true
+	return nil
+}
+
+// Polling reports whether the watcher fell back to polling
+func (w *Watcher) Polling() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.polling
+}
+
+// Close stops watching; pending changes are dropped
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.pending = make(map[string]bool)
+
+	if w.backend == nil {
+		return nil
+	}
+	err := w.backend.close()
+	w.backend = nil
+	return err
+}
+
+// notify records a changed directory and schedules the callback
+func (w *Watcher) notify(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+	w.pending[dir] = true
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.options.Debounce, w.flush)
+	}
+}
+
+// flush hands the collected changes to the callback
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	dirs := make([]string, 0, len(w.pending))
+	for dir := range w.pending {
+		dirs = append(dirs, dir)
+	}
+	w.pending = make(map[string]bool)
+	w.timer = nil
+	closed := w.closed
+	w.mu.Unlock()
+
+	if closed || len(dirs) == 0 || w.callback == nil {
+		return
+	}
+	sort.Strings(dirs)
+	w.callback(dirs)
+}
+
+func addTrees(b backend, roots []string) error {
+	for _, root := range roots {
+		if err := b.addTree(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// existingRoots drops roots that don't exist and roots nested in another
+func existingRoots(roots []string) []string {
+	var dirs []string
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		dirs = append(dirs, filepath.Clean(root))
+	}
+	sort.Strings(dirs)
+
+	var result []string
+	for _, dir := range dirs {
+		if n := len(result); n > 0 && isWithin(dir, result[n-1]) {
+			continue
+		}
+		result = append(result, dir)
+	}
+	return result
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	if dir == string(filepath.Separator) {
+		return filepath.IsAbs(path)
+	}
+	return len(path) > len(dir) && path[:len(dir)] == dir && path[len(dir)] == filepath.Separator
+}
+
+// walkDirs calls fn for root and every directory below it, skipping
+// symlinks and paths excluded by the scanner's exclusion rules. fn may
+// return filepath.SkipDir or an error to stop.
+func walkDirs(root string, fn func(dir string) error) error {
+	ex := scanner.CurrentExcluder().Rooted(root)
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() || d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if _, ok := ex.Match(path, true); ok {
+			return filepath.SkipDir
+		}
+		return fn(path)
+	})
+}
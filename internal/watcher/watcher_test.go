@@ -0,0 +1,158 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"disk-peek/internal/scanner"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitForChange watches root, runs change and returns the reported dirs
+func waitForChange(t *testing.T, options Options, root string, change func()) []string {
+	t.Helper()
+
+	changes := make(chan []string, 10)
+	w := New(options, func(dirs []string) { changes <- dirs })
+	if err := w.Watch([]string{root}); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer w.Close()
+
+	change()
+
+	select {
+	case dirs := <-changes:
+		return dirs
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+		return nil
+	}
+}
+
+func TestWatcherReportsChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{"default", Options{Debounce: 50 * time.Millisecond}},
+		{"poll", Options{Debounce: 50 * time.Millisecond, PollInterval: 50 * time.Millisecond, Poll: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			nested := filepath.Join(root, "a", "b")
+			if err := os.MkdirAll(nested, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			dirs := waitForChange(t, tt.options, root, func() {
+				// Give the poller a tick to record the initial mtimes
+				time.Sleep(100 * time.Millisecond)
+				writeFile(t, filepath.Join(nested, "new.bin"), 1024)
+			})
+
+			found := false
+			for _, dir := range dirs {
+				if dir == nested {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("changes = %v, want %s", dirs, nested)
+			}
+		})
+	}
+}
+
+func TestTreeApply(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a", "deep", "one.bin"), 4096)
+	writeFile(t, filepath.Join(root, "b", "two.bin"), 4096)
+
+	s := scanner.NewNormalScanner(2)
+	s.SetFingerprintIndex(scanner.NewFingerprintIndex())
+	tree := NewTree(s.ScanPath(root), s)
+	before := tree.Result().TotalSize
+
+	writeFile(t, filepath.Join(root, "a", "deep", "big.bin"), 65536)
+	updates := tree.Apply([]string{filepath.Join(root, "a", "deep")})
+
+	if len(updates) != 1 || updates[0].Path != filepath.Join(root, "a") {
+		t.Fatalf("updates = %+v, want one update for a", updates)
+	}
+	if updates[0].Delta <= 0 {
+		t.Errorf("delta = %d, want growth", updates[0].Delta)
+	}
+
+	want := scanner.NewNormalScanner(2).ScanPath(root).TotalSize
+	if got := tree.Result().TotalSize; got != want || got != before+updates[0].Delta {
+		t.Errorf("total size = %d, want %d (before %d, delta %d)", got, want, before, updates[0].Delta)
+	}
+
+	t.Run("changes outside the tree are ignored", func(t *testing.T) {
+		if updates := tree.Apply([]string{t.TempDir()}); len(updates) != 0 {
+			t.Errorf("updates = %+v, want none", updates)
+		}
+	})
+
+	t.Run("files growing in place are measured", func(t *testing.T) {
+		f, err := os.OpenFile(filepath.Join(root, "a", "deep", "one.bin"), os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(make([]byte, 65536))
+		f.Close()
+
+		updates := tree.Apply([]string{filepath.Join(root, "a", "deep")})
+		if len(updates) != 1 || updates[0].Delta <= 0 {
+			t.Fatalf("updates = %+v, want growth of a", updates)
+		}
+	})
+
+	t.Run("root changes refresh children", func(t *testing.T) {
+		writeFile(t, filepath.Join(root, "c", "three.bin"), 4096)
+		updates := tree.Apply([]string{root})
+		if len(updates) != 1 || len(updates[0].Node.Children) != 3 {
+			t.Fatalf("updates = %+v, want root with 3 children", updates)
+		}
+	})
+}
+
+func TestCategoryUpdates(t *testing.T) {
+	result := scanner.ScanResult{
+		Categories: []scanner.Category{
+			{ID: "parent", Size: 30, Children: []scanner.Category{
+				{ID: "one", Paths: []string{"/cache/one"}, Size: 10},
+				{ID: "two", Paths: []string{"/cache/two"}, Size: 20},
+			}},
+		},
+		TotalSize: 30,
+	}
+
+	ids := AffectedCategories(result.Categories, []string{"/cache/two/sub", "/elsewhere"})
+	if len(ids) != 1 || ids[0] != "two" {
+		t.Errorf("AffectedCategories = %v, want [two]", ids)
+	}
+	if ids := AffectedCategories(result.Categories, []string{"/cache"}); len(ids) != 2 {
+		t.Errorf("AffectedCategories for parent dir = %v, want both", ids)
+	}
+
+	changed := UpdateCategory(&result, scanner.Category{ID: "two", Paths: []string{"/cache/two"}, Size: 50})
+	if !changed || result.TotalSize != 60 || result.Categories[0].Size != 60 {
+		t.Errorf("after update: changed %v, total %d, parent %d; want true, 60, 60",
+			changed, result.TotalSize, result.Categories[0].Size)
+	}
+}
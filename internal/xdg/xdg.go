@@ -0,0 +1,191 @@
+// Package xdg resolves the XDG base directories used by Disk Peek
+// https://specifications.freedesktop.org/basedir-spec/latest/
+package xdg
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// AppName is the directory name used under each base directory
+const AppName = "disk-peek"
+
+// ConfigHome returns $XDG_CONFIG_HOME, defaulting to ~/.config
+func ConfigHome() string {
+	return baseDir("XDG_CONFIG_HOME", ".config")
+}
+
+// CacheHome returns $XDG_CACHE_HOME, defaulting to ~/.cache
+func CacheHome() string {
+	return baseDir("XDG_CACHE_HOME", ".cache")
+}
+
+// DataHome returns $XDG_DATA_HOME, defaulting to ~/.local/share
+func DataHome() string {
+	return baseDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// baseDir returns the value of env if it is an absolute path, otherwise
+// the default location relative to the home directory. The spec requires
+// relative values to be ignored.
+func baseDir(env, fallback string) string {
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, fallback)
+}
+
+// ConfigDir returns (and creates) the Disk Peek config directory
+// It holds user-edited files such as settings.json and categories.json
+func ConfigDir() (string, error) {
+	return appDir(ConfigHome())
+}
+
+// CacheDir returns (and creates) the Disk Peek cache directory
+// Everything in it can be regenerated by rescanning
+func CacheDir() (string, error) {
+	return appDir(CacheHome())
+}
+
+// DataDir returns (and creates) the Disk Peek data directory
+// It holds history that can't be regenerated, such as trends
+func DataDir() (string, error) {
+	return appDir(DataHome())
+}
+
+func appDir(base string) (string, error) {
+	if base == "" {
+		_, err := os.UserHomeDir()
+		return "", err
+	}
+	dir := filepath.Join(base, AppName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ConfigFile returns the path of a file in the config directory without
+// creating anything
+func ConfigFile(name string) (string, error) {
+	return appFile(ConfigHome(), name)
+}
+
+// DataFile returns the path of a file in the data directory without
+// creating anything
+func DataFile(name string) (string, error) {
+	return appFile(DataHome(), name)
+}
+
+func appFile(base, name string) (string, error) {
+	if base == "" {
+		_, err := os.UserHomeDir()
+		return "", err
+	}
+	return filepath.Join(base, AppName, name), nil
+}
+
+// legacyDir is where all files lived before XDG directories were honored
+func legacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", AppName), nil
+}
+
+// MigrateLegacy moves files from the old ~/.config/disk-peek layout into the
+// XDG directories. It is safe to call on every start: files are only moved
+// if they exist in the old location and not in the new one, so the
+// migration effectively runs once.
+func MigrateLegacy() error {
+	legacy, err := legacyDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(legacy); os.IsNotExist(err) {
+		return nil
+	}
+
+	moves := []struct {
+		from string
+		base string
+		to   string
+	}{
+		{filepath.Join(legacy, "settings.json"), ConfigHome(), "settings.json"},
+		{filepath.Join(legacy, "categories.json"), ConfigHome(), "categories.json"},
+		{filepath.Join(legacy, "trends.json"), DataHome(), "trends.json"},
+		{filepath.Join(legacy, "cache", "dev_scan_cache.json"), CacheHome(), "dev_scan_cache.json"},
+		{filepath.Join(legacy, "cache", "normal_scan_cache.json"), CacheHome(), "normal_scan_cache.json"},
+	}
+
+	var firstErr error
+	for _, m := range moves {
+		if m.base == "" {
+			continue
+		}
+		to := filepath.Join(m.base, AppName, m.to)
+		if to == m.from {
+			continue
+		}
+		if err := moveIfAbsent(m.from, to); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Remove the old cache directory once it is empty
+	_ = os.Remove(filepath.Join(legacy, "cache"))
+
+	return firstErr
+}
+
+// moveIfAbsent moves from to to unless from is missing or to already exists
+func moveIfAbsent(from, to string) error {
+	if _, err := os.Stat(from); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(to); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	// Rename fails across filesystems, fall back to copy and remove
+	if err := copyFile(from, to); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	return dst.Close()
+}
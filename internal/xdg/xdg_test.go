@@ -0,0 +1,96 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("XDG_CACHE_HOME", "")
+		t.Setenv("XDG_DATA_HOME", "")
+
+		if got, want := ConfigHome(), filepath.Join(home, ".config"); got != want {
+			t.Errorf("ConfigHome() = %s, want %s", got, want)
+		}
+		if got, want := CacheHome(), filepath.Join(home, ".cache"); got != want {
+			t.Errorf("CacheHome() = %s, want %s", got, want)
+		}
+		if got, want := DataHome(), filepath.Join(home, ".local", "share"); got != want {
+			t.Errorf("DataHome() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("absolute overrides", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", "/var/cache/me")
+		if got := CacheHome(); got != "/var/cache/me" {
+			t.Errorf("CacheHome() = %s, want /var/cache/me", got)
+		}
+	})
+
+	t.Run("relative values are ignored", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", "relative/data")
+		if got, want := DataHome(), filepath.Join(home, ".local", "share"); got != want {
+			t.Errorf("DataHome() = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "cfg"))
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	legacy := filepath.Join(home, ".config", AppName)
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(legacy, "settings.json"), "old-settings")
+	write(filepath.Join(legacy, "trends.json"), "trends")
+	write(filepath.Join(legacy, "cache", "dev_scan_cache.json"), "dev")
+	// An existing file in the new location must not be overwritten
+	write(filepath.Join(home, "cfg", AppName, "settings.json"), "new-settings")
+
+	if err := MigrateLegacy(); err != nil {
+		t.Fatalf("MigrateLegacy: %v", err)
+	}
+
+	expect := map[string]string{
+		filepath.Join(home, "cfg", AppName, "settings.json"):           "new-settings",
+		filepath.Join(home, ".local", "share", AppName, "trends.json"): "trends",
+		filepath.Join(home, ".cache", AppName, "dev_scan_cache.json"):  "dev",
+		filepath.Join(legacy, "settings.json"):                         "old-settings",
+	}
+	for path, want := range expect {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", path, data, want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(legacy, "cache")); !os.IsNotExist(err) {
+		t.Error("empty legacy cache directory should be removed")
+	}
+
+	// Running again is a no-op
+	if err := MigrateLegacy(); err != nil {
+		t.Errorf("second MigrateLegacy: %v", err)
+	}
+}
@@ -32,6 +32,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 11, G: 11, B: 13, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Menu:             appMenu,
 		Bind: []interface{}{
 			app,